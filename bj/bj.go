@@ -108,9 +108,16 @@ import (
                 really determine which is the best strategy in a given scenario.
   17 Aug 24 -- Thinking about the nonrandom code, it was wrong-headed.  Running thru a deck without changing anything will always get the same statistics.  That's not helpful.
                 And it turns out that the card playing occurs very quickly.  It's the shuffling that takes most of the time.  So it goes.  There's no purpose to the nonrandom stuff.
+  18 Oct 26 -- Adding card counting, in count.go.  The counts are Hi-Lo, KO, Omega II, or a tag table in the .strat file or -count flag.  Added the penetration and cut card,
+                the number of decks, a bet ramp keyed to the true count, and a bankroll w/ sessions to get the risk of ruin.  It outputs the EV and SD per true count.
+                These can be lines in the .strat file, like COUNT HILO, or flags, which win over the file.
+  18 Oct 26 -- Adding -solve, in solve.go, which works out the best strategy by combinatorial analysis and writes it as a .strat file w/ the EV tables.
+                If a .strat file is also given, it's compared to the solved strategy.  Added -h17 and -resplit, which are the same as DEALER17 and RESPLIT.
+  18 Oct 26 -- A comment after the values of a counting line, like DECKS 6, is now ignored like it is on the strategy lines.  And when a round runs out of cards,
+                only the discards are reshuffled, as the cards on the table aren't back in the shoe.
 */
 
-const lastAltered = "Aug 16, 2024"
+const lastAltered = "Oct 18, 2026"
 
 var OptionName = []string{"Stnd", "Hit ", "Dbl ", "SP  ", "Sur "} // Stand, Hit, Double, Split, Surrender
 
@@ -278,6 +285,16 @@ func ReadStrategyMatrix(buf *bytes.Reader) { // the StrategyMatrix is global.
 		if EOL {
 			return
 		}
+		if rowID.State == tknptr.ALLELSE { // the counting options, like COUNT HILO, are handled in count.go
+			isCounting, err := readCountingOption(rowID.Str, strings.Fields(rowbuf)[1:])
+			if err != nil {
+				fmt.Println(" Error in the line", rowbuf, ":", err, ".  Exiting.")
+				os.Exit(1)
+			}
+			if isCounting {
+				continue
+			}
+		}
 		row := make([]int, 0, 10) // a single StrategyMatrix row.
 		for {                     // This is reading a single lines worth of tokens after the first one, which are H, D, S, SP or SUR.  This loop is validating to make sure all tokens on the line are valid.
 			token, eol := tknbuf.GetToken(true) // force upper case token
@@ -455,7 +472,7 @@ func WriteStrategyMatrix(filehandle *bufio.Writer) {
 
 // InitDeck -- does just that.
 func InitDeck() { // Initalize the deck of cards.
-	for i := 0; i < 4*numOfDecks; i++ {
+	for i := 0; i < 4*decks; i++ {
 		for j := 1; j <= 10; j++ { // There is no card Zero
 			deck = append(deck, j)
 		}
@@ -468,6 +485,7 @@ func InitDeck() { // Initalize the deck of cards.
 // doTheShuffle -- role is in the title
 func doTheShuffle() {
 	currentCard = 0
+	defer setCutCard()
 	if nonrandom {
 		return
 	}
@@ -488,7 +506,13 @@ func doTheShuffle() {
 
 // ------------------------------------------------------- getCard -----------------------------------
 func getCard() int {
+	if currentCard >= len(deck)-1 { // only when the cut card is at the very end and a round runs out of cards.
+		reshuffleDiscards()
+	}
 	currentCard++ // This will ignore the first card, in position zero.
+	if counter != nil {
+		counter.Count(deck[currentCard])
+	}
 	return deck[currentCard]
 }
 
@@ -1458,16 +1482,36 @@ func main() {
 	flag.BoolVar(&veryVerboseFlag, "vv", false, "Very Verbose mode")
 	flag.BoolVar(&interactiveFlag, "i", false, "Interactive mode flag")
 	flag.IntVar(&numOfPlayers, "n", 1, "Number of players to simulate")
+	flag.StringVar(&countSpec, "count", "", "Count: hilo, ko, omega2, none, or 10 tags from A to 10, as in -1,1,1,1,1,1,0,0,0,-1")
+	flag.StringVar(&rampSpec, "ramp", "", "Bet ramp of tc:units, as in 1:1,2:2,3:4,4:8")
+	flag.IntVar(&decks, "decks", numOfDecks, "Number of decks in the shoe")
+	flag.Float64Var(&penetration, "pen", 0.75, "Penetration, the fraction of the shoe dealt before the shuffle")
+	flag.IntVar(&cutCardFromEnd, "cut", 0, "Cut card placed this many cards from the end of the shoe; wins over -pen")
+	flag.Float64Var(&bankroll, "bankroll", 0, "Bankroll in units for each session, to get the risk of ruin")
+	flag.IntVar(&sessionRounds, "session", 1000, "Rounds per session")
+	flag.StringVar(&solveFilename, "solve", "", "Solve for the best strategy and write it to this .strat file.  A .strat file given also is compared to it.")
+	flag.BoolVar(&noSurrenderFlag, "nosur", false, "Solve w/o surrender")
+	flag.BoolVar(&dealerHitsSoft17, "h17", false, "Dealer hits soft 17, same as DEALER17 in the .strat file")
+	flag.BoolVar(&resplitAcesFlag, "resplit", false, "Resplit aces, same as RESPLIT in the .strat file")
 	flag.Parse()
 	if veryVerboseFlag {
 		verboseFlag = true
 	}
+	flag.Visit(func(f *flag.Flag) { flagsSet[f.Name] = true })
+	if err := applyCountingFlags(); err != nil {
+		fmt.Println(" Error in the counting flags:", err, ".  Exiting.")
+		os.Exit(1)
+	}
 
 	const InputExtDefault = ".strat"
 	const OutputExtDefault = ".results"
 
 	displayRound = interactiveFlag
-	deck = make([]int, 0, NumOfCards)
+	deck = make([]int, 0, 52*decks)
+	if solveFilename != "" && flag.NArg() < 1 { // nothing to read in, so don't use the file picker.
+		solveMain(false)
+		return
+	}
 
 	// File picker stuff added Apr 2024
 
@@ -1548,6 +1592,10 @@ func main() {
 	if verboseFlag {
 		pause()
 	}
+	if solveFilename != "" {
+		solveMain(true)
+		return
+	}
 
 	// Construct results filename to receive the results.
 	OutputFilename = BaseFilename + OutputExtDefault
@@ -1613,6 +1661,7 @@ func main() {
 	for i := 0; i < shuffleAmount; i++ { // increase the shuffling, since it's not so good, esp noticable when I'm using only 1 deck for testing of this.
 		rand.Shuffle(len(deck), swapFnt)
 	}
+	setCutCard()
 	timeToShuffle := time.Since(shuffleStartTime) // timeToShuffle is a Duration type, which is an int64 but has methods.
 	fmt.Println(" It took ", timeToShuffle.String(), " to shuffle this file.  shuffleAmount=", shuffleAmount, ".")
 	if displayRound || verboseFlag {
@@ -1675,9 +1724,11 @@ func main() {
 
 PlayAllRounds:
 	for j := 0; j < maxNumOfHands; j++ {
+		startRound()
 		playAllHands()
 		showDown()
 		incrementStats()
+		countRound()
 		if j%loopDivisor == 0 {
 			progBar.Add(loopDivisor)
 		}
@@ -1711,7 +1762,7 @@ PlayAllRounds:
 		// Need to remove splits, if any, from the player hand slice.
 		playerHand = playerHand[:numOfPlayers]
 
-		if currentCard > len(deck)*3/4 { // shuffle if 3/4 of the deck has been played thru.
+		if currentCard > cutCard { // shuffle if the cut card came out, which by default is after 3/4 of the deck.
 			doTheShuffle()
 			if displayRound {
 				fmt.Println(" shuffling ...")
@@ -1794,6 +1845,7 @@ PlayAllRounds:
 	fmt.Print(runswonstring)
 	fmt.Print(runsloststring)
 
+	wrCountingStats(bufOutputFileWriter)
 	wrStatsToFile()
 
 } // main
@@ -0,0 +1,489 @@
+package main // count.go
+
+/*
+  Card counting and bet sizing for the simulator.
+
+  A Counter sees every card as it's dealt by getCard, and is reset by doTheShuffle.  The counts here are all tag tables, one tag per card value where Ace
+  is 1 and 10 is all the 10 value cards, so a user defined count is only a new table.  A balanced count, like Hi-Lo and Omega II, has a true count that is
+  the running count divided by the decks that are left.  An unbalanced count, like KO, starts at an initial running count (IRC) and its running count is
+  used as is, which is the point of an unbalanced count.  I'm ignoring the Ace side count that Omega II players use.
+
+  The bet ramp is keyed to the true count at the start of a round.  It's a list of tc:units, as in 1:1 2:2 3:4 4:8, which means bet 2 units at a true
+  count of 2, 4 units at 3, and 8 units at 4 and up.  Below the first entry, the bet is the units of the first entry.  No ramp means a flat bet of 1 unit.
+
+  The penetration is the fraction of the shoe that's dealt before the shuffle.  The cut card is the number of cards behind the cut card, and if it's set it
+  wins over the penetration.  The shuffle only happens between rounds, like in a casino, so a round that starts in front of the cut card plays to the end.
+
+  In the .strat file these are lines that start w/ a keyword, and the command line flags win over these.  Like the strategy lines, anything after the
+  values is a comment.
+    COUNT HILO | KO | OMEGA2 | NONE | [name] tagA tag2 tag3 tag4 tag5 tag6 tag7 tag8 tag9 tag10
+    RAMP 1:1 2:2 3:4 4:8
+    DECKS 6
+    PENETRATION 0.75   or   CUT 78
+    BANKROLL 200       in units
+    SESSION 1000       rounds per session
+  The strategy matrix is not changed by the count.  Playing deviations, like the Illustrious 18, are not here.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Counter is what a counting system has to do.  TrueCount is what the bet ramp is keyed to.
+type Counter interface {
+	Name() string
+	Count(card int)
+	RunningCount() int
+	TrueCount(decksLeft float64) float64
+	Reset(decks int)
+}
+
+type tagTable [11]int // indexed by card, where Ace is 1.  Index 0 is not used.
+
+// tagCounter is a Counter for any tag table.
+type tagCounter struct {
+	name    string
+	tags    tagTable
+	running int
+}
+
+func (tc *tagCounter) Name() string { return tc.name }
+
+func (tc *tagCounter) Count(card int) { tc.running += tc.tags[card] }
+
+func (tc *tagCounter) RunningCount() int { return tc.running }
+
+// imbalance is the sum of the tags over 1 deck, which is zero for a balanced count.
+func (tc *tagCounter) imbalance() int {
+	sum := 0
+	for card := 1; card <= 10; card++ {
+		if card == 10 {
+			sum += 16 * tc.tags[card]
+		} else {
+			sum += 4 * tc.tags[card]
+		}
+	}
+	return sum
+}
+
+func (tc *tagCounter) TrueCount(decksLeft float64) float64 {
+	if tc.imbalance() != 0 { // unbalanced counts use the running count as is.
+		return float64(tc.running)
+	}
+	if decksLeft < 0.25 { // don't let the last few cards make a silly true count.
+		decksLeft = 0.25
+	}
+	return float64(tc.running) / decksLeft
+}
+
+// Reset sets the running count to the IRC, which for KO is 4 - 4*decks, so the key count comes out right at the end of the shoe.
+func (tc *tagCounter) Reset(decks int) {
+	tc.running = -tc.imbalance() * (decks - 1)
+}
+
+var countTables = map[string]tagTable{
+	//           A   2  3  4  5  6  7  8   9  10
+	"HILO":   {0, -1, 1, 1, 1, 1, 1, 0, 0, 0, -1},
+	"KO":     {0, -1, 1, 1, 1, 1, 1, 1, 0, 0, -1},
+	"OMEGA2": {0, 0, 1, 1, 2, 2, 2, 1, 0, -1, -2},
+}
+
+type rampStep struct {
+	trueCount int
+	units     float64
+}
+
+func (r rampStep) String() string { return fmt.Sprintf("%d:%g", r.trueCount, r.units) }
+
+const minTC, maxTC = -8, 8 // the true counts outside this range are lumped into the end buckets.
+
+type tcStatsType struct { // per true count, all at a flat bet of 1 unit.
+	rounds, hands   int
+	sum, sumSquares float64
+}
+
+var counter Counter
+var ramp []rampStep
+var decks = numOfDecks
+var penetration = 0.75
+var cutCardFromEnd, cutCard int
+var roundStartCard int // where the deck was when the round started, so a reshuffle in the middle of a round knows which cards are on the table.
+var bankroll float64
+var sessionRounds = 1000
+var countSpec, rampSpec string
+var flagsSet = make(map[string]bool) // the flags on the command line win over the .strat file.
+
+var tcStats [maxTC - minTC + 1]tcStatsType
+var roundBet, roundTC float64
+var totalUnitsBet, totalUnitsWon, sumSquaresRound float64
+var totalRounds int
+var sessionBankroll float64
+var sessionResults []float64
+var sessionsRuined, roundsInSession int
+var sessionRuined bool
+
+// ------------------------------------------------------- newCounter -----------------------------------
+// newCounter takes the name of a count, or a user defined count as 10 tags, from Ace to 10, optionally after a name.
+func newCounter(fields []string) (Counter, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no count given")
+	}
+	name := strings.ToUpper(fields[0])
+	name = strings.NewReplacer("-", "", " ", "").Replace(name)
+	if name == "NONE" {
+		return nil, nil
+	}
+	if name == "OMEGAII" {
+		name = "OMEGA2"
+	}
+	if tags, ok := countTables[name]; ok && len(fields) == 1 {
+		return &tagCounter{name: name, tags: tags}, nil
+	}
+
+	if _, err := strconv.Atoi(fields[0]); err == nil {
+		name = "USER"
+	} else {
+		fields = fields[1:]
+	}
+	if len(fields) != 10 {
+		return nil, fmt.Errorf("count %s needs 10 tags, for A 2 3 4 5 6 7 8 9 10, but has %d", name, len(fields))
+	}
+	tc := &tagCounter{name: name}
+	for i, f := range fields {
+		tag, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("tag %q of count %s is not a number", f, name)
+		}
+		tc.tags[i+1] = tag
+	}
+	return tc, nil
+} // newCounter
+
+// ------------------------------------------------------- parseRamp -----------------------------------
+func parseRamp(fields []string) ([]rampStep, error) {
+	steps := make([]rampStep, 0, len(fields))
+	for _, f := range fields {
+		tcStr, unitsStr, ok := strings.Cut(f, ":")
+		if !ok {
+			return nil, fmt.Errorf("ramp step %q is not tc:units", f)
+		}
+		tc, err := strconv.Atoi(tcStr)
+		if err != nil {
+			return nil, fmt.Errorf("ramp step %q: true count is not a number", f)
+		}
+		units, err := strconv.ParseFloat(unitsStr, 64)
+		if err != nil || units <= 0 {
+			return nil, fmt.Errorf("ramp step %q: units must be a number > 0", f)
+		}
+		steps = append(steps, rampStep{trueCount: tc, units: units})
+	}
+	slices.SortFunc(steps, func(a, b rampStep) int { return a.trueCount - b.trueCount })
+	return steps, nil
+} // parseRamp
+
+var countingKeywords = map[string]string{ // keyword -> flag
+	"COUNT": "count", "RAMP": "ramp", "DECKS": "decks", "PENETRATION": "pen", "PEN": "pen", "CUT": "cut", "BANKROLL": "bankroll", "SESSION": "session",
+}
+
+// countingValues drops a trailing comment from the fields of a counting line, the same way a strategy line stops at the first token that's not a code.
+// A count is its name, or its tags, and a ramp is its tc:units steps.  The other keywords take one number.
+func countingValues(keyword string, fields []string) []string {
+	var keep func(i int, f string) bool
+	switch keyword {
+	case "COUNT":
+		keep = func(i int, f string) bool {
+			_, err := strconv.Atoi(f)
+			return i == 0 || err == nil
+		}
+	case "RAMP":
+		keep = func(i int, f string) bool { return strings.Contains(f, ":") }
+	default:
+		keep = func(i int, f string) bool { return i == 0 }
+	}
+	for i, f := range fields {
+		if !keep(i, f) {
+			return fields[:i]
+		}
+	}
+	return fields
+}
+
+// readCountingOption handles the counting lines of the .strat file.  It returns false if the keyword is not one of these.
+func readCountingOption(keyword string, fields []string) (bool, error) {
+	flagName, ok := countingKeywords[keyword]
+	if !ok {
+		return false, nil
+	}
+	if flagsSet[flagName] {
+		return true, nil
+	}
+	fields = countingValues(keyword, fields)
+	var err error
+	switch keyword {
+	case "COUNT":
+		counter, err = newCounter(fields)
+	case "RAMP":
+		ramp, err = parseRamp(fields)
+	case "DECKS", "CUT", "SESSION":
+		if len(fields) != 1 {
+			return true, fmt.Errorf("%s takes one number", keyword)
+		}
+		var n int
+		if n, err = strconv.Atoi(fields[0]); err != nil || n < 1 {
+			return true, fmt.Errorf("%s must be a number > 0, not %q", keyword, fields[0])
+		}
+		switch keyword {
+		case "DECKS":
+			decks = n
+		case "CUT":
+			cutCardFromEnd = n
+		default:
+			sessionRounds = n
+		}
+	case "PENETRATION", "PEN", "BANKROLL":
+		if len(fields) != 1 {
+			return true, fmt.Errorf("%s takes one number", keyword)
+		}
+		var f float64
+		if f, err = strconv.ParseFloat(fields[0], 64); err != nil || f <= 0 {
+			return true, fmt.Errorf("%s must be a number > 0, not %q", keyword, fields[0])
+		}
+		if keyword == "BANKROLL" {
+			bankroll = f
+		} else {
+			penetration = f
+		}
+	}
+	return true, err
+} // readCountingOption
+
+// countingWanted is true when there's anything to report.  W/o it, the output is as it always was.
+func countingWanted() bool {
+	return counter != nil || len(ramp) > 0 || bankroll > 0
+}
+
+// applyCountingFlags makes the Counter and the ramp from the -count and -ramp flags, which are separated by commas or spaces.
+func applyCountingFlags() error {
+	if decks < 1 || penetration <= 0 || cutCardFromEnd < 0 || sessionRounds < 1 {
+		return fmt.Errorf("decks, pen and session must be > 0, and cut must not be < 0")
+	}
+	var err error
+	split := func(s string) []string { return strings.Fields(strings.ReplaceAll(s, ",", " ")) }
+	if flagsSet["count"] {
+		if counter, err = newCounter(split(countSpec)); err != nil {
+			return err
+		}
+	}
+	if flagsSet["ramp"] {
+		ramp, err = parseRamp(split(rampSpec))
+	}
+	return err
+}
+
+// ------------------------------------------------------- setCutCard -----------------------------------
+// setCutCard places the cut card in the freshly shuffled deck.
+func setCutCard() {
+	if cutCardFromEnd > 0 {
+		cutCard = len(deck) - cutCardFromEnd
+	} else {
+		cutCard = int(float64(len(deck)) * min(penetration, 1))
+	}
+	cutCard = max(min(cutCard, len(deck)-1), 1)
+	if counter != nil {
+		counter.Reset(decks)
+	}
+}
+
+// ------------------------------------------------------- reshuffleDiscards -----------------------------------
+// reshuffleDiscards is for when a round runs out of cards.  The cards of this round are still on the table, so only the discards are shuffled.  The cards
+// on the table are moved to the front of the deck as already dealt, and the count starts over from them.
+func reshuffleDiscards() {
+	inPlay := slices.Clone(deck[roundStartCard+1 : currentCard+1])
+	discards := slices.Clone(deck[:roundStartCard+1])
+	if !nonrandom {
+		rand.Shuffle(len(discards), func(i, j int) { discards[i], discards[j] = discards[j], discards[i] })
+	}
+	deck = append(append(deck[:0], inPlay...), discards...)
+	currentCard = len(inPlay) - 1
+	roundStartCard = -1 // all the cards in front of currentCard are this round's.
+	setCutCard()
+	if counter != nil {
+		for _, card := range inPlay {
+			counter.Count(card)
+		}
+	}
+} // reshuffleDiscards
+
+func decksLeft() float64 {
+	return float64(len(deck)-currentCard) / 52
+}
+
+// betUnits is the bet for a true count, from the ramp.
+func betUnits(tc float64) float64 {
+	if len(ramp) == 0 {
+		return 1
+	}
+	units := ramp[0].units
+	for _, step := range ramp {
+		if tc >= float64(step.trueCount) {
+			units = step.units
+		}
+	}
+	return units
+}
+
+func tcBucket(tc float64) int {
+	return int(max(min(math.Floor(tc), maxTC), minTC)) - minTC
+}
+
+// handUnits is the net result of a hand for a bet of 1 unit.
+func handUnits(result int) float64 {
+	switch result {
+	case won:
+		return 1
+	case wonBJ:
+		return 1.5
+	case wondbl:
+		return 2
+	case lost, losttoBJ:
+		return -1
+	case lostdbl:
+		return -2
+	case surrend:
+		return -0.5
+	}
+	return 0 // pushed, pushedBJ
+}
+
+// ------------------------------------------------------- startRound -----------------------------------
+// startRound gets the true count and the bet before the cards are dealt.
+func startRound() {
+	roundStartCard = currentCard
+	roundTC = 0
+	if counter != nil {
+		roundTC = counter.TrueCount(decksLeft())
+	}
+	roundBet = betUnits(roundTC)
+	if bankroll > 0 && roundsInSession == 0 {
+		sessionBankroll = bankroll
+		sessionRuined = false
+	}
+}
+
+// ------------------------------------------------------- countRound -----------------------------------
+// countRound collects the stats of the round, after the showDown.  Splits are each a hand, like in the other stats.
+func countRound() {
+	bucket := &tcStats[tcBucket(roundTC)]
+	bucket.rounds++
+	var net float64
+	for i := range playerHand {
+		u := handUnits(playerHand[i].result)
+		bucket.hands++
+		bucket.sum += u
+		bucket.sumSquares += u * u
+		net += u * roundBet
+		totalUnitsBet += roundBet
+		if playerHand[i].doubledflag {
+			totalUnitsBet += roundBet
+		}
+	}
+	totalRounds++
+	totalUnitsWon += net
+	sumSquaresRound += net * net
+
+	if bankroll <= 0 {
+		return
+	}
+	if !sessionRuined {
+		sessionBankroll += net
+		sessionRuined = sessionBankroll < betUnits(math.Inf(-1))*float64(numOfPlayers) // can't cover the smallest bet.
+	}
+	roundsInSession++
+	if roundsInSession >= sessionRounds { // a session that's not finished when the run ends is not counted.
+		if sessionRuined {
+			sessionsRuined++
+		}
+		sessionResults = append(sessionResults, sessionBankroll-bankroll)
+		roundsInSession = 0
+	}
+} // countRound
+
+// meanSD returns the mean and standard deviation from the sums.
+func meanSD(n int, sum, sumSquares float64) (float64, float64) {
+	if n == 0 {
+		return 0, 0
+	}
+	mean := sum / float64(n)
+	variance := sumSquares/float64(n) - mean*mean
+	return mean, math.Sqrt(max(variance, 0))
+}
+
+// ------------------------------------------------------- wrCountingStats -----------------------------------
+// wrCountingStats writes the EV and SD per true count, the win rate of the ramp and the bankroll stats, to the screen and the results file.
+func wrCountingStats(w *bufio.Writer) {
+	if !countingWanted() {
+		return
+	}
+	var sb strings.Builder
+	name := "none"
+	if counter != nil {
+		name = counter.Name()
+	}
+	fmt.Fprintf(&sb, "\n Count is %s, %d decks, cut card at %d of %d cards, ramp is %v \n", name, decks, cutCard, len(deck), ramp)
+	if counter != nil {
+		sb.WriteString("\n   TC    rounds   freq%     hands    EV/hand%    SD/hand \n")
+		sb.WriteString(" ----------------------------------------------------------\n")
+		for i, st := range tcStats {
+			if st.rounds == 0 {
+				continue
+			}
+			label := fmt.Sprintf("%3d", i+minTC)
+			if i == 0 {
+				label = fmt.Sprintf("<=%d", minTC)
+			} else if i == len(tcStats)-1 {
+				label = fmt.Sprintf(">=%d", maxTC)
+			}
+			ev, sd := meanSD(st.hands, st.sum, st.sumSquares)
+			fmt.Fprintf(&sb, " %5s %9d %7.3f %9d %10.3f %10.4f \n", label, st.rounds, 100*float64(st.rounds)/float64(totalRounds), st.hands, 100*ev, sd)
+		}
+	}
+
+	ev, sd := meanSD(totalRounds, totalUnitsWon, sumSquaresRound)
+	fmt.Fprintf(&sb, "\n Units bet= %.1f, units won= %.1f, win rate= %.4f%% of units bet, EV/round= %.5f units, SD/round= %.4f units \n",
+		totalUnitsBet, totalUnitsWon, 100*totalUnitsWon/totalUnitsBet, ev, sd)
+
+	if bankroll > 0 {
+		mean, sessionSD := meanSD(len(sessionResults), sumOf(sessionResults, 1), sumOf(sessionResults, 2))
+		ror := riskOfRuin(ev, sd, bankroll)
+		fmt.Fprintf(&sb, " Bankroll= %.0f units, %d sessions of %d rounds, %d ruined, simulated RoR= %.3f%%, long run RoR= %.3f%% \n",
+			bankroll, len(sessionResults), sessionRounds, sessionsRuined, 100*float64(sessionsRuined)/float64(max(len(sessionResults), 1)), 100*ror)
+		fmt.Fprintf(&sb, " Session result mean= %.2f units, SD= %.2f units \n", mean, sessionSD)
+	}
+	fmt.Print(sb.String())
+	w.WriteString(sb.String())
+} // wrCountingStats
+
+// riskOfRuin is the long run RoR of a bankroll, from the EV and SD per round.  It's the usual exp(-2 * EV * bankroll / variance).
+func riskOfRuin(ev, sd, bankroll float64) float64 {
+	if ev <= 0 { // can't win in the long run, so ruin is certain.
+		return 1
+	}
+	if sd == 0 {
+		return 0
+	}
+	return math.Exp(-2 * ev * bankroll / (sd * sd))
+}
+
+func sumOf(x []float64, power int) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += math.Pow(v, float64(power))
+	}
+	return sum
+}
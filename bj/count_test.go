@@ -0,0 +1,259 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCountTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      tagTable
+		imbalance int
+		irc       int // for 6 decks
+	}{
+		//                    A   2  3  4  5  6  7  8   9  10
+		{"HILO", tagTable{0, -1, 1, 1, 1, 1, 1, 0, 0, 0, -1}, 0, 0},
+		{"KO", tagTable{0, -1, 1, 1, 1, 1, 1, 1, 0, 0, -1}, 4, -20},
+		{"OMEGA2", tagTable{0, 0, 1, 1, 2, 2, 2, 1, 0, -1, -2}, 0, 0},
+	}
+	for _, tt := range tests {
+		c, err := newCounter([]string{tt.name})
+		if err != nil {
+			t.Fatalf("newCounter(%s): %v", tt.name, err)
+		}
+		tc := c.(*tagCounter)
+		if tc.tags != tt.tags {
+			t.Errorf("%s tags = %v, want %v", tt.name, tc.tags, tt.tags)
+		}
+		if got := tc.imbalance(); got != tt.imbalance {
+			t.Errorf("%s imbalance = %d, want %d", tt.name, got, tt.imbalance)
+		}
+		tc.Reset(6)
+		if got := tc.RunningCount(); got != tt.irc {
+			t.Errorf("%s IRC for 6 decks = %d, want %d", tt.name, got, tt.irc)
+		}
+
+		// a whole shoe counts out to the IRC plus the imbalance of every deck, which is 4 for KO, its key count at the end of the shoe.
+		for range 6 {
+			for card := 1; card <= 10; card++ {
+				n := 4
+				if card == 10 {
+					n = 16
+				}
+				for range n {
+					tc.Count(card)
+				}
+			}
+		}
+		if got, want := tc.RunningCount(), tt.irc+6*tt.imbalance; got != want {
+			t.Errorf("%s count after a 6 deck shoe = %d, want %d", tt.name, got, want)
+		}
+	}
+}
+
+func TestTrueCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		cards     []int
+		decksLeft float64
+		want      float64
+	}{
+		{"HILO", []int{2, 3, 4, 5, 6, 10}, 2, 2},   // running count 4 over 2 decks
+		{"HILO", []int{10, 10, 1, 9}, 1.5, -2},     // running count -3 over 1.5 decks
+		{"HILO", []int{5, 5}, 0.1, 8},              // the decks left don't go below 1/4
+		{"OMEGA2", []int{4, 5, 6, 7, 9}, 2, 3},     // running count 6 over 2 decks
+		{"KO", []int{7, 7, 2, 10}, 2, -18},         // unbalanced, so the running count is used as is, from the IRC of -20
+		{"KO", []int{1, 10, 10, 10}, 0.5, -20 - 4}, // and the decks left don't matter
+	}
+	for _, tt := range tests {
+		c, _ := newCounter([]string{tt.name})
+		c.Reset(6)
+		for _, card := range tt.cards {
+			c.Count(card)
+		}
+		if got := c.TrueCount(tt.decksLeft); got != tt.want {
+			t.Errorf("%s true count of %v w/ %g decks left = %g, want %g", tt.name, tt.cards, tt.decksLeft, got, tt.want)
+		}
+	}
+}
+
+func TestNewCounter(t *testing.T) {
+	tests := []struct {
+		fields  string
+		name    string
+		wantErr bool
+	}{
+		{"hilo", "HILO", false},
+		{"Hi-Lo", "HILO", false},
+		{"omega-ii", "OMEGA2", false},
+		{"none", "", false},
+		{"-1 1 1 1 1 1 0 0 0 -1", "USER", false},
+		{"ZEN -1 1 1 2 2 2 1 0 0 -2", "ZEN", false},
+		{"HILO 1 2", "", true},
+		{"-1 1 1", "", true},
+		{"ZEN -1 1 1 2 x 2 1 0 0 -2", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		c, err := newCounter(strings.Fields(tt.fields))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newCounter(%q) error = %v, wantErr %t", tt.fields, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		name := ""
+		if c != nil {
+			name = c.Name()
+		}
+		if name != tt.name {
+			t.Errorf("newCounter(%q) name = %q, want %q", tt.fields, name, tt.name)
+		}
+	}
+}
+
+func TestBetRamp(t *testing.T) {
+	saved := ramp
+	defer func() { ramp = saved }()
+
+	var err error
+	ramp, err = parseRamp(strings.Fields("4:8 1:1 3:4 2:2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []rampStep{{1, 1}, {2, 2}, {3, 4}, {4, 8}}; !slices.Equal(ramp, want) {
+		t.Fatalf("parseRamp sorted = %v, want %v", ramp, want)
+	}
+	tests := []struct {
+		tc, units float64
+	}{
+		{-5, 1}, {0, 1}, {1, 1}, {1.9, 1}, {2, 2}, {2.5, 2}, {3, 4}, {4, 8}, {12, 8}, {math.Inf(-1), 1},
+	}
+	for _, tt := range tests {
+		if got := betUnits(tt.tc); got != tt.units {
+			t.Errorf("betUnits(%g) = %g, want %g", tt.tc, got, tt.units)
+		}
+	}
+
+	ramp = nil
+	if got := betUnits(5); got != 1 {
+		t.Errorf("betUnits w/o a ramp = %g, want a flat 1", got)
+	}
+
+	for _, bad := range []string{"1-1", "x:1", "1:0", "1:-2", "1:y"} {
+		if _, err := parseRamp([]string{bad}); err == nil {
+			t.Errorf("parseRamp(%q) should be an error", bad)
+		}
+	}
+}
+
+func TestRiskOfRuin(t *testing.T) {
+	tests := []struct {
+		ev, sd, bankroll, want float64
+	}{
+		{0.01, 1.1, 100, math.Exp(-2 * 0.01 * 100 / 1.21)}, // about 19.1%
+		{0.02, 1.2, 400, math.Exp(-2 * 0.02 * 400 / 1.44)}, // about 0.0015%
+		{0, 1.1, 1000, 1},
+		{-0.005, 1.1, 1000, 1},
+		{0.01, 0, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := riskOfRuin(tt.ev, tt.sd, tt.bankroll); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("riskOfRuin(%g, %g, %g) = %g, want %g", tt.ev, tt.sd, tt.bankroll, got, tt.want)
+		}
+	}
+	if got := riskOfRuin(0.01, 1.1, 100); math.Abs(got-0.1915) > 0.0001 {
+		t.Errorf("riskOfRuin(0.01, 1.1, 100) = %.4f, want about 0.1915", got)
+	}
+}
+
+func TestReadCountingOption(t *testing.T) {
+	savedCounter, savedRamp, savedDecks, savedPen, savedBankroll := counter, ramp, decks, penetration, bankroll
+	defer func() {
+		counter, ramp, decks, penetration, bankroll = savedCounter, savedRamp, savedDecks, savedPen, savedBankroll
+	}()
+
+	tests := []struct {
+		line    string
+		wantErr bool
+		check   func() bool
+	}{
+		{"DECKS 6 the usual shoe", false, func() bool { return decks == 6 }},
+		{"DECKS 2", false, func() bool { return decks == 2 }},
+		{"PEN 0.8 about 5 of 6 decks", false, func() bool { return penetration == 0.8 }},
+		{"BANKROLL 400 units", false, func() bool { return bankroll == 400 }},
+		{"COUNT KO for the IRC", false, func() bool { return counter != nil && counter.Name() == "KO" }},
+		{"COUNT ZEN -1 1 1 2 2 2 1 0 0 -2 is my own", false, func() bool { return counter != nil && counter.Name() == "ZEN" }},
+		{"COUNT -1 1 1 1 1 1 0 0 0 -1 hilo again", false, func() bool { return counter != nil && counter.Name() == "USER" }},
+		{"RAMP 1:1 2:4 a steep one", false, func() bool { return slices.Equal(ramp, []rampStep{{1, 1}, {2, 4}}) }},
+		{"DECKS six", true, nil},
+		{"DECKS", true, nil},
+		{"COUNT ZEN -1 1 1 2 comment", true, nil},
+	}
+	for _, tt := range tests {
+		fields := strings.Fields(tt.line)
+		isCounting, err := readCountingOption(fields[0], fields[1:])
+		if !isCounting {
+			t.Errorf("%q is not a counting line", tt.line)
+			continue
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q error = %v, wantErr %t", tt.line, err, tt.wantErr)
+			continue
+		}
+		if tt.check != nil && !tt.check() {
+			t.Errorf("%q didn't set its option", tt.line)
+		}
+	}
+	if isCounting, _ := readCountingOption("HARD", nil); isCounting {
+		t.Errorf("HARD is not a counting keyword")
+	}
+}
+
+func TestReshuffleDiscards(t *testing.T) {
+	savedDeck, savedCard, savedStart, savedCounter, savedDecks := deck, currentCard, roundStartCard, counter, decks
+	defer func() {
+		deck, currentCard, roundStartCard, counter, decks = savedDeck, savedCard, savedStart, savedCounter, savedDecks
+	}()
+
+	deck = make([]int, 0, 52)
+	decks = 1
+	InitDeck()
+	full := slices.Clone(deck)
+	counter, _ = newCounter([]string{"HILO"})
+	setCutCard()
+
+	roundStartCard = len(deck) - 5 // the round has dealt the last 4 cards, and needs another.
+	currentCard = len(deck) - 1
+	inPlay := slices.Clone(deck[roundStartCard+1:])
+	reshuffleDiscards()
+
+	if got := deck[:currentCard+1]; !slices.Equal(got, inPlay) {
+		t.Errorf("cards on the table = %v, want %v in front of the next card", got, inPlay)
+	}
+	sortedDeck := slices.Clone(deck)
+	slices.Sort(sortedDeck)
+	slices.Sort(full)
+	if !slices.Equal(sortedDeck, full) {
+		t.Errorf("the deck lost or gained cards in the reshuffle")
+	}
+	want := 0
+	for _, card := range inPlay {
+		want += countTables["HILO"][card]
+	}
+	if got := counter.RunningCount(); got != want {
+		t.Errorf("running count after the reshuffle = %d, want %d from the cards on the table", got, want)
+	}
+
+	card := getCard()
+	if currentCard != len(inPlay) {
+		t.Errorf("getCard after the reshuffle dealt position %d, want %d", currentCard, len(inPlay))
+	}
+	if card != deck[len(inPlay)] {
+		t.Errorf("getCard = %d, want %d", card, deck[len(inPlay)])
+	}
+}
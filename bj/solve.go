@@ -0,0 +1,514 @@
+package main // solve.go
+
+/*
+  Strategy solver, for the -solve flag.  Instead of playing hands, it works out the expected value of Stand, Hit, Double, Split and Surrender for each hand
+  against each dealer up card, by combinatorial analysis of the shoe, and writes the best play as a .strat file that ReadStrategyMatrix can read.
+
+  For each up card and each 2 card hand, the dealer's final totals are worked out exactly from the shoe less those 3 cards, and given that the dealer does not
+  have blackjack, as the dealer peeks.  The player's draws are taken from that shoe too, card by card.  But the dealer's totals are not redone for the cards
+  the player draws, so this is what's called total dependent after the first 2 cards.  That's how a strategy matrix works anyway.
+  The EV of a row is the average of the 2 card hands that make that total, weighted by how likely they are.  Pairs are in the pair rows, not the hard rows,
+  except for hard 4 and hard 20, which can only be made by a pair.
+
+  The rules are the ones the simulator plays:
+    The dealer hits soft 17 if DEALER17 or -h17.
+    Double on any 2 cards, and after a split, except a split hand w/ an ace can't double unless RESPLIT or -resplit.
+    Split hands can't be blackjack.  Resplits are not counted, which makes splitting look a little worse than it is.
+    Surrender is late surrender, and only in the hard rows 14 - 16, as those are the only rows SurrenderStrategyMatrix has after a hit.  -nosur turns it off.
+    Aces are always split by playAhand, so the AA row doesn't matter, but it's written anyway.
+  Double in a row means hit if more cards have been taken, which is how hitMePlayer does it.
+
+  The EV tables are written after the matrix as comments, so the file can still be read by ReadStrategyMatrix.  If a .strat file is also on the
+  command line, its rules are used and its matrix is compared to the best play, cell by cell, to show what each difference costs.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	ct "github.com/daviddengcn/go-colortext"
+	ctfmt "github.com/daviddengcn/go-colortext/fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+type shoeType [11]int // count of each card in the shoe, where Ace is 1 and 10 is all the 10 value cards.
+
+type dealerDistType [6]float64 // probability the dealer ends on 17, 18, 19, 20, 21, or busts.
+
+const bustIdx = 5
+
+var solveFilename string
+var noSurrenderFlag bool
+
+type actionEVType [5]float64 // indexed by Stand, Hit, Double, Split, Surrender.  math.Inf(-1) if the play is not allowed.
+
+// solvedCellType is one cell of a strategy matrix.  weight is the sum of the probabilities of the hands that make the row.
+type solvedCellType struct {
+	ev     actionEVType
+	weight float64
+	best   int
+}
+
+var solvedHard, solvedSoft [22][11]solvedCellType // [row][dealer up card], where the up card is 1 .. 10.
+var solvedPair [11][11]solvedCellType
+
+func newShoe(numDecks int) shoeType {
+	var shoe shoeType
+	for card := 1; card <= 9; card++ {
+		shoe[card] = 4 * numDecks
+	}
+	shoe[10] = 16 * numDecks
+	return shoe
+}
+
+func (s *shoeType) total() int {
+	n := 0
+	for _, c := range s[1:] {
+		n += c
+	}
+	return n
+}
+
+// handValue counts an ace as 11 if that doesn't bust, and says if the hand is soft.
+func handValue(hard int, hasAce bool) (int, bool) {
+	if hasAce && hard+10 <= 21 {
+		return hard + 10, true
+	}
+	return hard, false
+}
+
+// ------------------------------------------------------- dealerDist -----------------------------------
+// dealerDist works out the dealer's final totals, given the up card, and that the dealer does not have blackjack.
+func dealerDist(shoe shoeType, up int) dealerDistType {
+	var dist dealerDistType
+	noBJ := 0 // the hole card can't make blackjack
+	switch up {
+	case Ace:
+		noBJ = 10
+	case 10:
+		noBJ = Ace
+	}
+	n := shoe.total()
+	if noBJ > 0 {
+		n -= shoe[noBJ]
+	}
+	for hole := 1; hole <= 10; hole++ {
+		if hole == noBJ || shoe[hole] == 0 {
+			continue
+		}
+		p := float64(shoe[hole]) / float64(n)
+		shoe[hole]--
+		dealerDraw(&shoe, up+hole, up == Ace || hole == Ace, p, &dist)
+		shoe[hole]++
+	}
+	return dist
+}
+
+func dealerDraw(shoe *shoeType, hard int, hasAce bool, p float64, dist *dealerDistType) {
+	value, soft := handValue(hard, hasAce)
+	if value > 21 {
+		dist[bustIdx] += p
+		return
+	}
+	if value >= 18 || (value == 17 && !(soft && dealerHitsSoft17)) {
+		dist[value-17] += p
+		return
+	}
+	n := float64(shoe.total())
+	for card := 1; card <= 10; card++ {
+		if shoe[card] == 0 {
+			continue
+		}
+		q := float64(shoe[card]) / n
+		shoe[card]--
+		dealerDraw(shoe, hard+card, hasAce || card == Ace, p*q, dist)
+		shoe[card]++
+	}
+}
+
+// ------------------------------------------------------- player EVs -----------------------------------
+
+func standEV(value int, dist *dealerDistType) float64 {
+	if value > 21 {
+		return -1
+	}
+	ev := dist[bustIdx]
+	for i := 0; i < bustIdx; i++ {
+		switch dealerTotal := 17 + i; {
+		case value > dealerTotal:
+			ev += dist[i]
+		case value < dealerTotal:
+			ev -= dist[i]
+		}
+	}
+	return ev
+}
+
+// hitEV is the EV of taking a card, and then playing the best of hit or stand.
+func hitEV(shoe *shoeType, hard int, hasAce bool, dist *dealerDistType) float64 {
+	n := float64(shoe.total())
+	ev := 0.0
+	for card := 1; card <= 10; card++ {
+		if shoe[card] == 0 {
+			continue
+		}
+		p := float64(shoe[card]) / n
+		newHard, newAce := hard+card, hasAce || card == Ace
+		value, _ := handValue(newHard, newAce)
+		switch {
+		case value > 21:
+			ev -= p
+		case value == 21:
+			ev += p * standEV(21, dist)
+		default:
+			shoe[card]--
+			ev += p * max(standEV(value, dist), hitEV(shoe, newHard, newAce, dist))
+			shoe[card]++
+		}
+	}
+	return ev
+}
+
+// doubleEV is the EV of doubling, in units of the original bet.
+func doubleEV(shoe *shoeType, hard int, hasAce bool, dist *dealerDistType) float64 {
+	n := float64(shoe.total())
+	ev := 0.0
+	for card := 1; card <= 10; card++ {
+		if shoe[card] == 0 {
+			continue
+		}
+		value, _ := handValue(hard+card, hasAce || card == Ace)
+		ev += float64(shoe[card]) / n * standEV(value, dist)
+	}
+	return 2 * ev
+}
+
+// handEVs are the EVs of the plays for a hand of 2 cards.
+func handEVs(shoe *shoeType, c1, c2 int, canDouble bool, dist *dealerDistType) actionEVType {
+	hard, hasAce := c1+c2, c1 == Ace || c2 == Ace
+	value, _ := handValue(hard, hasAce)
+	ev := actionEVType{Stand: standEV(value, dist), Hit: hitEV(shoe, hard, hasAce, dist), Double: math.Inf(-1), Split: math.Inf(-1), Surrender: math.Inf(-1)}
+	if canDouble {
+		ev[Double] = doubleEV(shoe, hard, hasAce, dist)
+	}
+	return ev
+}
+
+func bestOf(ev actionEVType) int {
+	best := Stand
+	for a := Hit; a <= Surrender; a++ {
+		if ev[a] > ev[best] {
+			best = a
+		}
+	}
+	return best
+}
+
+// splitEV is for splitting a pair of c.  Each hand gets a second card and is played the best way, w/o resplitting.
+func splitEV(shoe *shoeType, c int, dist *dealerDistType) float64 {
+	n := float64(shoe.total())
+	ev := 0.0
+	for card := 1; card <= 10; card++ {
+		if shoe[card] == 0 {
+			continue
+		}
+		p := float64(shoe[card]) / n
+		shoe[card]--
+		canDouble := resplitAcesFlag || (c != Ace && card != Ace) // hitMePlayer won't double a soft split hand unless resplit.
+		hand := handEVs(shoe, c, card, canDouble, dist)
+		ev += p * hand[bestOf(hand)]
+		shoe[card]++
+	}
+	return 2 * ev
+}
+
+// ------------------------------------------------------- solveStrategy -----------------------------------
+// solveStrategy fills in the solved matrices, and returns the EV of a round played w/ them, counting blackjacks.
+func solveStrategy() float64 {
+	full := newShoe(decks)
+	for up := 1; up <= 10; up++ {
+		for c1 := 1; c1 <= 10; c1++ {
+			for c2 := c1; c2 <= 10; c2++ {
+				shoe := full
+				shoe[up]--
+				w := float64(shoe[c1]) / float64(shoe.total())
+				shoe[c1]--
+				w *= float64(shoe[c2]) / float64(shoe.total())
+				shoe[c2]--
+				if c1 != c2 {
+					w *= 2 // either order
+				}
+				if c1 == Ace && c2 == 10 {
+					continue // blackjack, there's nothing to decide.
+				}
+				dist := dealerDist(shoe, up)
+				ev := handEVs(&shoe, c1, c2, true, &dist)
+				hard := c1 + c2
+				if !noSurrenderFlag && c1 != Ace && hard >= 14 && hard <= 16 {
+					ev[Surrender] = -0.5
+				}
+				var cell *solvedCellType
+				switch {
+				case c1 == c2:
+					pairEV := ev
+					pairEV[Split] = splitEV(&shoe, c1, &dist)
+					addToCell(&solvedPair[c1][up], pairEV, w)
+					if hard == 4 || hard == 20 { // only a pair makes these.
+						cell = &solvedHard[hard][up]
+					}
+				case c1 == Ace:
+					cell = &solvedSoft[hard][up]
+				default:
+					cell = &solvedHard[hard][up]
+				}
+				if cell != nil {
+					addToCell(cell, ev, w)
+				}
+			}
+		}
+		for row := range solvedHard {
+			finishCell(&solvedHard[row][up])
+			finishCell(&solvedSoft[row][up])
+		}
+		for row := range solvedPair {
+			finishCell(&solvedPair[row][up])
+		}
+	}
+	return roundEV(full)
+} // solveStrategy
+
+func addToCell(cell *solvedCellType, ev actionEVType, w float64) {
+	for a := range ev {
+		if math.IsInf(ev[a], -1) {
+			cell.ev[a] = ev[a]
+		} else if !math.IsInf(cell.ev[a], -1) {
+			cell.ev[a] += w * ev[a]
+		}
+	}
+	cell.weight += w
+}
+
+// finishCell turns the weighted sums into averages, and picks the best play.
+func finishCell(cell *solvedCellType) {
+	if cell.weight == 0 {
+		return
+	}
+	for a := range cell.ev {
+		if !math.IsInf(cell.ev[a], -1) {
+			cell.ev[a] /= cell.weight
+		}
+	}
+	cell.best = bestOf(cell.ev)
+}
+
+// ------------------------------------------------------- roundEV -----------------------------------
+// roundEV is the EV of a round played w/ the solved matrices, per hand of the row.  Blackjack pays 3:2, and a dealer blackjack only takes the bet.
+func roundEV(full shoeType) float64 {
+	total := 0.0
+	for up := 1; up <= 10; up++ {
+		shoe := full
+		pUp := float64(shoe[up]) / float64(shoe.total())
+		shoe[up]--
+		for c1 := 1; c1 <= 10; c1++ {
+			for c2 := c1; c2 <= 10; c2++ {
+				s := shoe
+				w := float64(s[c1]) / float64(s.total())
+				s[c1]--
+				w *= float64(s[c2]) / float64(s.total())
+				s[c2]--
+				if c1 != c2 {
+					w *= 2
+				}
+				pDealerBJ := 0.0
+				switch up {
+				case Ace:
+					pDealerBJ = float64(s[10]) / float64(s.total())
+				case 10:
+					pDealerBJ = float64(s[Ace]) / float64(s.total())
+				}
+				var ev float64
+				switch {
+				case c1 == Ace && c2 == 10:
+					ev = 1.5 * (1 - pDealerBJ)
+				case c1 == c2:
+					ev = -pDealerBJ + (1-pDealerBJ)*solvedPair[c1][up].ev[solvedPair[c1][up].best]
+				case c1 == Ace:
+					ev = -pDealerBJ + (1-pDealerBJ)*solvedSoft[c1+c2][up].ev[solvedSoft[c1+c2][up].best]
+				default:
+					ev = -pDealerBJ + (1-pDealerBJ)*solvedHard[c1+c2][up].ev[solvedHard[c1+c2][up].best]
+				}
+				total += pUp * w * ev
+			}
+		}
+	}
+	return total
+} // roundEV
+
+// ------------------------------------------------------- strat codes -----------------------------------
+
+var stratCode = []string{"S", "H", "D", "SP", "SUR"} // matches GetOption
+
+// hardCode is the code for a cell of a hard or soft row.  Rows that no 2 card hand makes, like hard 21, are stand at 17 and up, and hit under that.
+func hardCode(cell solvedCellType, row int) string {
+	if cell.weight == 0 {
+		if row >= 17 {
+			return stratCode[Stand]
+		}
+		return stratCode[Hit]
+	}
+	return stratCode[cell.best]
+}
+
+// pairCode is split, stand, or hit, which means go by the hard or soft row, as playAhand does.
+func pairCode(cell solvedCellType) string {
+	switch cell.best {
+	case Split, Stand:
+		return stratCode[cell.best]
+	}
+	return stratCode[Hit]
+}
+
+func pairRowName(card int) string {
+	if card == Ace {
+		return "AA"
+	}
+	return fmt.Sprintf("%d%d", card, card)
+}
+
+// ------------------------------------------------------- writeSolvedStrategy -----------------------------------
+func writeSolvedStrategy(w *bufio.Writer, ev float64) {
+	fmt.Fprintf(w, "# Strategy solved by bj -solve on %s, for %d decks.  Dealer hits soft 17 is %t, resplit aces is %t, surrender is %t.\n",
+		time.Now().Format("Mon Jan 2 2006"), decks, dealerHitsSoft17, resplitAcesFlag, !noSurrenderFlag)
+	fmt.Fprintf(w, "# EV of a round w/ this strategy is %.4f%%.  The EV tables below are given that the dealer does not have blackjack.\n", 100*ev)
+	if dealerHitsSoft17 {
+		w.WriteString("DEALER17\n")
+	}
+	if resplitAcesFlag {
+		w.WriteString("RESPLIT\n")
+	}
+	fmt.Fprintf(w, "DECKS %d\n\n", decks)
+
+	w.WriteString("#     A   2   3   4   5   6   7   8   9   10\n")
+	writeRow := func(name string, cells [11]solvedCellType, code func(solvedCellType) string) {
+		fmt.Fprintf(w, "%-5s", name)
+		for up := 1; up <= 10; up++ {
+			fmt.Fprintf(w, " %-3s", code(cells[up]))
+		}
+		w.WriteString("\n")
+	}
+	for row := 4; row <= 21; row++ {
+		writeRow(fmt.Sprint(row), solvedHard[row], func(c solvedCellType) string { return hardCode(c, row) })
+	}
+	w.WriteString("\n")
+	for row := 3; row <= 11; row++ {
+		writeRow(fmt.Sprintf("S%d", row), solvedSoft[row], func(c solvedCellType) string { return hardCode(c, row+10) })
+	}
+	w.WriteString("\n")
+	for card := 1; card <= 10; card++ {
+		writeRow(pairRowName(card), solvedPair[card], pairCode)
+	}
+
+	for a := Stand; a <= Split; a++ {
+		fmt.Fprintf(w, "\n# EV%% of %s\n", []string{"Stand", "Hit", "Double", "Split"}[a])
+		w.WriteString("#           A       2       3       4       5       6       7       8       9      10\n")
+		writeEVRow := func(name string, cells [11]solvedCellType) {
+			if cells[2].weight == 0 || math.IsInf(cells[2].ev[a], -1) {
+				return
+			}
+			fmt.Fprintf(w, "# %-5s", name)
+			for up := 1; up <= 10; up++ {
+				fmt.Fprintf(w, " %7.1f", 100*cells[up].ev[a])
+			}
+			w.WriteString("\n")
+		}
+		if a != Split {
+			for row := 4; row <= 21; row++ {
+				writeEVRow(fmt.Sprint(row), solvedHard[row])
+			}
+			for row := 3; row <= 11; row++ {
+				writeEVRow(fmt.Sprintf("S%d", row), solvedSoft[row])
+			}
+		}
+		for card := 1; card <= 10; card++ {
+			writeEVRow(pairRowName(card), solvedPair[card])
+		}
+	}
+} // writeSolvedStrategy
+
+// ------------------------------------------------------- compareStrategy -----------------------------------
+// compareStrategy shows the cells where the matrices that were read in differ from the solved ones, and what each costs in EV.
+func compareStrategy(w *bufio.Writer) {
+	var sb strings.Builder
+	diffs := 0
+	compare := func(name string, row OptionRowType, cells [11]solvedCellType, isPair bool) {
+		for up := 1; up <= 10 && up <= len(row); up++ {
+			cell := cells[up]
+			given := row[up-1]
+			if cell.weight == 0 || given == ErrorValue || (isPair && given != Split && cell.best != Split) {
+				continue // for a pair that's not split, the hard or soft row is what matters.
+			}
+			if given == cell.best || (given == Double && math.IsInf(cell.ev[given], -1)) { // a double that's not allowed is a hit.
+				continue
+			}
+			givenEV := cell.ev[given]
+			if isPair && given != Split {
+				givenEV = max(cell.ev[Stand], cell.ev[Hit], cell.ev[Double])
+			}
+			if math.IsInf(givenEV, -1) {
+				continue
+			}
+			diffs++
+			fmt.Fprintf(&sb, " %-5s vs %-2s: have %-4s best is %-4s costs %.2f%% \n", name, cardName(up), OptionName[given], OptionName[cell.best],
+				100*(cell.ev[cell.best]-givenEV))
+		}
+	}
+	for row := 4; row <= 21; row++ {
+		compare(fmt.Sprint(row), StrategyMatrix[row], solvedHard[row], false)
+	}
+	for row := 3; row <= 11; row++ {
+		compare(fmt.Sprintf("S%d", row), SoftStrategyMatrix[row], solvedSoft[row], false)
+	}
+	for card := 2; card <= 10; card++ { // aces are always split.
+		compare(pairRowName(card), PairStrategyMatrix[card], solvedPair[card], true)
+	}
+	fmt.Fprintf(&sb, " %s has %d cells that differ from the solved strategy. \n", Filename, diffs)
+	fmt.Print(sb.String())
+	w.WriteString("\n# Compared to " + Filename + "\n")
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		w.WriteString("# " + line + "\n")
+	}
+} // compareStrategy
+
+func cardName(card int) string {
+	if card == Ace {
+		return "A"
+	}
+	return fmt.Sprint(card)
+}
+
+// ------------------------------------------------------- solveMain -----------------------------------
+// solveMain is main for -solve.  compare is true if a .strat file was read in.
+func solveMain(compare bool) {
+	fmt.Printf(" Solving for %d decks.  Dealer hits soft 17 is %t, resplit aces is %t, surrender is %t.\n", decks, dealerHitsSoft17, resplitAcesFlag, !noSurrenderFlag)
+	ev := solveStrategy()
+	ctfmt.Printf(ct.Green, false, " EV of a round w/ the solved strategy is %.4f%%\n", 100*ev)
+
+	if !strings.Contains(solveFilename, ".") {
+		solveFilename += ".strat"
+	}
+	f, err := os.Create(solveFilename)
+	if err != nil {
+		fmt.Println(" Could not create", solveFilename, ":", err, ".  Exiting.")
+		os.Exit(1)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	defer w.Flush()
+	writeSolvedStrategy(w, ev)
+	if compare {
+		compareStrategy(w)
+	}
+	fmt.Println(" Wrote", solveFilename)
+} // solveMain
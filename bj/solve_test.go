@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"testing"
+)
+
+// solveFor solves a game of numDecks w/ the dealer hitting soft 17 or not, and surrender allowed, and puts the rules back after.
+func solveFor(t *testing.T, numDecks int, h17 bool) float64 {
+	t.Helper()
+	savedDecks, savedH17, savedResplit, savedNoSur := decks, dealerHitsSoft17, resplitAcesFlag, noSurrenderFlag
+	t.Cleanup(func() {
+		decks, dealerHitsSoft17, resplitAcesFlag, noSurrenderFlag = savedDecks, savedH17, savedResplit, savedNoSur
+		solvedHard, solvedSoft, solvedPair = [22][11]solvedCellType{}, [22][11]solvedCellType{}, [11][11]solvedCellType{}
+	})
+	decks, dealerHitsSoft17, resplitAcesFlag, noSurrenderFlag = numDecks, h17, false, false
+	solvedHard, solvedSoft, solvedPair = [22][11]solvedCellType{}, [22][11]solvedCellType{}, [11][11]solvedCellType{}
+	return solveStrategy()
+}
+
+func TestSolveStrategy(t *testing.T) {
+	tests := []struct {
+		name string
+		h17  bool
+		cell *solvedCellType
+		want int
+	}{
+		{"16 vs 10", false, &solvedHard[16][10], Surrender},
+		{"15 vs 10", false, &solvedHard[15][10], Surrender},
+		{"16 vs 9", false, &solvedHard[16][9], Surrender},
+		{"16 vs 6", false, &solvedHard[16][6], Stand},
+		{"12 vs 2", false, &solvedHard[12][2], Hit},
+		{"12 vs 4", false, &solvedHard[12][4], Stand},
+		{"11 vs A", false, &solvedHard[11][Ace], Hit},
+		{"11 vs 10", false, &solvedHard[11][10], Double},
+		{"11 vs A", true, &solvedHard[11][Ace], Double},
+		{"10 vs 9", false, &solvedHard[10][9], Double},
+		{"A,8 vs 6", false, &solvedSoft[9][6], Stand},
+		{"A,8 vs 6", true, &solvedSoft[9][6], Double},
+		{"A,7 vs 9", false, &solvedSoft[8][9], Hit},
+		{"8,8 vs 10", false, &solvedPair[8][10], Split}, // split beats surrender, under S17
+		{"8,8 vs 9", false, &solvedPair[8][9], Split},
+		{"10,10 vs 6", false, &solvedPair[10][6], Stand},
+	}
+	for _, h17 := range []bool{false, true} {
+		ev := solveFor(t, 6, h17)
+		if ev > 0 || ev < -0.01 { // basic strategy in a 6 deck game is a house edge of about 0.5%.
+			t.Errorf("6 decks, h17 %t: EV of a round is %.4f%%, want a little under 0", h17, 100*ev)
+		}
+		for _, tt := range tests {
+			if tt.h17 != h17 {
+				continue
+			}
+			if got := tt.cell.best; got != tt.want {
+				t.Errorf("6 decks, h17 %t: %s is %s, want %s.  EVs are %v", h17, tt.name, OptionName[got], OptionName[tt.want], tt.cell.ev)
+			}
+		}
+	}
+
+	solveFor(t, 6, false)
+	if ev := solvedHard[16][10].ev; ev[Surrender] != -0.5 || ev[Stand] > -0.5 || ev[Hit] > -0.5 {
+		t.Errorf("16 vs 10 EVs are %v, want surrender at -0.5 and stand and hit worse than that", ev)
+	}
+	if ev := solvedHard[4][5].ev[Surrender]; !math.IsInf(ev, -1) {
+		t.Errorf("surrender of hard 4 has an EV of %g, want it not allowed", ev)
+	}
+}
+
+func TestSolvedStrategyReadsBack(t *testing.T) {
+	for _, h17 := range []bool{false, true} {
+		ev := solveFor(t, 6, h17)
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeSolvedStrategy(w, ev)
+		w.Flush()
+
+		savedStrat, savedSoft, savedPair := StrategyMatrix, SoftStrategyMatrix, PairStrategyMatrix
+		StrategyMatrix, SoftStrategyMatrix, PairStrategyMatrix = [22]OptionRowType{}, [22]OptionRowType{}, [11]OptionRowType{}
+		decks, dealerHitsSoft17 = 1, false
+		ReadStrategyMatrix(bytes.NewReader(buf.Bytes()))
+		gotStrat, gotSoft, gotPair := StrategyMatrix, SoftStrategyMatrix, PairStrategyMatrix
+		StrategyMatrix, SoftStrategyMatrix, PairStrategyMatrix = savedStrat, savedSoft, savedPair
+
+		if decks != 6 || dealerHitsSoft17 != h17 {
+			t.Errorf("h17 %t: read back %d decks and h17 %t", h17, decks, dealerHitsSoft17)
+		}
+		check := func(name string, row OptionRowType, code func(up int) string) {
+			if len(row) != 10 {
+				t.Errorf("h17 %t: row %s read back w/ %d cells, want 10", h17, name, len(row))
+				return
+			}
+			for up := 1; up <= 10; up++ {
+				if got, want := stratCode[row[up-1]], code(up); got != want {
+					t.Errorf("h17 %t: %s vs %s read back as %s, want %s", h17, name, cardName(up), got, want)
+				}
+			}
+		}
+		for row := 4; row <= 21; row++ {
+			check(cardName(row), gotStrat[row], func(up int) string { return hardCode(solvedHard[row][up], row) })
+		}
+		for row := 3; row <= 11; row++ {
+			check("S"+cardName(row), gotSoft[row], func(up int) string { return hardCode(solvedSoft[row][up], row+10) })
+		}
+		for card := 1; card <= 10; card++ {
+			check(pairRowName(card), gotPair[card], func(up int) string { return pairCode(solvedPair[card][up]) })
+		}
+	}
+}
@@ -31,6 +31,13 @@ REVISION HISTORY
 23 Mar 21 -- Changed the limits in EASTER function.
  8 Apr 21 -- Converted to module name src.
 21 Oct 21 -- golangci-lint said I have redundant break statements, so I removed them.  I likely lifted that routine from my C++ code which needs them.
+18 Oct 26 -- Added HolidaySet, which returns the holidays of a range of years as a timlibg.HolidaySet for the business day functions.
+18 Oct 26 -- Added the rule based calendars in rules.go.  EASTER uses the computus there outside of 1900 - 2100, as the golden number
+             table is a week off for some years in the 1800's.  GetHolidays now works for the years JULIAN does.
+18 Oct 26 -- HolidaySet now uses the US calendar from rules.go, as GetHolidays doesn't have the fixed date holidays.
+18 Oct 26 -- To be clear about the EASTER change above, it gives different answers than before for 1800 - 1899.  The table had Easter a week late
+             in 1805, 1818, 1822, 1825, 1842, 1845, 1849, 1869, 1873 and 1893, and these are now the right dates, so GetHolidays changes too.
+             The rest of the 1800's, and 1900 - 2100, are the same as before.  Before 1800 and after 2100, it used to return 0, 0.
 */
 
 type MDType struct { // MDType is a contraction of Month Day Type, and Go export caps rules apply.
@@ -78,9 +85,8 @@ func EASTER(YEAR int) (MM, DD int) {
 	   is the following Sunday.
 	*/
 
-	if (YEAR < 1800) || (YEAR > 2100) { // changed these limits 3/23/21
-		MM = 0
-		DD = 0
+	if (YEAR < 1900) || (YEAR > 2100) { // changed these limits 3/23/21.  The table is wrong for some years in the 1800's, like 1818, so it's 1900 again and the computus in rules.go does the rest.
+		return EasterDate(YEAR)
 	} else {
 		GOLDENNUM := (YEAR % 19) + 1
 		switch GOLDENNUM {
@@ -179,7 +185,7 @@ func GetHolidays(y int) HolType {
 
 	Holidays := HolType{}
 
-	if y < 1800 || y > 2100 {
+	if y < 1700 || y > 2500 { // the range of timlibg.JULIAN
 		return Holidays // returning a zeroed out Holidays, including Valid field being false
 	}
 
@@ -217,4 +223,16 @@ func GetHolidays(y int) HolType {
 	return Holidays
 } // GetHolidays
 
+//*****************************************************************************************
+
+// HolidaySet -- returns the observed holidays of the US calendar in rules.go for the years from y1 thru y2, for timlibg.AddBusinessDays and BusinessDaysBetween.
+// GetHolidays only has the holidays that move around, so New Year's, July 4th, Christmas and the others on a fixed date weren't in the set.
+func HolidaySet(y1, y2 int) timlibg.HolidaySet {
+	cals, _ := Calendars() // a bad holidaycalc.txt still returns the built in calendars.
+	if cal, ok := cals["US"]; ok {
+		return cal.HolidaySet(y1, y2)
+	}
+	return make(timlibg.HolidaySet)
+} // HolidaySet
+
 // END holidaycalc.go
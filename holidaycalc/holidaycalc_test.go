@@ -0,0 +1,34 @@
+package holidaycalc
+
+import (
+	"testing"
+	"time"
+
+	"src/timlibg"
+)
+
+func TestHolidaySet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // so a holidaycalc.txt in the real home directory doesn't change the US calendar.
+	hols := HolidaySet(2025, 2026)
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{time.Date(2025, time.December, 25, 0, 0, 0, 0, time.Local), true},
+		{time.Date(2026, time.July, 3, 0, 0, 0, 0, time.Local), true}, // July 4th is a Saturday, so it's observed on Friday.
+		{time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local), true},
+		{time.Date(2026, time.November, 26, 0, 0, 0, 0, time.Local), true}, // Thanksgiving
+		{time.Date(2025, time.December, 24, 0, 0, 0, 0, time.Local), false},
+	}
+	for _, tc := range tests {
+		if got := hols.Contains(tc.date); got != tc.want {
+			t.Errorf("HolidaySet has %s is %t, want %t", tc.date.Format("Jan 2 2006"), got, tc.want)
+		}
+	}
+
+	// Dec 24 2025 is a Wednesday, so the next business day skips Christmas.
+	next := timlibg.AddBusinessDays(time.Date(2025, time.December, 24, 0, 0, 0, 0, time.Local), 1, hols)
+	if want := time.Date(2025, time.December, 26, 0, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Errorf("1 business day after Dec 24 2025 is %s, want %s", next.Format("Jan 2 2006"), want.Format("Jan 2 2006"))
+	}
+}
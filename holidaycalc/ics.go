@@ -0,0 +1,359 @@
+package holidaycalc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- iCalendar, RFC 5545, so holidays and schedules can go into a calendar app, and calendars from a calendar app can be put on calgo's month grid.
+                 WriteICS writes VEVENTs, HolidayEvents makes them from a Calendar, and AllDayEvent makes one for anything else, like a vacation assignment from lint.
+                 ReadICS reads the VEVENTs of a file back in.  It only knows about what's needed for a month grid, which is the summary and the dates.  Recurrence rules are ignored,
+                 so a repeating event only shows up on its first date.
+  18 Oct 26 -- A DTSTART or DTEND is a DATE only if it has VALUE=DATE, as the RFC says, instead of any value that's 8 chars long.
+                 A VEVENT w/o a DTSTART is skipped w/ a warning, instead of failing the whole file.  ReadICS returns the warnings.
+*/
+
+const icsProdID = "-//drrob1//holidaycalc//EN"
+const icsDateFormat = "20060102"
+const icsDateTimeFormat = "20060102T150405"
+const icsMaxLine = 75 // octets, not counting the CRLF
+
+// Event -- one VEVENT.  For an all day event End is the day after the last day, as DTEND is in the file, so a one day event has End = Start + 1 day.
+type Event struct {
+	Summary, Description, Location, UID string
+	Start, End                          time.Time
+	AllDay                              bool
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+var icsUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+var durationRegex = regexp.MustCompile(`^([+-]?)P(?:([0-9]+)W)?(?:([0-9]+)D)?(?:T(?:([0-9]+)H)?(?:([0-9]+)M)?(?:([0-9]+)S)?)?$`)
+var uidCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ----------------------------------------------- AllDayEvent, HolidayEvents
+
+// AllDayEvent -- an event from first thru last, inclusive.
+func AllDayEvent(summary string, first, last time.Time) Event {
+	start := dateOf(first.Year(), int(first.Month()), first.Day())
+	end := dateOf(last.Year(), int(last.Month()), last.Day()).AddDate(0, 0, 1)
+	if !end.After(start) {
+		end = start.AddDate(0, 0, 1)
+	}
+	return Event{Summary: summary, Start: start, End: end, AllDay: true}
+} // AllDayEvent
+
+// HolidayEvents -- the holidays of year y as all day events.  A holiday observed on another day gets a second event on that day, as that's the day off.
+func HolidayEvents(cal *Calendar, y int) []Event {
+	events := make([]Event, 0, len(cal.Rules)+5)
+	for _, h := range cal.Holidays(y) {
+		e := AllDayEvent(h.Name, h.Date, h.Date)
+		e.Description = cal.Name + " holiday"
+		events = append(events, e)
+		if !h.Observed.Equal(h.Date) {
+			e = AllDayEvent(h.Name+" (observed)", h.Observed, h.Observed)
+			e.Description = fmt.Sprintf("%s holiday, observed for %s", cal.Name, h.Date.Format("Mon Jan 2, 2006"))
+			events = append(events, e)
+		}
+	}
+	return events
+} // HolidayEvents
+
+// ----------------------------------------------- Covers
+
+// Covers -- true if the event is on t's date, for putting events on a month grid.
+func (e Event) Covers(t time.Time) bool {
+	day := dateOf(t.Year(), int(t.Month()), t.Day())
+	if e.AllDay {
+		return !day.Before(e.Start) && day.Before(e.End)
+	}
+	start := e.Start.In(time.Local)
+	end := e.End.In(time.Local)
+	firstDay := dateOf(start.Year(), int(start.Month()), start.Day())
+	if !end.After(start) {
+		return day.Equal(firstDay)
+	}
+	return !day.Before(firstDay) && day.Before(end)
+} // Covers
+
+// ----------------------------------------------- WriteICS
+
+// WriteICS -- writes a VCALENDAR of the events.  calName is shown by calendar apps that understand X-WR-CALNAME.  Events w/o a UID get one made from the date and summary,
+// so writing the same events again updates them instead of adding duplicates when the file is imported again.
+func WriteICS(w io.Writer, calName string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(icsDateTimeFormat) + "Z"
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+icsProdID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	if calName != "" {
+		writeLine(bw, "X-WR-CALNAME:"+icsEscaper.Replace(calName))
+	}
+	for _, e := range events {
+		uid := e.UID
+		if uid == "" {
+			uid = e.Start.Format(icsDateFormat) + "-" + strings.Trim(uidCleaner.ReplaceAllString(strings.ToLower(e.Summary), "-"), "-") + "@holidaycalc"
+		}
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+uid)
+		writeLine(bw, "DTSTAMP:"+stamp)
+		if e.AllDay {
+			end := e.End
+			if !end.After(e.Start) {
+				end = e.Start.AddDate(0, 0, 1)
+			}
+			writeLine(bw, "DTSTART;VALUE=DATE:"+e.Start.Format(icsDateFormat))
+			writeLine(bw, "DTEND;VALUE=DATE:"+end.Format(icsDateFormat))
+			writeLine(bw, "TRANSP:TRANSPARENT") // all day events shouldn't make me look busy.
+		} else {
+			writeLine(bw, "DTSTART:"+e.Start.UTC().Format(icsDateTimeFormat)+"Z")
+			if e.End.After(e.Start) {
+				writeLine(bw, "DTEND:"+e.End.UTC().Format(icsDateTimeFormat)+"Z")
+			}
+		}
+		writeLine(bw, "SUMMARY:"+icsEscaper.Replace(e.Summary))
+		if e.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+icsEscaper.Replace(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(bw, "LOCATION:"+icsEscaper.Replace(e.Location))
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+} // WriteICS
+
+// WriteICSFile -- WriteICS to a new file.
+func WriteICSFile(filename, calName string, events []Event) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = WriteICS(f, calName, events)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+} // WriteICSFile
+
+// writeLine -- folds lines longer than 75 octets w/o splitting a UTF-8 character, and ends them w/ CRLF as the RFC says.
+// Errors are sticky in a bufio.Writer, so Flush returns any of them.
+func writeLine(bw *bufio.Writer, line string) {
+	limit := icsMaxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 { // don't cut in the middle of a UTF-8 sequence
+			cut--
+		}
+		bw.WriteString(line[:cut])
+		bw.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsMaxLine - 1 // the leading space counts
+	}
+	bw.WriteString(line)
+	bw.WriteString("\r\n")
+}
+
+// ----------------------------------------------- ReadICS
+
+// ReadICS -- returns the VEVENTs in r.  Times w/ a Z are UTC, times w/ a TZID are in that zone if Go knows it, and the rest are local.
+// An event w/o DTEND or DURATION is one day long if it's all day, else it has End = Start.  An event w/o DTSTART is skipped, and the warnings say which ones.
+func ReadICS(r io.Reader) ([]Event, []string, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	events := make([]Event, 0, 50)
+	var warnings []string
+	var e *Event
+	var duration time.Duration
+	var durationDays int
+	var haveEnd, haveDuration bool
+	depth := 0 // VALARM and the like are inside a VEVENT, and their properties aren't the event's.
+
+	for lineNum, line := range lines {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue // the RFC says to ignore what you don't understand.
+		}
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") && e == nil {
+				e = &Event{}
+				haveEnd, haveDuration, duration, durationDays, depth = false, false, 0, 0, 0
+			} else if e != nil {
+				depth++
+			}
+			continue
+		case "END":
+			if e == nil {
+				continue
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if e.Start.IsZero() {
+				warnings = append(warnings, fmt.Sprintf("line %d: VEVENT %q has no DTSTART, so it's skipped", lineNum+1, e.Summary))
+				e = nil
+				continue
+			}
+			switch {
+			case haveEnd:
+			case haveDuration:
+				e.End = e.Start.AddDate(0, 0, durationDays).Add(duration)
+			case e.AllDay:
+				e.End = e.Start.AddDate(0, 0, 1)
+			default:
+				e.End = e.Start
+			}
+			events = append(events, *e)
+			e = nil
+			continue
+		}
+		if e == nil || depth > 0 {
+			continue
+		}
+
+		switch name {
+		case "SUMMARY":
+			e.Summary = icsUnescaper.Replace(value)
+		case "DESCRIPTION":
+			e.Description = icsUnescaper.Replace(value)
+		case "LOCATION":
+			e.Location = icsUnescaper.Replace(value)
+		case "UID":
+			e.UID = value
+		case "DTSTART", "DTEND":
+			t, allDay, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("line %d: %s %w", lineNum+1, name, err)
+			}
+			if name == "DTSTART" {
+				e.Start, e.AllDay = t, allDay
+			} else {
+				e.End, haveEnd = t, true
+			}
+		case "DURATION":
+			m := durationRegex.FindStringSubmatch(value)
+			if m == nil {
+				return nil, warnings, fmt.Errorf("line %d: DURATION %q is not a duration I understand", lineNum+1, value)
+			}
+			num := func(s string) int {
+				n, _ := strconv.Atoi(s)
+				return n
+			}
+			durationDays = 7*num(m[2]) + num(m[3])
+			duration = time.Duration(num(m[4]))*time.Hour + time.Duration(num(m[5]))*time.Minute + time.Duration(num(m[6]))*time.Second
+			if m[1] == "-" {
+				durationDays, duration = -durationDays, -duration
+			}
+			haveDuration = true
+		}
+	}
+	if e != nil {
+		return events, warnings, fmt.Errorf("VEVENT %q has no END", e.Summary)
+	}
+	return events, warnings, nil
+} // ReadICS
+
+// ReadICSFile -- ReadICS from a file.  The warnings start w/ the filename.
+func ReadICSFile(filename string) ([]Event, []string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	events, warnings, err := ReadICS(f)
+	for i := range warnings {
+		warnings[i] = filename + " " + warnings[i]
+	}
+	if err != nil {
+		return events, warnings, fmt.Errorf("%s %w", filename, err)
+	}
+	return events, warnings, nil
+} // ReadICSFile
+
+// unfoldLines -- joins the continuation lines, which start w/ a space or a tab, onto the line before.  Bare LF line endings are accepted too.
+func unfoldLines(r io.Reader) ([]string, error) {
+	lines := make([]string, 0, 100)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+} // unfoldLines
+
+// splitProperty -- splits NAME;PARAM=x;PARAM=y:VALUE.  A colon inside a quoted parameter value isn't the end of the parameters.
+func splitProperty(line string) (name string, params map[string]string, value string, ok bool) {
+	inQuote := false
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			inQuote = !inQuote
+		} else if line[i] == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	parts := strings.Split(line[:colon], ";")
+	params = make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+} // splitProperty
+
+// parseICSTime -- a DATE is all day, and a DATE-TIME isn't.  The value is a DATE-TIME unless the VALUE parameter says it's a DATE.
+func parseICSTime(value string, params map[string]string) (time.Time, bool, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") {
+		t, err := time.ParseInLocation(icsDateFormat, value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%q is not a date", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsDateTimeFormat, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%q is not a date-time", value)
+		}
+		return t, false, nil
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(icsDateTimeFormat, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is not a date-time", value)
+	}
+	return t, false, nil
+} // parseICSTime
+
+// END ics.go
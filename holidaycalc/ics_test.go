@@ -0,0 +1,75 @@
+package holidaycalc
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:no start",
+		"DTEND;VALUE=DATE:20261002",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:vacation",
+		"DTSTART;VALUE=DATE:20261005",
+		"DTEND;VALUE=DATE:20261010",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:meeting",
+		"DTSTART:20261007T150000Z",
+		"DURATION:PT1H",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	events, warnings, err := ReadICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("ReadICS error %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"no start"`) {
+		t.Errorf("warnings are %q, want 1 for the event w/o a DTSTART", warnings)
+	}
+	if len(events) != 2 {
+		t.Fatalf("read %d events, want 2: %+v", len(events), events)
+	}
+	vacation, meeting := events[0], events[1]
+	if !vacation.AllDay || !vacation.Start.Equal(time.Date(2026, time.October, 5, 0, 0, 0, 0, time.Local)) ||
+		!vacation.End.Equal(time.Date(2026, time.October, 10, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("vacation is %+v, want all day from Oct 5 to Oct 10", vacation)
+	}
+	if meeting.AllDay || !meeting.Start.Equal(time.Date(2026, time.October, 7, 15, 0, 0, 0, time.UTC)) || meeting.End.Sub(meeting.Start) != time.Hour {
+		t.Errorf("meeting is %+v, want 15:00 UTC on Oct 7 for an hour", meeting)
+	}
+}
+
+func TestParseICSTime(t *testing.T) {
+	tests := []struct {
+		value      string
+		params     map[string]string
+		want       time.Time
+		wantAllDay bool
+		wantErr    bool
+	}{
+		{value: "20261225", params: map[string]string{"VALUE": "DATE"}, want: time.Date(2026, time.December, 25, 0, 0, 0, 0, time.Local), wantAllDay: true},
+		{value: "20261225", params: map[string]string{"VALUE": "date"}, want: time.Date(2026, time.December, 25, 0, 0, 0, 0, time.Local), wantAllDay: true},
+		{value: "20261225", wantErr: true}, // w/o VALUE=DATE it's a DATE-TIME, so it needs the time.
+		{value: "20261225T093000Z", want: time.Date(2026, time.December, 25, 9, 30, 0, 0, time.UTC)},
+		{value: "20261225T093000", params: map[string]string{"VALUE": "DATE-TIME"}, want: time.Date(2026, time.December, 25, 9, 30, 0, 0, time.Local)},
+		{value: "20261225T093000Z", params: map[string]string{"VALUE": "DATE"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		got, allDay, err := parseICSTime(tc.value, tc.params)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseICSTime(%q, %v) = %v, want an error", tc.value, tc.params, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tc.want) || allDay != tc.wantAllDay {
+			t.Errorf("parseICSTime(%q, %v) = %v, %t, %v, want %v, %t", tc.value, tc.params, got, allDay, err, tc.want, tc.wantAllDay)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package holidaycalc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"src/timlibg"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Rule based holiday calendars.  HolType only has the ten holidays that move around, and only in the US.  A Calendar is a list of rules, and a rule is one of
+                 fixed date, nth weekday of a month, last weekday of a month, or days from Easter.  Fixed dates can be observed on the nearest weekday as US federal holidays are,
+                 or on the next free weekday as UK bank holidays are.  Easter is computed w/ the Gregorian computus, so there's no 1800 - 2100 limit here.
+                 US and UK calendars are built in.  More calendars, or replacements for the built in ones, are read from holidaycalc.txt in the home directory.
+                 The file has a [name] line to start each calendar, then one holiday per line as name | rule, like
+                   [US]
+                   Independence Day | fixed 7/4 observed
+                   Labor Day        | nth 1 Mon 9
+                   Memorial Day     | last Mon 5
+                   Election Day     | nth 1 Mon 11 +1
+                   Juneteenth       | fixed 6/19 observed from 2021
+                   Good Friday      | easter -2
+*/
+
+// RuleKind is how a Rule finds its date in a year.
+type RuleKind int
+
+const (
+	FixedDate    RuleKind = iota // Month/Day every year
+	NthWeekday                   // the Nth Weekday of Month, N is 1..5
+	LastWeekday                  // the last Weekday of Month
+	EasterOffset                 // Offset days from Easter Sunday
+)
+
+// Observance is what happens when a fixed date falls on a weekend.
+type Observance int
+
+const (
+	ObserveNone       Observance = iota // the day is the day
+	ObserveNearest                      // Sat is observed on Fri and Sun on Mon, as for US federal holidays
+	ObserveSubstitute                   // observed on the next weekday that isn't already a holiday, as for UK bank holidays
+)
+
+// Rule -- one holiday.  Offset is added after the date is found, so Election Day is the day after the 1st Monday of November.
+// FromYear and ToYear limit the years the holiday exists, and zero means no limit.
+type Rule struct {
+	Name             string
+	Kind             RuleKind
+	Month, Day, N    int
+	Weekday          time.Weekday
+	Offset           int
+	Observe          Observance
+	FromYear, ToYear int
+}
+
+// Holiday -- Date is the holiday itself and Observed is the day off, which is the same unless Date is on a weekend.
+type Holiday struct {
+	Name           string
+	Date, Observed time.Time
+}
+
+// Calendar -- the rules of one country, or one employer.
+type Calendar struct {
+	Name  string
+	Rules []Rule
+}
+
+const HolidayFilename = "holidaycalc.txt"
+
+const builtinCalendars = `
+[US]
+New Year's Day             | fixed 1/1 observed
+Martin Luther King Jr. Day | nth 3 Mon 1 from 1986
+Presidents' Day            | nth 3 Mon 2
+Memorial Day               | last Mon 5
+Juneteenth                 | fixed 6/19 observed from 2021
+Independence Day           | fixed 7/4 observed
+Labor Day                  | nth 1 Mon 9
+Columbus Day               | nth 2 Mon 10
+Veterans Day               | fixed 11/11 observed
+Thanksgiving Day           | nth 4 Thu 11
+Christmas Day              | fixed 12/25 observed
+
+[UK]
+New Year's Day             | fixed 1/1 substitute
+Good Friday                | easter -2
+Easter Monday              | easter 1
+Early May Bank Holiday     | nth 1 Mon 5 from 1978
+Spring Bank Holiday        | last Mon 5 from 1971
+Summer Bank Holiday        | last Mon 8 from 1971
+Christmas Day              | fixed 12/25 substitute
+Boxing Day                 | fixed 12/26 substitute
+`
+
+var weekdayNames = map[string]time.Weekday{"SUN": time.Sunday, "MON": time.Monday, "TUE": time.Tuesday, "WED": time.Wednesday,
+	"THU": time.Thursday, "FRI": time.Friday, "SAT": time.Saturday}
+
+var monthAbbrevs = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// ----------------------------------------------- EasterDate
+
+// EasterDate -- Western Easter Sunday by the anonymous Gregorian algorithm, which works for any Gregorian year.
+func EasterDate(y int) (m, d int) {
+	a := y % 19
+	b, c := y/100, y%100
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - b/4 - g + 15) % 30 // h is the days from Mar 21 to the paschal full moon, more or less
+	l := (32 + 2*(b%4) + 2*(c/4) - h - c%4) % 7
+	k := (a + 11*h + 22*l) / 451
+	m = (h + l - 7*k + 114) / 31
+	d = (h+l-7*k+114)%31 + 1
+	return m, d
+} // EasterDate
+
+// ----------------------------------------------- Rule.Date
+
+// Date -- returns the date of the rule in year y, before any weekend observance.  False if the holiday doesn't exist that year, as for a 5th Monday that isn't there.
+func (r Rule) Date(y int) (time.Time, bool) {
+	if (r.FromYear != 0 && y < r.FromYear) || (r.ToYear != 0 && y > r.ToYear) {
+		return time.Time{}, false
+	}
+	var t time.Time
+	switch r.Kind {
+	case FixedDate:
+		if r.Day > timlibg.DaysInMonth(r.Month, y) { // Feb 29
+			return time.Time{}, false
+		}
+		t = dateOf(y, r.Month, r.Day)
+	case NthWeekday:
+		first := dateOf(y, r.Month, 1)
+		t = first.AddDate(0, 0, (int(r.Weekday)-int(first.Weekday())+7)%7+7*(r.N-1))
+		if int(t.Month()) != r.Month {
+			return time.Time{}, false
+		}
+	case LastWeekday:
+		last := dateOf(y, r.Month, timlibg.DaysInMonth(r.Month, y))
+		t = last.AddDate(0, 0, -((int(last.Weekday()) - int(r.Weekday) + 7) % 7))
+	case EasterOffset:
+		m, d := EasterDate(y)
+		t = dateOf(y, m, d)
+	default:
+		return time.Time{}, false
+	}
+	return t.AddDate(0, 0, r.Offset), true
+} // Rule.Date
+
+// ----------------------------------------------- Calendar.Holidays
+
+// Holidays -- returns the holidays of year y in order of their observed dates.  A holiday is in the year of its Date, so when New Year's Day is on a Saturday,
+// its Observed date is Dec 31 of the year before.
+func (c *Calendar) Holidays(y int) []Holiday {
+	hols := make([]Holiday, 0, len(c.Rules))
+	taken := make(map[int]bool, len(c.Rules)) // keyed by dayKey
+	for _, r := range c.Rules {               // every holiday that isn't moving takes its day first, so a substitute doesn't land on one that comes later in the list.
+		if t, ok := r.Date(y); ok && !(r.Observe != ObserveNone && isWeekend(t)) {
+			taken[dayKey(t)] = true
+		}
+	}
+	for _, r := range c.Rules {
+		t, ok := r.Date(y)
+		if !ok {
+			continue
+		}
+		observed := t
+		if isWeekend(t) {
+			switch r.Observe {
+			case ObserveNearest:
+				if t.Weekday() == time.Saturday {
+					observed = t.AddDate(0, 0, -1)
+				} else {
+					observed = t.AddDate(0, 0, 1)
+				}
+			case ObserveSubstitute:
+				for isWeekend(observed) || taken[dayKey(observed)] {
+					observed = observed.AddDate(0, 0, 1)
+				}
+			}
+			taken[dayKey(observed)] = true
+		}
+		hols = append(hols, Holiday{Name: r.Name, Date: t, Observed: observed})
+	}
+	sort.SliceStable(hols, func(i, j int) bool { return hols[i].Observed.Before(hols[j].Observed) })
+	return hols
+} // Calendar.Holidays
+
+// ----------------------------------------------- IsHoliday, HolidaysInRange, NextHoliday
+
+// IsHoliday -- true if t's date is a holiday or the day a holiday is observed.
+func (c *Calendar) IsHoliday(t time.Time) (Holiday, bool) {
+	day := dateOf(t.Year(), int(t.Month()), t.Day())
+	for y := day.Year() - 1; y <= day.Year()+1; y++ { // observed dates can cross into the next or previous year.
+		for _, h := range c.Holidays(y) {
+			if h.Date.Equal(day) || h.Observed.Equal(day) {
+				return h, true
+			}
+		}
+	}
+	return Holiday{}, false
+} // IsHoliday
+
+// HolidaysInRange -- returns the holidays whose date or observed date is from start thru end, in order of their observed dates.
+func (c *Calendar) HolidaysInRange(start, end time.Time) []Holiday {
+	first := dateOf(start.Year(), int(start.Month()), start.Day())
+	last := dateOf(end.Year(), int(end.Month()), end.Day())
+	hols := make([]Holiday, 0, 20)
+	for y := first.Year() - 1; y <= last.Year()+1; y++ {
+		for _, h := range c.Holidays(y) {
+			if inRange(h.Date, first, last) || inRange(h.Observed, first, last) {
+				hols = append(hols, h)
+			}
+		}
+	}
+	return hols
+} // HolidaysInRange
+
+// NextHoliday -- returns the first holiday w/ a date after t's date.  False if there isn't one in the next 2 years, as can happen w/ rules that have a ToYear.
+func (c *Calendar) NextHoliday(t time.Time) (Holiday, bool) {
+	day := dateOf(t.Year(), int(t.Month()), t.Day())
+	for y := day.Year(); y <= day.Year()+2; y++ {
+		hols := c.Holidays(y)
+		sort.SliceStable(hols, func(i, j int) bool { return hols[i].Date.Before(hols[j].Date) })
+		for _, h := range hols {
+			if h.Date.After(day) {
+				return h, true
+			}
+		}
+	}
+	return Holiday{}, false
+} // NextHoliday
+
+// HolidaySet -- returns the observed dates of the years y1 thru y2 for timlibg.AddBusinessDays and BusinessDaysBetween.
+func (c *Calendar) HolidaySet(y1, y2 int) timlibg.HolidaySet {
+	hols := make(timlibg.HolidaySet)
+	for y := y1; y <= y2; y++ {
+		for _, h := range c.Holidays(y) {
+			hols.Add(h.Observed)
+		}
+	}
+	return hols
+} // Calendar.HolidaySet
+
+// ----------------------------------------------- ParseRule
+
+// ParseRule -- spec is one of
+//
+//	fixed M/D [observed | substitute]
+//	nth N Weekday Month [+-offset]
+//	last Weekday Month [+-offset]
+//	easter [+-offset]
+//
+// followed by from YYYY and to YYYY if the holiday only exists in some years.  Month is a number or a name, and Weekday is a name.
+func ParseRule(name, spec string) (Rule, error) {
+	r := Rule{Name: strings.TrimSpace(name)}
+	fields := strings.Fields(strings.ToUpper(spec))
+	if r.Name == "" {
+		return r, fmt.Errorf("holiday has no name")
+	}
+	if len(fields) == 0 {
+		return r, fmt.Errorf("%s has no rule", r.Name)
+	}
+
+	var rest []string
+	var err error
+	switch fields[0] {
+	case "FIXED":
+		r.Kind = FixedDate
+		if len(fields) < 2 {
+			return r, fmt.Errorf("%s: fixed needs M/D", r.Name)
+		}
+		md := strings.Split(fields[1], "/")
+		if len(md) != 2 {
+			return r, fmt.Errorf("%s: %q is not M/D", r.Name, fields[1])
+		}
+		if r.Month, err = parseMonth(md[0]); err != nil {
+			return r, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		r.Day, err = strconv.Atoi(md[1])
+		if err != nil || r.Day < 1 || r.Day > timlibg.DaysInMonth(r.Month, 2000) {
+			return r, fmt.Errorf("%s: %q is not a day of month %d", r.Name, md[1], r.Month)
+		}
+		rest = fields[2:]
+	case "NTH":
+		r.Kind = NthWeekday
+		if len(fields) < 4 {
+			return r, fmt.Errorf("%s: nth needs N Weekday Month", r.Name)
+		}
+		r.N, err = strconv.Atoi(fields[1])
+		if err != nil || r.N < 1 || r.N > 5 {
+			return r, fmt.Errorf("%s: N must be 1 thru 5, not %q", r.Name, fields[1])
+		}
+		if r.Weekday, err = parseWeekday(fields[2]); err != nil {
+			return r, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		if r.Month, err = parseMonth(fields[3]); err != nil {
+			return r, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		rest = fields[4:]
+	case "LAST":
+		r.Kind = LastWeekday
+		if len(fields) < 3 {
+			return r, fmt.Errorf("%s: last needs Weekday Month", r.Name)
+		}
+		if r.Weekday, err = parseWeekday(fields[1]); err != nil {
+			return r, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		if r.Month, err = parseMonth(fields[2]); err != nil {
+			return r, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		rest = fields[3:]
+	case "EASTER":
+		r.Kind = EasterOffset
+		rest = fields[1:]
+	default:
+		return r, fmt.Errorf("%s: rule %q is not fixed, nth, last or easter", r.Name, fields[0])
+	}
+
+	for i := 0; i < len(rest); i++ {
+		word := rest[i]
+		switch {
+		case word == "OBSERVED" && r.Kind == FixedDate:
+			r.Observe = ObserveNearest
+		case word == "SUBSTITUTE" && r.Kind == FixedDate:
+			r.Observe = ObserveSubstitute
+		case (word == "FROM" || word == "TO") && i+1 < len(rest):
+			i++
+			y, err := strconv.Atoi(rest[i])
+			if err != nil {
+				return r, fmt.Errorf("%s: %q is not a year", r.Name, rest[i])
+			}
+			if word == "FROM" {
+				r.FromYear = y
+			} else {
+				r.ToYear = y
+			}
+		case strings.HasPrefix(word, "+") || strings.HasPrefix(word, "-") || (r.Kind == EasterOffset && i == 0):
+			r.Offset, err = strconv.Atoi(word)
+			if err != nil {
+				return r, fmt.Errorf("%s: %q is not an offset in days", r.Name, word)
+			}
+		default:
+			return r, fmt.Errorf("%s: %q is not understood", r.Name, strings.ToLower(word))
+		}
+	}
+	return r, nil
+} // ParseRule
+
+// ----------------------------------------------- ReadCalendars, LoadCalendars, GetCalendar
+
+// ReadCalendars -- reads calendars in the holidaycalc.txt format.  # starts a comment.  The map is keyed by the upper case calendar name.
+func ReadCalendars(rdr io.Reader) (map[string]*Calendar, error) {
+	cals := make(map[string]*Calendar)
+	var cal *Calendar
+	scanner := bufio.NewScanner(rdr)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: calendar has no name", lineNum)
+			}
+			cal = &Calendar{Name: name}
+			cals[strings.ToUpper(name)] = cal
+			continue
+		}
+		if cal == nil {
+			return nil, fmt.Errorf("line %d: holiday before the first [calendar] line", lineNum)
+		}
+		name, spec, found := strings.Cut(line, "|")
+		if !found {
+			return nil, fmt.Errorf("line %d: needs name | rule", lineNum)
+		}
+		r, err := ParseRule(name, spec)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		cal.Rules = append(cal.Rules, r)
+	}
+	return cals, scanner.Err()
+} // ReadCalendars
+
+// LoadCalendars -- reads the calendars in filename.
+func LoadCalendars(filename string) (map[string]*Calendar, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cals, err := ReadCalendars(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", filename, err)
+	}
+	return cals, nil
+} // LoadCalendars
+
+// Calendars -- returns the built in calendars, w/ the ones in holidaycalc.txt in the home directory added or replacing a built in one of the same name.
+// The file is read fresh each time, so edits don't need a restart, and not having one is fine.
+func Calendars() (map[string]*Calendar, error) {
+	cals, err := ReadCalendars(strings.NewReader(builtinCalendars))
+	if err != nil {
+		return nil, err // can't happen unless I broke builtinCalendars.
+	}
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return cals, nil
+	}
+	userCals, err := LoadCalendars(filepath.Join(homedir, HolidayFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cals, nil
+		}
+		return cals, err
+	}
+	for name, cal := range userCals {
+		cals[name] = cal
+	}
+	return cals, nil
+} // Calendars
+
+// GetCalendar -- returns the calendar by name, ignoring case, from Calendars.  A bad holidaycalc.txt is an error even if the name is built in, so the problem gets noticed.
+func GetCalendar(name string) (*Calendar, error) {
+	cals, err := Calendars()
+	if err != nil {
+		return nil, err
+	}
+	cal, ok := cals[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("no holiday calendar named %s", name)
+	}
+	return cal, nil
+} // GetCalendar
+
+// ----------------------------------------------- helpers
+
+func dateOf(y, m, d int) time.Time {
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.Local)
+}
+
+// dayKey -- yyyymmdd as an int, which unlike a Julian date number works for any year.
+func dayKey(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+func inRange(t, first, last time.Time) bool {
+	return !t.Before(first) && !t.After(last)
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	if len(s) >= 3 {
+		if wd, ok := weekdayNames[s[:3]]; ok {
+			return wd, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a weekday", strings.ToLower(s))
+}
+
+func parseMonth(s string) (int, error) {
+	if m, err := strconv.Atoi(s); err == nil && m >= 1 && m <= 12 {
+		return m, nil
+	}
+	if len(s) >= 3 {
+		for i, abbrev := range monthAbbrevs {
+			if s[:3] == abbrev {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%q is not a month", strings.ToLower(s))
+}
+
+// END rules.go
@@ -0,0 +1,149 @@
+package holidaycalc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleDate(t *testing.T) {
+	tests := []struct {
+		spec string
+		year int
+		want string // "" if the holiday isn't there that year
+	}{
+		{"fixed 7/4", 2026, "Jul 4 2026"},
+		{"fixed Dec/25", 2026, "Dec 25 2026"},
+		{"fixed 2/29", 2024, "Feb 29 2024"},
+		{"fixed 2/29", 2025, ""},
+		{"nth 4 Thu 11", 2026, "Nov 26 2026"},  // Thanksgiving
+		{"nth 1 Mon 9", 2026, "Sep 7 2026"},    // Labor Day
+		{"nth 3 Mon Jan", 2026, "Jan 19 2026"}, // MLK Day
+		{"nth 1 Mon 11 +1", 2026, "Nov 3 2026"},
+		{"nth 1 Mon 11 +1", 2024, "Nov 5 2024"}, // Nov 1 2024 is a Friday
+		{"nth 1 Sun 3", 2026, "Mar 1 2026"},     // the 1st is the weekday itself
+		{"nth 5 Mon 2", 2026, ""},               // Feb 2026 has only 4 Mondays
+		{"nth 5 Thu 10", 2026, "Oct 29 2026"},
+		{"last Mon 5", 2026, "May 25 2026"}, // Memorial Day
+		{"last Mon 5", 2021, "May 31 2021"}, // the last day is the weekday itself
+		{"last Mon Aug", 2026, "Aug 31 2026"},
+		{"last Fri 2", 2024, "Feb 23 2024"},
+		{"last Thu 2 -3", 2026, "Feb 23 2026"},
+		{"easter", 2026, "Apr 5 2026"},
+		{"easter -2", 2026, "Apr 3 2026"}, // Good Friday
+		{"easter 1", 2026, "Apr 6 2026"},  // Easter Monday
+		{"easter +39", 2026, "May 14 2026"},
+		{"easter", 2025, "Apr 20 2025"},
+		{"easter", 2038, "Apr 25 2038"}, // as late as it can be
+		{"easter", 2285, "Mar 22 2285"}, // as early as it can be
+		{"easter", 1818, "Mar 22 1818"},
+		{"fixed 6/19 from 2021", 2020, ""},
+		{"fixed 6/19 from 2021", 2021, "Jun 19 2021"},
+		{"last Mon 5 to 1970", 1971, ""},
+		{"last Mon 5 to 1970", 1970, "May 25 1970"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRule("Test Day", tt.spec)
+		if err != nil {
+			t.Errorf("ParseRule(%q): %v", tt.spec, err)
+			continue
+		}
+		d, ok := r.Date(tt.year)
+		got := ""
+		if ok {
+			got = d.Format("Jan 2 2006")
+		}
+		if got != tt.want {
+			t.Errorf("%q in %d = %q, want %q", tt.spec, tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, spec := range []string{"", "fixed", "fixed 13/1", "fixed 2/30", "fixed 7-4", "nth 6 Mon 5", "nth 1 Xyz 5", "nth 1 Mon",
+		"last Mon", "last Mon 13", "easter two", "nth 1 Mon 5 observed", "fixed 7/4 from", "fixed 7/4 from 20x1", "weekly Mon"} {
+		if r, err := ParseRule("Test Day", spec); err == nil {
+			t.Errorf("ParseRule(%q) = %+v, want an error", spec, r)
+		}
+	}
+	if _, err := ParseRule(" ", "fixed 7/4"); err == nil {
+		t.Errorf("ParseRule w/ no name should be an error")
+	}
+}
+
+func TestObservance(t *testing.T) {
+	cals, err := ReadCalendars(strings.NewReader(builtinCalendars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cal, name  string
+		year       int
+		date, obsd string
+	}{
+		{"US", "Independence Day", 2026, "Jul 4 2026", "Jul 3 2026"}, // Sat is observed on Fri
+		{"US", "Independence Day", 2027, "Jul 4 2027", "Jul 5 2027"}, // Sun is observed on Mon
+		{"US", "Independence Day", 2025, "Jul 4 2025", "Jul 4 2025"}, // a weekday is itself
+		{"US", "New Year's Day", 2022, "Jan 1 2022", "Dec 31 2021"},  // observed in the year before
+		{"US", "Christmas Day", 2021, "Dec 25 2021", "Dec 24 2021"},
+		{"UK", "Christmas Day", 2021, "Dec 25 2021", "Dec 27 2021"}, // Sat goes to Mon
+		{"UK", "Boxing Day", 2021, "Dec 26 2021", "Dec 28 2021"},    // Sun goes to Tue, as Mon is taken by Christmas
+		{"UK", "Christmas Day", 2022, "Dec 25 2022", "Dec 27 2022"}, // Sun goes to Tue, as Boxing Day is on Mon
+		{"UK", "Boxing Day", 2022, "Dec 26 2022", "Dec 26 2022"},
+		{"UK", "New Year's Day", 2022, "Jan 1 2022", "Jan 3 2022"}, // substitutes go forward, not back
+		{"UK", "Good Friday", 2026, "Apr 3 2026", "Apr 3 2026"},
+	}
+	for _, tt := range tests {
+		var found bool
+		for _, h := range cals[tt.cal].Holidays(tt.year) {
+			if h.Name != tt.name {
+				continue
+			}
+			found = true
+			if got := h.Date.Format("Jan 2 2006"); got != tt.date {
+				t.Errorf("%s %s %d date = %s, want %s", tt.cal, tt.name, tt.year, got, tt.date)
+			}
+			if got := h.Observed.Format("Jan 2 2006"); got != tt.obsd {
+				t.Errorf("%s %s %d observed = %s, want %s", tt.cal, tt.name, tt.year, got, tt.obsd)
+			}
+		}
+		if !found {
+			t.Errorf("%s has no %s in %d", tt.cal, tt.name, tt.year)
+		}
+	}
+
+	// a fixed date w/o observed or substitute stays on the weekend.
+	cal := &Calendar{Name: "test"}
+	r, _ := ParseRule("Weekend Day", "fixed 7/4")
+	cal.Rules = append(cal.Rules, r)
+	if hols := cal.Holidays(2026); len(hols) != 1 || !hols[0].Observed.Equal(hols[0].Date) {
+		t.Errorf("fixed 7/4 in 2026 w/o observance = %+v, want it observed on Sat", hols)
+	}
+	if _, ok := cals["US"].IsHoliday(dateOf(2021, 12, 31)); !ok {
+		t.Errorf("Dec 31 2021 is New Year's Day observed, and should be a holiday")
+	}
+}
+
+// TestEASTER checks the golden number table against the computus.  The table is a week late in these years of the 1800's, which is why EASTER uses the computus before 1900.
+func TestEASTER(t *testing.T) {
+	tests := []struct {
+		year, m, d int
+	}{
+		{1805, 4, 14}, {1818, 3, 22}, {1822, 4, 7}, {1825, 4, 3}, {1842, 3, 27}, {1845, 3, 23}, {1849, 4, 8}, {1869, 3, 28}, {1873, 4, 13}, {1893, 4, 2},
+		{1900, 4, 15}, {1954, 4, 18}, {2000, 4, 23}, {2008, 3, 23}, {2019, 4, 21}, {2026, 4, 5}, {2100, 3, 28}, {1700, 4, 11}, {2200, 4, 6},
+	}
+	for _, tt := range tests {
+		if m, d := EASTER(tt.year); m != tt.m || d != tt.d {
+			t.Errorf("EASTER(%d) = %d/%d, want %d/%d", tt.year, m, d, tt.m, tt.d)
+		}
+		if m, d := EasterDate(tt.year); m != tt.m || d != tt.d {
+			t.Errorf("EasterDate(%d) = %d/%d, want %d/%d", tt.year, m, d, tt.m, tt.d)
+		}
+	}
+	for y := 1900; y <= 2100; y++ { // the table and the computus agree where the table is used.
+		m1, d1 := EASTER(y)
+		m2, d2 := EasterDate(y)
+		if m1 != m2 || d1 != d2 {
+			t.Errorf("EASTER(%d) = %d/%d, but EasterDate = %d/%d", y, m1, d1, m2, d2)
+		}
+	}
+}
@@ -0,0 +1,741 @@
+package hpcalc2
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"src/tknptr"
+	"strconv"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Arbitrary precision and exact rational modes.  BIGFLOAT backs the stack w/ math/big.Float at a precision set by BIGPREC, and RAT backs it w/ big.Rat for exact fractions.
+                 FLOAT goes back to the float64 stack.  The float64 Stack is kept as a mirror of the big stack, so the front ends that read Stack directly still work.
+                 Commands that don't have a big implementation here use the float64 code in Result, and only the registers they changed are converted back.
+                 This means that CLEAN, ADJ, NEXT and BEFORE aren't needed to patch over rounding noise while in one of these modes.
+  18 Oct 26 -- 0b and 0o literals pushed the digits as decimal, so 0b101 was 101.  They now use Isum from the tokenizer, as 0x literals do.
+  18 Oct 26 -- ROUND, INT, TRUNC, FRAC, MOD, PRIME, PRIMEFAC, HCF and GCD panic'd when X or Y was Inf in BIGFLOAT, as big.Float.Int returns nil for Inf.
+                 These now go to the float64 code.  0 to a negative power is an error in RAT, instead of 0.  And a user program is run before the
+                 3 character abbreviations are checked, as Result does, so a program named sqrtwo isn't SQR.
+*/
+
+const (
+	FloatMode = iota // the zero value is the float64 stack, as it's always been.
+	BigFloatMode
+	RationalMode
+	ComplexMode // in complexmode.go, and ProgrammerMode follows it in progmode.go
+)
+
+var CalcModeNames = []string{"FLOAT", "BIGFLOAT", "RAT", "COMPLEX", "PROG"}
+
+const defaultBigPrecDigits = 50
+const ratFixedDigits = 20        // digits after the decimal point in the decimal form of a fraction, when sigfig is -1.
+const bigFactorLimit = 1_000_000 // trial division limit for PRIMEFAC of numbers that don't fit in a uint.
+const maxBigExponent = 100_000   // larger integer exponents go to float64, as the exact answer could take forever.
+
+type bigStackType [StackSize]*big.Float
+type ratStackType [StackSize]*big.Rat
+
+var calcMode int
+var bigPrec = digitsToBits(defaultBigPrecDigits)
+var bigStack bigStackType
+var ratStack ratStackType
+var bigLastX *big.Float
+var ratLastX *big.Rat
+
+// bigIntCmds use big.Float.Int, which is nil for Inf, so bigResult leaves these to the float64 code when X or Y is Inf.
+var bigIntCmds = map[int]bool{215: true, 220: true, 230: true, 240: true, 250: true, 280: true, 285: true, 300: true, 310: true}
+
+// These are kept parallel to StackUndoMatrix, so the same index works for all three.  Values are never altered in place, so copying the arrays copies the state.
+var bigUndoMatrix []bigStackType
+var ratUndoMatrix []ratStackType
+
+// ----------------------------------------------------------- CalcMode ---------------------------------------------------
+
+// CalcMode -- returns FloatMode, BigFloatMode, RationalMode, ComplexMode or ProgrammerMode.
+func CalcMode() int {
+	return calcMode
+}
+
+// ----------------------------------------------------------- SetCalcMode ------------------------------------------------
+
+// SetCalcMode -- switches the backing of the stack.  The current values are carried over into the new mode.
+func SetCalcMode(mode int) {
+	if mode < FloatMode || mode > ProgrammerMode {
+		mode = FloatMode
+	}
+	calcMode = mode
+	bigStack = bigStackType{}
+	ratStack = ratStackType{}
+	cplxStack = cplxStackType{}
+	progStack = progStackType{}
+	bigLastX, ratLastX = nil, nil
+	cplxLastX = complex(LastX, 0)
+	progLastX = floatToProg(LastX)
+	bigFillFromFloat()
+	cplxFillFromFloat()
+	progFillFromFloat()
+}
+
+// ----------------------------------------------------------- SetBigPrec -------------------------------------------------
+
+// SetBigPrec -- sets the precision of BIGFLOAT mode in decimal digits.  Values already on the big stack are rounded to the new precision.
+func SetBigPrec(digits int) {
+	bigPrec = digitsToBits(digits)
+	for i := X; i < StackSize; i++ {
+		if bigStack[i] != nil {
+			bigStack[i] = newBig().Set(bigStack[i])
+		}
+	}
+	if bigLastX != nil {
+		bigLastX = newBig().Set(bigLastX)
+	}
+}
+
+// ----------------------------------------------------------- digitsToBits -----------------------------------------------
+
+// digitsToBits -- converts decimal digits of precision to the bits that big.Float wants, w/ a few guard bits.
+func digitsToBits(digits int) uint {
+	if digits < 1 {
+		digits = 1
+	}
+	return uint(math.Ceil(float64(digits)*math.Log2(10))) + 8
+}
+
+// ----------------------------------------------------------- floatToRat -------------------------------------------------
+
+// floatToRat -- uses the shortest decimal string for r, so 0.1 becomes 1/10 and not the exact binary fraction that SetFloat64 would give.
+func floatToRat(r float64) *big.Rat {
+	q, ok := new(big.Rat).SetString(strconv.FormatFloat(r, 'g', -1, 64))
+	if !ok { // Inf or NaN can't be a rational.
+		return new(big.Rat)
+	}
+	return q
+}
+
+func floatToBig(r float64) *big.Float {
+	f, _, err := big.ParseFloat(strconv.FormatFloat(r, 'g', -1, 64), 10, bigPrec, big.ToNearestEven)
+	if err != nil {
+		return new(big.Float).SetPrec(bigPrec)
+	}
+	return f
+}
+
+// ----------------------------------------------------------- bigFillFromFloat -------------------------------------------
+
+// bigFillFromFloat -- any register that is empty, or no longer agrees w/ the float64 stack, is set from the float64 stack.  Registers that still agree keep their full precision.
+func bigFillFromFloat() {
+	if bigLastX != nil {
+		if f, _ := bigLastX.Float64(); f != LastX {
+			bigLastX = nil
+		}
+	}
+	if ratLastX != nil {
+		if f, _ := ratLastX.Float64(); f != LastX {
+			ratLastX = nil
+		}
+	}
+	for i := X; i < StackSize; i++ {
+		switch calcMode {
+		case BigFloatMode:
+			if bigStack[i] == nil {
+				bigStack[i] = floatToBig(Stack[i])
+			} else if f, _ := bigStack[i].Float64(); f != Stack[i] {
+				bigStack[i] = floatToBig(Stack[i])
+			}
+		case RationalMode:
+			if ratStack[i] == nil {
+				ratStack[i] = floatToRat(Stack[i])
+			} else if f, _ := ratStack[i].Float64(); f != Stack[i] {
+				ratStack[i] = floatToRat(Stack[i])
+			}
+		}
+	}
+}
+
+// ----------------------------------------------------------- bigToFloat -------------------------------------------------
+
+// bigToFloat -- updates the float64 mirror of the big stack.
+func bigToFloat() {
+	for i := X; i < StackSize; i++ {
+		switch calcMode {
+		case BigFloatMode:
+			Stack[i], _ = bigStack[i].Float64()
+		case RationalMode:
+			Stack[i], _ = ratStack[i].Float64()
+		}
+	}
+	switch calcMode {
+	case BigFloatMode:
+		if bigLastX != nil {
+			LastX, _ = bigLastX.Float64()
+		}
+	case RationalMode:
+		if ratLastX != nil {
+			LastX, _ = ratLastX.Float64()
+		}
+	}
+}
+
+// ----------------------------------------------------------- big stack movement -----------------------------------------
+
+func bigStackUp() {
+	for S := T2; S >= X; S-- {
+		bigStack[S+1] = bigStack[S]
+		ratStack[S+1] = ratStack[S]
+	}
+}
+
+func bigStackDn() { // Does not affect X, same as STACKDN.
+	for S := Y; S < T1; S++ {
+		bigStack[S] = bigStack[S+1]
+		ratStack[S] = ratStack[S+1]
+	}
+}
+
+func bigSwapXY() {
+	bigStack[X], bigStack[Y] = bigStack[Y], bigStack[X]
+	ratStack[X], ratStack[Y] = ratStack[Y], ratStack[X]
+}
+
+func bigSaveLastX() {
+	bigLastX = bigStack[X]
+	ratLastX = ratStack[X]
+}
+
+// ----------------------------------------------------------- newBig -----------------------------------------------------
+
+func newBig() *big.Float {
+	return new(big.Float).SetPrec(bigPrec)
+}
+
+// ----------------------------------------------------------- bigRound ---------------------------------------------------
+
+// bigRound -- rounds register i to the nearest integer, away from zero for halves like Round does.
+func bigRound(i int) *big.Int {
+	switch calcMode {
+	case BigFloatMode:
+		half := big.NewFloat(0.5)
+		if bigStack[i].Sign() < 0 {
+			half.Neg(half)
+		}
+		z, _ := newBig().Add(bigStack[i], half).Int(nil) // Int truncates toward zero
+		return z
+	case RationalMode:
+		return ratRound(ratStack[i])
+	}
+	return big.NewInt(int64(Round(Stack[i])))
+}
+
+func ratRound(q *big.Rat) *big.Int {
+	num := new(big.Int).Abs(q.Num())
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, q.Denom())
+	z := num.Quo(num, new(big.Int).Mul(q.Denom(), big.NewInt(2)))
+	if q.Sign() < 0 {
+		z.Neg(z)
+	}
+	return z
+}
+
+// ratTrunc -- truncates toward zero, like math.Trunc.
+func ratTrunc(q *big.Rat) *big.Int {
+	return new(big.Int).Quo(q.Num(), q.Denom())
+}
+
+// ----------------------------------------------------------- bigPWRI ----------------------------------------------------
+
+// bigPWRI -- same algorithm as PWRI, for the big stack.
+func bigPWRI(y int, I int64) {
+	NEGFLAG := I < 0
+	if NEGFLAG {
+		I = -I
+	}
+	switch calcMode {
+	case BigFloatMode:
+		Z := newBig().SetInt64(1)
+		R := newBig().Set(bigStack[y])
+		for I > 0 {
+			if I%2 == 1 {
+				Z = newBig().Mul(Z, R)
+			}
+			R = newBig().Mul(R, R)
+			I /= 2
+		}
+		if NEGFLAG {
+			Z = newBig().Quo(newBig().SetInt64(1), Z)
+		}
+		bigStack[X] = Z
+	case RationalMode:
+		Z := new(big.Rat).SetInt64(1)
+		R := new(big.Rat).Set(ratStack[y])
+		for I > 0 {
+			if I%2 == 1 {
+				Z = new(big.Rat).Mul(Z, R)
+			}
+			R = new(big.Rat).Mul(R, R)
+			I /= 2
+		}
+		if NEGFLAG && Z.Sign() != 0 {
+			Z = new(big.Rat).Inv(Z)
+		}
+		ratStack[X] = Z
+	}
+}
+
+// ----------------------------------------------------------- bigIsZero --------------------------------------------------
+
+func bigIsZero(i int) bool {
+	if calcMode == RationalMode {
+		return ratStack[i].Sign() == 0
+	}
+	return bigStack[i].Sign() == 0
+}
+
+// ----------------------------------------------------------- bigArithOK -------------------------------------------------
+
+// bigArithOK -- returns true if bigArith can do this op code.  Division by zero is left to float64, because big.Float and big.Rat panic where float64 gives Inf.
+func bigArithOK(I int) bool {
+	switch I {
+	case 5, 8, 10, 12, 22:
+		return true
+	case 14:
+		return !bigIsZero(X)
+	}
+	return false
+}
+
+// ----------------------------------------------------------- bigArith ---------------------------------------------------
+
+// bigArith -- does Y op X into X, for the op codes of GETOPCODE that bigArithOK allows.
+func bigArith(I int) {
+	switch calcMode {
+	case BigFloatMode:
+		x, y := bigStack[X], bigStack[Y]
+		switch I {
+		case 5, 8:
+			bigStack[X] = newBig().Add(y, x)
+		case 10:
+			bigStack[X] = newBig().Sub(y, x)
+		case 12:
+			bigStack[X] = newBig().Mul(y, x)
+		case 14:
+			bigStack[X] = newBig().Quo(y, x)
+		case 22:
+			bigStack[X] = newBig().Quo(newBig().Mul(y, x), newBig().SetInt64(100))
+		}
+	case RationalMode:
+		x, y := ratStack[X], ratStack[Y]
+		switch I {
+		case 5, 8:
+			ratStack[X] = new(big.Rat).Add(y, x)
+		case 10:
+			ratStack[X] = new(big.Rat).Sub(y, x)
+		case 12:
+			ratStack[X] = new(big.Rat).Mul(y, x)
+		case 14:
+			ratStack[X] = new(big.Rat).Quo(y, x)
+		case 22:
+			ratStack[X] = new(big.Rat).Quo(new(big.Rat).Mul(y, x), big.NewRat(100, 1))
+		}
+	}
+}
+
+// ----------------------------------------------------------- bigPush ----------------------------------------------------
+
+// bigPush -- pushes a number token onto the big stack, parsed from its string so nothing is lost thru float64.
+func bigPush(tkn tknptr.TokenType) {
+	bigStackUp()
+	str := tkn.FullString
+	if tkn.HexFlag || tkn.BinFlag || tkn.OctFlag { // the tokenizer has already converted these, and FullString is only the digits after the prefix.
+		str = strconv.Itoa(tkn.Isum)
+	}
+	switch calcMode {
+	case BigFloatMode:
+		f, _, err := big.ParseFloat(str, 10, bigPrec, big.ToNearestEven)
+		if err != nil {
+			f = floatToBig(tkn.Rsum)
+		}
+		bigStack[X] = f
+	case RationalMode:
+		q, ok := new(big.Rat).SetString(str)
+		if !ok {
+			q = floatToRat(tkn.Rsum)
+		}
+		ratStack[X] = q
+	}
+}
+
+// ----------------------------------------------------------- bigPrimeFactors --------------------------------------------
+
+// bigPrimeFactors -- returns the prime factors as strings.  Numbers that fit in a uint use PrimeFactorMemoized, otherwise trial division up to bigFactorLimit.
+func bigPrimeFactors(n *big.Int) []string {
+	stringslice := make([]string, 0, 10)
+	if n.IsUint64() && n.Uint64() <= math.MaxUint {
+		for _, pf := range PrimeFactorMemoized(uint(n.Uint64())) {
+			stringslice = append(stringslice, fmt.Sprintf("%d", pf))
+		}
+		return stringslice
+	}
+	u := new(big.Int).Set(n)
+	d := big.NewInt(2)
+	r := new(big.Int)
+	q := new(big.Int)
+	for i := 0; i < bigFactorLimit && new(big.Int).Mul(d, d).Cmp(u) <= 0; i++ {
+		q.QuoRem(u, d, r)
+		if r.Sign() == 0 {
+			stringslice = append(stringslice, d.String())
+			u.Set(q)
+			continue
+		}
+		if d.Cmp(big.NewInt(2)) == 0 {
+			d.SetInt64(3)
+		} else {
+			d.Add(d, big.NewInt(2))
+		}
+	}
+	if u.Cmp(big.NewInt(1)) > 0 {
+		if u.ProbablyPrime(numOfFermatTests) {
+			stringslice = append(stringslice, u.String())
+		} else {
+			stringslice = append(stringslice, u.String()+" (not fully factored)")
+		}
+	}
+	return stringslice
+}
+
+// ----------------------------------------------------------- bigRegString -----------------------------------------------
+
+// bigRegString -- formats register i of the big stack w/ the 'f', 'e' or 'g' verb, w/o going thru float64.  A fraction is shown as a/b followed by its decimal value.
+func bigRegString(i int, format byte) string {
+	switch calcMode {
+	case BigFloatMode:
+		str := bigStack[i].Text(format, sigfig)
+		if format == 'f' {
+			str = CropNStr(str)
+			if bigStack[i].Cmp(big.NewFloat(10000)) > 0 {
+				str = AddCommas(str)
+			}
+		}
+		return str
+	case RationalMode:
+		q := ratStack[i]
+		digits := sigfig
+		if digits < 0 {
+			digits = ratFixedDigits
+		}
+		var str string
+		if format == 'f' {
+			str = strings.TrimSuffix(CropNStr(q.FloatString(digits)), ".")
+			if q.Cmp(big.NewRat(10000, 1)) > 0 {
+				str = AddCommas(str)
+			}
+		} else {
+			str = new(big.Float).SetPrec(bigPrec).SetRat(q).Text(format, sigfig)
+		}
+		if q.IsInt() {
+			return str
+		}
+		return q.RatString() + " = " + str
+	}
+	return strconv.FormatFloat(Stack[i], format, sigfig, 64)
+}
+
+// ----------------------------------------------------------- dumpBigStack -----------------------------------------------
+
+// dumpBigStack -- the Dump functions call this when the stack is backed by big numbers.
+func dumpBigStack(format byte) []string {
+	bigFillFromFloat() // an undo back to before the mode was set leaves empty registers.
+	ss := make([]string, 0, StackSize+3)
+	ss = append(ss, HeaderDivider)
+	for SRN := T1; SRN >= X; SRN-- {
+		ss = append(ss, fmt.Sprintf("%2s: %s", StackRegNamesString[SRN], bigRegString(SRN, format)))
+	}
+	ss = append(ss, HeaderDivider)
+	ss = append(ss, fmt.Sprintf(" mode is %s, precision is %d bits", CalcModeNames[calcMode], bigPrec))
+	return ss
+}
+
+// ----------------------------------------------------------- bigResult --------------------------------------------------
+
+// bigResult -- Result calls this first when in one of the big modes.  It returns false for tokens it doesn't handle, and those fall thru to the float64 code.
+func bigResult(tkn tknptr.TokenType) (bool, []string) {
+	ss := make([]string, 0, 10)
+	bigFillFromFloat() // in case a front end changed the float64 stack directly, as rpn2 does when it reads its stack file.
+
+	switch tkn.State {
+	case tknptr.DGT:
+		PushMatrixStacks()
+		bigPush(tkn)
+
+	case tknptr.OP:
+		I := tkn.Isum
+		if (I == 6) || (I == 20) || (I == 1) || (I == 3) { // <>, ><, <, > will all SWAP
+			bigSwapXY()
+			break
+		}
+		if calcMode == BigFloatMode && (bigStack[X].IsInf() || bigStack[Y].IsInf()) {
+			return false, nil // big.Float panics on things like Inf - Inf, so let float64 make the NaN.
+		}
+		if I == 16 || (I == 18 && bigIsIntegral(X)) { // ^ is PWRI, and ** w/ an integer exponent is the same thing.
+			n := bigRound(X)
+			if !n.IsInt64() || n.Int64() > maxBigExponent || n.Int64() < -maxBigExponent {
+				return false, nil
+			}
+			if calcMode == RationalMode && n.Sign() < 0 && bigIsZero(Y) {
+				ss = append(ss, " 0 to a negative power is undefined for a fraction.  Command ignored.")
+				return true, ss
+			}
+			PushMatrixStacks()
+			bigSaveLastX()
+			bigPWRI(Y, n.Int64())
+			bigStackDn()
+			break
+		}
+		if !bigArithOK(I) {
+			return false, nil
+		}
+		PushMatrixStacks()
+		bigSaveLastX()
+		bigArith(I)
+		if I != 22 { // Do not move stack for % operator
+			bigStackDn()
+		}
+
+	case tknptr.ALLELSE:
+		cmdnum := cmdMap[tkn.Str]
+		if cmdnum == 0 {
+			if _, ok := isMacro(tkn.Str); ok {
+				return false, nil // Result runs the program, and its steps come back here.
+			}
+		}
+		if cmdnum == 0 {
+			cmdnum = abbrevCmdNum(tkn.Str)
+		}
+		if calcMode == BigFloatMode && bigIntCmds[cmdnum] && (bigStack[X].IsInf() || bigStack[Y].IsInf()) {
+			return false, nil
+		}
+		switch cmdnum {
+		case 10: // DUMP
+			ss = append(ss, dumpBigStack('g')...)
+		case 20: // DUMPFIX
+			ss = append(ss, dumpBigStack('f')...)
+		case 30: // DUMPFLOAT
+			ss = append(ss, dumpBigStack('e')...)
+		case 80: // RECIP
+			if bigIsZero(X) {
+				return false, nil
+			}
+			PushMatrixStacks()
+			bigSaveLastX()
+			if calcMode == RationalMode {
+				ratStack[X] = new(big.Rat).Inv(ratStack[X])
+			} else {
+				bigStack[X] = newBig().Quo(newBig().SetInt64(1), bigStack[X])
+			}
+		case 160: // SWAP
+			PushMatrixStacks()
+			bigSwapXY()
+		case 170: // LASTX
+			if bigLastX == nil && ratLastX == nil {
+				return false, nil
+			}
+			PushMatrixStacks()
+			bigStackUp()
+			bigStack[X], ratStack[X] = bigLastX, ratLastX
+		case 180: // ROLLDN
+			PushMatrixStacks()
+			tempBig, tempRat := bigStack[X], ratStack[X]
+			bigStack[X], ratStack[X] = bigStack[Y], ratStack[Y]
+			bigStackDn()
+			bigStack[T1], ratStack[T1] = tempBig, tempRat
+		case 190: // UP
+			PushMatrixStacks()
+			bigStackUp()
+		case 200: // DN
+			PushMatrixStacks()
+			bigStack[X], ratStack[X] = bigStack[Y], ratStack[Y]
+			bigStackDn()
+		case 210: // POP
+			PushMatrixStacks()
+			ss = append(ss, bigRegString(X, 'g'))
+			bigStack[X], ratStack[X] = bigStack[Y], ratStack[Y]
+			bigStackDn()
+		case 215, 240: // INT, TRUNC.  INT is floor, TRUNC is toward zero.
+			PushMatrixStacks()
+			bigSaveLastX()
+			bigIntPart(cmdnum == 215)
+		case 250: // ROUND
+			PushMatrixStacks()
+			bigSaveLastX()
+			bigSetInt(X, bigRound(X))
+		case 300: // FRAC
+			PushMatrixStacks()
+			bigSaveLastX()
+			x := bigStack[X]
+			q := ratStack[X]
+			bigIntPart(false)
+			if calcMode == RationalMode {
+				ratStack[X] = new(big.Rat).Sub(q, ratStack[X])
+			} else {
+				bigStack[X] = newBig().Sub(x, bigStack[X])
+			}
+		case 310: // MOD
+			y, x := bigRound(Y), bigRound(X)
+			if x.Sign() == 0 {
+				return false, nil
+			}
+			PushMatrixStacks()
+			bigSaveLastX()
+			bigSetInt(X, new(big.Int).Rem(y, x)) // Rem has the sign of y, like math.Mod
+			bigStackDn()
+		case 370: // CHS
+			PushMatrixStacks()
+			bigSaveLastX()
+			if calcMode == RationalMode {
+				ratStack[X] = new(big.Rat).Neg(ratStack[X])
+			} else {
+				bigStack[X] = newBig().Neg(bigStack[X])
+			}
+		case 400: // SQR
+			PushMatrixStacks()
+			bigSaveLastX()
+			bigPWRI(X, 2)
+		case 410: // SQRT
+			if (calcMode == RationalMode && ratStack[X].Sign() < 0) || (calcMode == BigFloatMode && bigStack[X].Sign() < 0) {
+				return false, nil // let float64 make the NaN
+			}
+			PushMatrixStacks()
+			bigSaveLastX()
+			if calcMode == RationalMode {
+				ratStack[X] = ratSqrt(ratStack[X])
+			} else {
+				bigStack[X] = newBig().Sqrt(bigStack[X])
+			}
+		case 220, 230: // PRIME, PRIMEFAC
+			n := new(big.Int).Abs(bigRound(X))
+			if cmdnum == 220 {
+				if n.ProbablyPrime(numOfFermatTests) {
+					ss = append(ss, fmt.Sprintf("%s is prime.", n))
+					break
+				}
+				ss = append(ss, fmt.Sprintf("%s is NOT prime.", n))
+			}
+			if n.Cmp(big.NewInt(2)) < 0 {
+				ss = append(ss, "PrimeFactors cmd of numbers < 2 ignored.")
+				break
+			}
+			ss = append(ss, strings.Join(bigPrimeFactors(n), ", "))
+		case 280, 285: // HCF, GCD
+			c1 := new(big.Int).Abs(bigRound(X))
+			c2 := new(big.Int).Abs(bigRound(Y))
+			c := new(big.Int).GCD(nil, nil, c2, c1)
+			if cmdnum == 280 {
+				ss = append(ss, fmt.Sprintf("HCF of %s and %s is %s.", c1, c2, c))
+			} else {
+				PushMatrixStacks()
+				bigStackUp()
+				bigSetInt(X, c)
+				ss = append(ss, fmt.Sprintf("GCD of %s and %s is %s.", c1, c2, c))
+			}
+		case 360: // PI
+			if calcMode == RationalMode {
+				return false, nil
+			}
+			PushMatrixStacks()
+			bigStackUp()
+			bigStack[X] = bigPi()
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	bigToFloat()
+	return true, ss
+}
+
+// ----------------------------------------------------------- bigIsIntegral ----------------------------------------------
+
+func bigIsIntegral(i int) bool {
+	if calcMode == RationalMode {
+		return ratStack[i].IsInt()
+	}
+	return bigStack[i].IsInt()
+}
+
+// ----------------------------------------------------------- bigSetInt --------------------------------------------------
+
+func bigSetInt(i int, n *big.Int) {
+	if calcMode == RationalMode {
+		ratStack[i] = new(big.Rat).SetInt(n)
+	} else {
+		bigStack[i] = newBig().SetInt(n)
+	}
+}
+
+// ----------------------------------------------------------- bigIntPart -------------------------------------------------
+
+// bigIntPart -- replaces X w/ its integer part.  Floor when floorFlag is true, else truncate toward zero.
+func bigIntPart(floorFlag bool) {
+	var n *big.Int
+	var neg, isInt bool
+	if calcMode == RationalMode {
+		n = ratTrunc(ratStack[X])
+		neg, isInt = ratStack[X].Sign() < 0, ratStack[X].IsInt()
+	} else {
+		n, _ = bigStack[X].Int(nil)
+		neg, isInt = bigStack[X].Sign() < 0, bigStack[X].IsInt()
+	}
+	if floorFlag && neg && !isInt {
+		n.Sub(n, big.NewInt(1))
+	}
+	bigSetInt(X, n)
+}
+
+// ----------------------------------------------------------- ratSqrt ----------------------------------------------------
+
+// ratSqrt -- exact if numerator and denominator are both perfect squares, otherwise it's as good as big.Float at bigPrec.
+func ratSqrt(q *big.Rat) *big.Rat {
+	n := new(big.Int).Sqrt(q.Num())
+	d := new(big.Int).Sqrt(q.Denom())
+	if new(big.Int).Mul(n, n).Cmp(q.Num()) == 0 && new(big.Int).Mul(d, d).Cmp(q.Denom()) == 0 {
+		return new(big.Rat).SetFrac(n, d)
+	}
+	f := newBig().Sqrt(newBig().SetRat(q))
+	r, _ := f.Rat(nil)
+	return r
+}
+
+// ----------------------------------------------------------- bigPi ------------------------------------------------------
+
+// bigPi -- computes pi to the current precision using Machin's formula, pi = 16*atan(1/5) - 4*atan(1/239).
+func bigPi() *big.Float {
+	atanInv := func(n int64) *big.Float {
+		prec := bigPrec + 32
+		x := new(big.Float).SetPrec(prec).Quo(new(big.Float).SetPrec(prec).SetInt64(1), new(big.Float).SetPrec(prec).SetInt64(n))
+		x2 := new(big.Float).SetPrec(prec).Mul(x, x)
+		sum := new(big.Float).SetPrec(prec).Set(x)
+		term := new(big.Float).SetPrec(prec).Set(x)
+		eps := new(big.Float).SetPrec(prec).SetMantExp(big.NewFloat(1), -int(prec))
+		for k := int64(1); ; k++ {
+			term.Mul(term, x2)
+			t := new(big.Float).SetPrec(prec).Quo(term, new(big.Float).SetPrec(prec).SetInt64(2*k+1))
+			if k%2 == 1 {
+				sum.Sub(sum, t)
+			} else {
+				sum.Add(sum, t)
+			}
+			if t.Cmp(eps) < 0 {
+				break
+			}
+		}
+		return sum
+	}
+	pi := newBig().Mul(newBig().SetInt64(16), atanInv(5))
+	pi.Sub(pi, newBig().Mul(newBig().SetInt64(4), atanInv(239)))
+	return pi
+}
@@ -0,0 +1,99 @@
+package hpcalc2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestBigPushPrefixed -- 0x, 0b and 0o literals in the big modes are the value in their base, not their digits in decimal.
+func TestBigPushPrefixed(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	defer SetCalcMode(FloatMode)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"0x1f", "31"},
+		{"0b101", "5"},
+		{"0o17", "15"},
+		{"12.5", "12.5"},
+	}
+	for _, mode := range []int{BigFloatMode, RationalMode} {
+		for _, tc := range tests {
+			SetCalcMode(mode)
+			GetResult(tc.input)
+			var got string
+			if mode == BigFloatMode {
+				got = bigStack[X].Text('g', 10)
+			} else {
+				got = ratStack[X].RatString()
+				if tc.want == "12.5" {
+					tc.want = "25/2"
+				}
+			}
+			if got != tc.want {
+				t.Errorf("%s in %s: X = %s, want %s", tc.input, CalcModeNames[mode], got, tc.want)
+			}
+		}
+	}
+}
+
+// TestBigInf -- the commands that need an integer part go to float64 when X or Y is Inf, instead of panicking.
+func TestBigInf(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	defer SetCalcMode(FloatMode)
+
+	for _, cmd := range []string{"round", "int", "trunc", "frac", "mod", "prime", "hcf", "gcd"} {
+		SetCalcMode(BigFloatMode)
+		GetResult("1 0 /")
+		GetResult("12 ~") // Inf in Y and 12 in X, then swapped so Inf is in X.
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s of Inf in BIGFLOAT panic'd: %v", cmd, r)
+				}
+			}()
+			GetResult(cmd)
+		}()
+	}
+}
+
+// TestBigPWRIZero -- 0 to a negative power is an error in RAT, and the stack isn't changed.
+func TestBigPWRIZero(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	defer SetCalcMode(FloatMode)
+
+	SetCalcMode(RationalMode)
+	GetResult("0 -2")
+	_, ss := GetResult("^")
+	if len(ss) == 0 {
+		t.Errorf("0 ^ -2 in RAT gave no error")
+	}
+	if ratStack[X].RatString() != "-2" || ratStack[Y].Sign() != 0 {
+		t.Errorf("0 ^ -2 in RAT changed the stack to X = %s, Y = %s", ratStack[X].RatString(), ratStack[Y].RatString())
+	}
+	GetResult("2 -2 ^")
+	if got := ratStack[X].RatString(); got != "1/4" {
+		t.Errorf("2 ^ -2 in RAT = %s, want 1/4", got)
+	}
+}
+
+// TestBigMacro -- a user program is run before the 3 character abbreviations, so sqrtwo isn't SQR.
+func TestBigMacro(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	defer SetCalcMode(FloatMode)
+
+	GetResult(`define sqrtwo "2 sqrt"`)
+	for _, mode := range []int{BigFloatMode, RationalMode} {
+		SetCalcMode(mode)
+		r, ss := GetResult("9 sqrtwo")
+		if r < 1.414213 || r > 1.414214 || Stack[Y] != 9 {
+			t.Errorf("9 sqrtwo in %s: X = %g and Y = %g, want sqrt 2 and 9 %q", CalcModeNames[mode], r, Stack[Y], ss)
+		}
+	}
+}
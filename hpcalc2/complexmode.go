@@ -0,0 +1,301 @@
+package hpcalc2
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"src/tknptr"
+	"strconv"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Complex mode, where each stack register holds a complex128.  A number followed by i or j is imaginary, so 3 4i + is 3+4i.  And CPLX pairs Y and X as Y + Xi.
+                 R>P and P>R convert X between rectangular and polar, w/ the angle in degrees like the trig functions.  The float64 Stack mirrors the real parts.
+                 Like the big modes, what isn't done here falls thru to the float64 code, and the registers it changed come back w/ a zero imaginary part.
+  18 Oct 26 -- The float64 code only sees the real parts, so 2i SIN gave 0+2i, and a stack lift by PI or RCL didn't move the imaginary parts.  Now an op that
+                 isn't done here is an error if any register has an imaginary part, unless it leaves the stack alone, like HELP or UNDO.
+  18 Oct 26 -- A user program is run before the 3 character abbreviations are checked, as Result does, so 9 sqrtwo isn't 9 SQR.
+*/
+
+type cplxStackType [StackSize]complex128
+
+var cplxStack cplxStackType
+var cplxLastX complex128
+var cplxUndoMatrix []cplxStackType // parallel to StackUndoMatrix, like the big stacks.
+
+// cplxStackSafe -- the commands the float64 code does that don't change the stack, so they're fine when it has imaginary parts.
+var cplxStackSafe = map[int]bool{
+	0:   true, // not a command.  Macros come back thru GetResult, so each of their ops is checked.
+	70:  true, // SIGFIG
+	120: true, // HELP
+	140: true, // UNDO, which restores the complex stack too.
+	150: true, // REDO
+	290: true, // P, X and Q are ignored.
+	390: true, // ABOUT
+	690: true, // MACROS
+	700: true, // BIGFLOAT
+	710: true, // RAT
+	720: true, // FLOAT
+	740: true, // MODE
+	770: true, // COMPLEX
+	820: true, // UNITS
+	850: true, // CLSUM
+	910: true, // SUMSHO
+	940: true, // TVMBEG
+	942: true, // TVMEND
+	944: true, // TVMSHO
+	946: true, // CLTVM
+	999: true,
+}
+
+// ----------------------------------------------------------- cplxFillFromFloat ------------------------------------------
+
+// cplxFillFromFloat -- any register whose real part no longer agrees w/ the float64 stack is set from it, w/ a zero imaginary part.
+func cplxFillFromFloat() {
+	if calcMode != ComplexMode {
+		return
+	}
+	for i := X; i < StackSize; i++ {
+		if real(cplxStack[i]) != Stack[i] && !(math.IsNaN(Stack[i]) && math.IsNaN(real(cplxStack[i]))) {
+			cplxStack[i] = complex(Stack[i], 0)
+		}
+	}
+	if real(cplxLastX) != LastX {
+		cplxLastX = complex(LastX, 0)
+	}
+}
+
+// ----------------------------------------------------------- cplxToFloat ------------------------------------------------
+
+// cplxToFloat -- updates the float64 mirror, which holds the real parts.
+func cplxToFloat() {
+	for i := X; i < StackSize; i++ {
+		Stack[i] = real(cplxStack[i])
+	}
+	LastX = real(cplxLastX)
+}
+
+// ----------------------------------------------------------- complex stack movement -------------------------------------
+
+func cplxStackUp() {
+	for S := T2; S >= X; S-- {
+		cplxStack[S+1] = cplxStack[S]
+	}
+}
+
+func cplxStackDn() { // Does not affect X, same as STACKDN.
+	for S := Y; S < T1; S++ {
+		cplxStack[S] = cplxStack[S+1]
+	}
+}
+
+// ----------------------------------------------------------- degree conversion ------------------------------------------
+
+// toPolar -- returns r + θi, w/ θ in degrees.
+func toPolar(z complex128) complex128 {
+	r, theta := cmplx.Polar(z)
+	return complex(r, theta*180.0/PI)
+}
+
+// fromPolar -- input is r + θi, w/ θ in degrees.
+func fromPolar(z complex128) complex128 {
+	return cmplx.Rect(real(z), imag(z)*PI/180.0)
+}
+
+// ----------------------------------------------------------- ComplexString ----------------------------------------------
+
+// ComplexString -- formats a complex number as a + bi, using the 'f', 'e' or 'g' verb and the current sigfig.
+func ComplexString(z complex128, format byte) string {
+	re := strconv.FormatFloat(real(z), format, sigfig, 64)
+	im := strconv.FormatFloat(math.Abs(imag(z)), format, sigfig, 64)
+	if format == 'f' {
+		re = strings.TrimSuffix(CropNStr(re), ".")
+		im = strings.TrimSuffix(CropNStr(im), ".")
+	}
+	if imag(z) == 0 {
+		return re
+	}
+	sign := " + "
+	if math.Signbit(imag(z)) {
+		sign = " - "
+	}
+	return re + sign + im + "i"
+}
+
+// ----------------------------------------------------------- dumpComplexStack -------------------------------------------
+
+// dumpComplexStack -- the Dump functions call this when in complex mode.  Each register shows real and imaginary parts, and the polar form.
+func dumpComplexStack(format byte) []string {
+	ss := make([]string, 0, StackSize+3)
+	ss = append(ss, HeaderDivider)
+	for SRN := T1; SRN >= X; SRN-- {
+		z := cplxStack[SRN]
+		if imag(z) == 0 {
+			ss = append(ss, fmt.Sprintf("%2s: %s", StackRegNamesString[SRN], ComplexString(z, format)))
+			continue
+		}
+		p := toPolar(z)
+		ss = append(ss, fmt.Sprintf("%2s: %s %s %s at %s deg", StackRegNamesString[SRN], ComplexString(z, format), SpaceFiller,
+			strconv.FormatFloat(real(p), 'g', sigfig, 64), strconv.FormatFloat(imag(p), 'g', sigfig, 64)))
+	}
+	ss = append(ss, HeaderDivider)
+	ss = append(ss, " mode is COMPLEX")
+	return ss
+}
+
+// ----------------------------------------------------------- cplxHasImag -----------------------------------------------
+
+// cplxHasImag -- true if any register of the complex stack has an imaginary part.
+func cplxHasImag() bool {
+	for _, z := range cplxStack {
+		if imag(z) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------- cplxNotDone -----------------------------------------------
+
+// cplxNotDone -- complexResult calls this for a token it doesn't do.  It's handled, as an error, if the float64 code would change a stack that has imaginary parts.
+func cplxNotDone(tkn tknptr.TokenType, cmdnum int) (bool, []string) {
+	if !cplxHasImag() || (tkn.State == tknptr.ALLELSE && cplxStackSafe[cmdnum]) {
+		return false, nil
+	}
+	return true, []string{fmt.Sprintf(" %s isn't done for complex numbers, and the stack has imaginary parts.  Command ignored.", tkn.Str)}
+}
+
+// ----------------------------------------------------------- complexResult ----------------------------------------------
+
+// complexResult -- Result calls this first when in complex mode.  It returns false for tokens it doesn't handle, and those fall thru to the float64 code.
+func complexResult(tkn tknptr.TokenType) (bool, []string) {
+	ss := make([]string, 0, 10)
+	cplxFillFromFloat() // in case a front end changed the float64 stack directly.
+
+	switch tkn.State {
+	case tknptr.DGT:
+		PushMatrixStacks()
+		cplxStackUp()
+		cplxStack[X] = complex(tkn.Rsum, 0)
+
+	case tknptr.OP:
+		I := tkn.Isum
+		if (I == 6) || (I == 20) || (I == 1) || (I == 3) { // <>, ><, <, > will all SWAP
+			cplxStack[X], cplxStack[Y] = cplxStack[Y], cplxStack[X]
+			break
+		}
+		x, y := cplxStack[X], cplxStack[Y]
+		var z complex128
+		switch I {
+		case 5, 8:
+			z = y + x
+		case 10:
+			z = y - x
+		case 12:
+			z = y * x
+		case 14:
+			z = y / x
+		case 16:
+			z = cmplx.Pow(y, complex(Round(real(x)), 0)) // ^ uses an integer exponent, as PWRI does.
+		case 18:
+			z = cmplx.Pow(y, x)
+		case 22:
+			z = y * x / 100
+		default:
+			return cplxNotDone(tkn, 0)
+		}
+		PushMatrixStacks()
+		cplxLastX = x
+		cplxStack[X] = z
+		if I != 22 { // Do not move stack for % operator
+			cplxStackDn()
+		}
+
+	case tknptr.ALLELSE:
+		cmdnum := cmdMap[tkn.Str]
+		if cmdnum == 0 {
+			if _, ok := isMacro(tkn.Str); ok {
+				return false, nil // Result runs the program, and its steps come back here.
+			}
+		}
+		if cmdnum == 0 {
+			cmdnum = abbrevCmdNum(tkn.Str)
+		}
+		unary := func(f func(complex128) complex128) {
+			PushMatrixStacks()
+			cplxLastX = cplxStack[X]
+			cplxStack[X] = f(cplxStack[X])
+		}
+		switch cmdnum {
+		case 10: // DUMP
+			ss = append(ss, dumpComplexStack('g')...)
+		case 20: // DUMPFIX
+			ss = append(ss, dumpComplexStack('f')...)
+		case 30: // DUMPFLOAT
+			ss = append(ss, dumpComplexStack('e')...)
+		case 80: // RECIP
+			unary(func(z complex128) complex128 { return 1 / z })
+		case 160: // SWAP
+			PushMatrixStacks()
+			cplxStack[X], cplxStack[Y] = cplxStack[Y], cplxStack[X]
+		case 170: // LASTX
+			PushMatrixStacks()
+			cplxStackUp()
+			cplxStack[X] = cplxLastX
+		case 180: // ROLLDN
+			PushMatrixStacks()
+			temp := cplxStack[X]
+			cplxStack[X] = cplxStack[Y]
+			cplxStackDn()
+			cplxStack[T1] = temp
+		case 190: // UP
+			PushMatrixStacks()
+			cplxStackUp()
+		case 200: // DN
+			PushMatrixStacks()
+			cplxStack[X] = cplxStack[Y]
+			cplxStackDn()
+		case 210: // POP
+			PushMatrixStacks()
+			ss = append(ss, ComplexString(cplxStack[X], 'g'))
+			cplxStack[X] = cplxStack[Y]
+			cplxStackDn()
+		case 370: // CHS
+			unary(func(z complex128) complex128 { return -z })
+		case 400: // SQR
+			unary(func(z complex128) complex128 { return z * z })
+		case 410: // SQRT
+			unary(cmplx.Sqrt)
+		case 420: // EXP
+			unary(cmplx.Exp)
+		case 430: // LN or LOG
+			unary(cmplx.Log)
+		case 750: // I or J, makes X imaginary
+			unary(func(z complex128) complex128 { return z * 1i })
+		case 760: // CPLX
+			PushMatrixStacks()
+			cplxLastX = cplxStack[X]
+			cplxStack[X] = complex(real(cplxStack[Y]), real(cplxStack[X]))
+			cplxStackDn()
+		case 780: // RE
+			unary(func(z complex128) complex128 { return complex(real(z), 0) })
+		case 785: // IM
+			unary(func(z complex128) complex128 { return complex(imag(z), 0) })
+		case 790: // CONJ
+			unary(cmplx.Conj)
+		case 800: // R2P or R>P
+			unary(toPolar)
+		case 810: // P2R or P>R
+			unary(fromPolar)
+		default:
+			return cplxNotDone(tkn, cmdnum)
+		}
+	default:
+		return false, nil
+	}
+	cplxToFloat()
+	return true, ss
+}
@@ -0,0 +1,43 @@
+package hpcalc2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestComplexNotDone -- an op the complex code doesn't do used to work on the real parts only, so 2i SIN gave 0+2i.
+func TestComplexNotDone(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	SetCalcMode(ComplexMode)
+	defer SetCalcMode(FloatMode)
+
+	GetResult("2i")
+	_, ss := GetResult("sin")
+	if len(ss) == 0 || cplxStack[X] != 2i {
+		t.Errorf("2i sin: X = %v, msg %q, want 0+2i left alone and an error", cplxStack[X], ss)
+	}
+	GetResult("re 30 sin")
+	if got := cplxStack[X]; real(got) < 0.4999 || real(got) > 0.5001 || imag(got) != 0 {
+		t.Errorf("30 sin after re: X = %v, want 0.5", got)
+	}
+	GetResult("3 4i + sqr")
+	if got := cplxStack[X]; got != -7+24i {
+		t.Errorf("3+4i sqr: X = %v, want -7+24i", got)
+	}
+}
+
+// TestComplexMacro -- a user program is run before the 3 character abbreviations, so sqrtwo isn't SQR, and its steps keep the imaginary parts.
+func TestComplexMacro(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	SetCalcMode(ComplexMode)
+	defer SetCalcMode(FloatMode)
+
+	GetResult(`define sqrtwo "2 sqrt"`)
+	GetResult("3 4i +")
+	GetResult("sqrtwo")
+	if got := cplxStack[X]; real(got) < 1.414213 || real(got) > 1.414214 || imag(got) != 0 || cplxStack[Y] != 3+4i {
+		t.Errorf("3+4i sqrtwo: X = %v and Y = %v, want sqrt 2 and 3+4i", got, cplxStack[Y])
+	}
+}
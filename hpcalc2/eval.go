@@ -0,0 +1,417 @@
+package hpcalc2
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Infix expressions, for those who don't think in RPN.  EVAL (3+4)*sqrt(2)/x evaluates the rest of the line and pushes the result.
+                 Precedence is the usual: ^ or ** highest and right associative, then unary minus, then * and /, then + and -.  So -2^2 is -4.
+                 Names are the stack registers X, Y, Z, T5 .. T1, then LASTX, PI and E, then the mapped registers.  Functions take one argument in parens, and trig is in degrees.
+                 The expression is first translated to RPN, which is shown so the user can learn the RPN way, and then the RPN is evaluated.
+                 I didn't use tknptr here because it makes ( and ) part of an ALLELSE token, and it doesn't split 3+4 the way an infix parser needs.
+  18 Oct 26 -- The result was only pushed onto the float64 stack, so in COMPLEX mode the imaginary parts didn't move up, and in the big modes the whole stack
+                 was set again from float64 and lost its precision.  Now it's pushed onto the stack of the current mode too.  And a register w/ an imaginary part
+                 is an error, as eval only does real numbers.
+*/
+
+const (
+	evalNum = iota
+	evalName
+	evalOp
+	evalFunc
+	evalLParen
+	evalRParen
+	evalEnd
+)
+
+type evalTokenType struct {
+	kind int
+	str  string // as entered, so mapped register names keep their case
+	num  float64
+	pos  int // byte offset in the expression, for error messages
+}
+
+type evalFuncType struct {
+	rpn string // the hpcalc2 command that does the same thing
+	f   func(float64) float64
+}
+
+var evalFuncs = map[string]evalFuncType{
+	"SQRT":   {"SQRT", math.Sqrt},
+	"SQR":    {"SQR", func(x float64) float64 { return x * x }},
+	"EXP":    {"EXP", math.Exp},
+	"LN":     {"LN", math.Log},
+	"LOG":    {"LN", math.Log}, // LOG is natural log here, same as the LOG command.
+	"SIN":    {"SIN", func(x float64) float64 { return math.Sin(x * PI / 180) }},
+	"COS":    {"COS", func(x float64) float64 { return math.Cos(x * PI / 180) }},
+	"TAN":    {"TAN", func(x float64) float64 { return math.Tan(x * PI / 180) }},
+	"ASIN":   {"ARCSIN", func(x float64) float64 { return math.Asin(x) * 180 / PI }},
+	"ARCSIN": {"ARCSIN", func(x float64) float64 { return math.Asin(x) * 180 / PI }},
+	"ACOS":   {"ARCCOS", func(x float64) float64 { return math.Acos(x) * 180 / PI }},
+	"ARCCOS": {"ARCCOS", func(x float64) float64 { return math.Acos(x) * 180 / PI }},
+	"ATAN":   {"ARCTAN", func(x float64) float64 { return math.Atan(x) * 180 / PI }},
+	"ARCTAN": {"ARCTAN", func(x float64) float64 { return math.Atan(x) * 180 / PI }},
+	"INT":    {"INT", math.Floor},
+	"FRAC":   {"FRAC", func(x float64) float64 { return x - math.Trunc(x) }},
+	"CEIL":   {"CEIL", math.Ceil},
+}
+
+// ----------------------------------------------------------- evalLex ----------------------------------------------------
+
+// evalLex -- splits an infix expression into tokens.  Numbers can be 1.5, .5, 1e-3 or 0x1F.
+func evalLex(expr string) ([]evalTokenType, error) {
+	tokens := make([]evalTokenType, 0, len(expr)/2+1)
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, evalTokenType{kind: evalLParen, str: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, evalTokenType{kind: evalRParen, str: ")", pos: i})
+			i++
+		case c == '*' && i+1 < len(expr) && expr[i+1] == '*':
+			tokens = append(tokens, evalTokenType{kind: evalOp, str: "^", pos: i})
+			i += 2
+		case strings.IndexByte("+-*/^", c) >= 0:
+			tokens = append(tokens, evalTokenType{kind: evalOp, str: string(c), pos: i})
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			var r float64
+			if c == '0' && i+1 < len(expr) && (expr[i+1] == 'x' || expr[i+1] == 'X') {
+				i += 2
+				for i < len(expr) && strings.IndexByte("0123456789abcdefABCDEF", expr[i]) >= 0 {
+					i++
+				}
+				u, err := strconv.ParseUint(expr[start+2:i], 16, 64)
+				if err != nil {
+					return nil, fmt.Errorf("bad hex number %q at position %d", expr[start:i], start+1)
+				}
+				r = float64(u)
+			} else {
+				for i < len(expr) && (expr[i] >= '0' && expr[i] <= '9' || expr[i] == '.') {
+					i++
+				}
+				if i < len(expr) && (expr[i] == 'e' || expr[i] == 'E') { // only an exponent if digits follow, else it's the start of a name.
+					j := i + 1
+					if j < len(expr) && (expr[j] == '+' || expr[j] == '-') {
+						j++
+					}
+					if j < len(expr) && expr[j] >= '0' && expr[j] <= '9' {
+						i = j
+						for i < len(expr) && expr[i] >= '0' && expr[i] <= '9' {
+							i++
+						}
+					}
+				}
+				var err error
+				r, err = strconv.ParseFloat(expr[start:i], 64)
+				if err != nil {
+					return nil, fmt.Errorf("bad number %q at position %d", expr[start:i], start+1)
+				}
+			}
+			tokens = append(tokens, evalTokenType{kind: evalNum, str: expr[start:i], num: r, pos: start})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(expr) && (expr[i] == '_' || unicode.IsLetter(rune(expr[i])) || expr[i] >= '0' && expr[i] <= '9') {
+				i++
+			}
+			name := expr[start:i]
+			kind := evalName
+			if _, ok := evalFuncs[strings.ToUpper(name)]; ok {
+				kind = evalFunc
+			}
+			tokens = append(tokens, evalTokenType{kind: kind, str: name, pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+		}
+	}
+	tokens = append(tokens, evalTokenType{kind: evalEnd, pos: len(expr)})
+	return tokens, nil
+} // evalLex
+
+// ----------------------------------------------------------- evalParser -------------------------------------------------
+
+// evalParser -- recursive descent, emitting the tokens in RPN order as it goes.
+type evalParser struct {
+	tokens []evalTokenType
+	idx    int
+	rpn    []evalTokenType
+}
+
+func (p *evalParser) peek() evalTokenType {
+	return p.tokens[p.idx]
+}
+
+func (p *evalParser) next() evalTokenType {
+	t := p.tokens[p.idx]
+	if t.kind != evalEnd {
+		p.idx++
+	}
+	return t
+}
+
+func describe(t evalTokenType) string {
+	if t.kind == evalEnd {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q at position %d", t.str, t.pos+1)
+}
+
+// expr := term { (+|-) term }
+func (p *evalParser) expr() error {
+	if err := p.term(); err != nil {
+		return err
+	}
+	for t := p.peek(); t.kind == evalOp && (t.str == "+" || t.str == "-"); t = p.peek() {
+		p.next()
+		if err := p.term(); err != nil {
+			return err
+		}
+		p.rpn = append(p.rpn, t)
+	}
+	return nil
+}
+
+// term := unary { (*|/) unary }
+func (p *evalParser) term() error {
+	if err := p.unary(); err != nil {
+		return err
+	}
+	for t := p.peek(); t.kind == evalOp && (t.str == "*" || t.str == "/"); t = p.peek() {
+		p.next()
+		if err := p.unary(); err != nil {
+			return err
+		}
+		p.rpn = append(p.rpn, t)
+	}
+	return nil
+}
+
+// unary := (-|+) unary | power
+func (p *evalParser) unary() error {
+	if t := p.peek(); t.kind == evalOp && (t.str == "-" || t.str == "+") {
+		p.next()
+		if err := p.unary(); err != nil {
+			return err
+		}
+		if t.str == "-" {
+			p.rpn = append(p.rpn, evalTokenType{kind: evalFunc, str: "CHS", pos: t.pos})
+		}
+		return nil
+	}
+	return p.power()
+}
+
+// power := primary [ ^ unary ], which makes ^ right associative and allows 2^-1.
+func (p *evalParser) power() error {
+	if err := p.primary(); err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind == evalOp && t.str == "^" {
+		p.next()
+		if err := p.unary(); err != nil {
+			return err
+		}
+		p.rpn = append(p.rpn, t)
+	}
+	return nil
+}
+
+// primary := number | name | func ( expr ) | ( expr )
+func (p *evalParser) primary() error {
+	t := p.next()
+	switch t.kind {
+	case evalNum, evalName:
+		p.rpn = append(p.rpn, t)
+	case evalFunc:
+		if p.peek().kind != evalLParen {
+			return fmt.Errorf("function %s needs ( after it, at position %d", t.str, t.pos+1)
+		}
+		p.next()
+		if err := p.expr(); err != nil {
+			return err
+		}
+		if r := p.next(); r.kind != evalRParen {
+			return fmt.Errorf("expected ) but found %s", describe(r))
+		}
+		p.rpn = append(p.rpn, t)
+	case evalLParen:
+		if err := p.expr(); err != nil {
+			return err
+		}
+		if r := p.next(); r.kind != evalRParen {
+			return fmt.Errorf("expected ) but found %s", describe(r))
+		}
+	default:
+		return fmt.Errorf("expected a number, name or ( but found %s", describe(t))
+	}
+	return nil
+}
+
+// ----------------------------------------------------------- evalCompile ------------------------------------------------
+
+func evalCompile(expr string) ([]evalTokenType, error) {
+	tokens, err := evalLex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &evalParser{tokens: tokens}
+	if err = p.expr(); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != evalEnd {
+		return nil, fmt.Errorf("unexpected %s", describe(t))
+	}
+	return p.rpn, nil
+}
+
+// ----------------------------------------------------------- InfixToRPN -------------------------------------------------
+
+// InfixToRPN -- returns the RPN translation of an infix expression, as in "(3+4)*sqrt(2)" becomes "3 4 + 2 SQRT *".  Power is shown as **, since ^ is an integer power in RPN.
+func InfixToRPN(expr string) (string, error) {
+	rpn, err := evalCompile(expr)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, 0, len(rpn))
+	for _, t := range rpn {
+		switch t.kind {
+		case evalOp:
+			if t.str == "^" {
+				parts = append(parts, "**")
+			} else {
+				parts = append(parts, t.str)
+			}
+		case evalFunc:
+			if f, ok := evalFuncs[strings.ToUpper(t.str)]; ok {
+				parts = append(parts, f.rpn)
+			} else {
+				parts = append(parts, t.str) // CHS
+			}
+		default:
+			parts = append(parts, t.str)
+		}
+	}
+	return strings.Join(parts, " "), nil
+} // InfixToRPN
+
+// ----------------------------------------------------------- evalLookup -------------------------------------------------
+
+// evalLookup -- resolves a name against the stack registers, then LASTX, PI and E, then the mapped registers.  Mapped register names match exactly, and then ignoring case.
+func evalLookup(name string) (float64, error) {
+	upper := strings.ToUpper(name)
+	for i, regName := range StackRegNamesString {
+		if upper == strings.TrimSpace(regName) {
+			cplxFillFromFloat() // only does something in complex mode.
+			if calcMode == ComplexMode && imag(cplxStack[i]) != 0 {
+				return 0, fmt.Errorf("%s is %s, and eval only does real numbers", upper, ComplexString(cplxStack[i], 'g'))
+			}
+			return Stack[i], nil
+		}
+	}
+	switch upper {
+	case "LASTX":
+		return LastX, nil
+	case "PI":
+		return PI, nil
+	case "E":
+		return math.E, nil
+	}
+	readMappedReg()
+	if r, ok := mappedReg[name]; ok {
+		return r, nil
+	}
+	for regName, r := range mappedReg {
+		if strings.EqualFold(regName, name) {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("name %s is not a stack register or mapped register", name)
+} // evalLookup
+
+// ----------------------------------------------------------- EvalExpression ---------------------------------------------
+
+// EvalExpression -- evaluates an infix expression w/o changing the stack.  GetResult's EVAL pushes the result.
+func EvalExpression(expr string) (float64, error) {
+	rpn, err := evalCompile(expr)
+	if err != nil {
+		return 0, err
+	}
+	stack := make([]float64, 0, len(rpn))
+	for _, t := range rpn {
+		switch t.kind {
+		case evalNum:
+			stack = append(stack, t.num)
+		case evalName:
+			r, err := evalLookup(t.str)
+			if err != nil {
+				return 0, err
+			}
+			stack = append(stack, r)
+		case evalFunc:
+			top := len(stack) - 1
+			if t.str == "CHS" {
+				stack[top] = -stack[top]
+			} else {
+				stack[top] = evalFuncs[strings.ToUpper(t.str)].f(stack[top])
+			}
+		case evalOp:
+			top := len(stack) - 1
+			y, x := stack[top-1], stack[top]
+			stack = stack[:top]
+			switch t.str {
+			case "+":
+				stack[top-1] = y + x
+			case "-":
+				stack[top-1] = y - x
+			case "*":
+				stack[top-1] = y * x
+			case "/":
+				if x == 0 {
+					return 0, fmt.Errorf("divide by zero at position %d", t.pos+1)
+				}
+				stack[top-1] = y / x
+			case "^":
+				stack[top-1] = math.Pow(y, x)
+			}
+		}
+	}
+	return stack[0], nil
+} // EvalExpression
+
+// ----------------------------------------------------------- evalRoutine ------------------------------------------------
+
+// evalRoutine -- input string has had the "eval" keyword removed, so it's the expression.  The result is pushed onto the stack.
+func evalRoutine(s string) []string {
+	ss := make([]string, 0, 3)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		ss = append(ss, " eval needs an expression, as in eval (3+4)*sqrt(2)/x.  Command ignored.")
+		return ss
+	}
+	rpn, err := InfixToRPN(s)
+	if err != nil {
+		ss = append(ss, fmt.Sprintf(" eval: %s.  Command ignored.", err))
+		return ss
+	}
+	r, err := EvalExpression(s)
+	if err != nil {
+		ss = append(ss, fmt.Sprintf(" eval: %s.  Command ignored.", err))
+		return ss
+	}
+	PushMatrixStacks()
+	modePushX(r)
+	ss = append(ss, fmt.Sprintf(" RPN: %s = %s", rpn, strconv.FormatFloat(r, 'g', sigfig, 64)))
+	return ss
+} // evalRoutine
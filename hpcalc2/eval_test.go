@@ -0,0 +1,109 @@
+package hpcalc2
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInfixToRPN(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"(3+4)*sqrt(2)/x", "3 4 + 2 SQRT * x /"},
+		{"1+2*3", "1 2 3 * +"},
+		{"-2^2", "2 2 ** CHS"},   // unary minus is below ^
+		{"2^3^2", "2 3 2 ** **"}, // ^ is right associative
+		{"sin(30)", "30 SIN"},
+	}
+	for _, tc := range tests {
+		got, err := InfixToRPN(tc.expr)
+		if err != nil || got != tc.want {
+			t.Errorf("InfixToRPN(%q) = %q, %v, want %q", tc.expr, got, err, tc.want)
+		}
+	}
+}
+
+func TestEvalExpression(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	SetCalcMode(FloatMode)
+	GetResult("5 2") // Y is 5 and X is 2
+
+	tests := []struct {
+		expr    string
+		want    float64
+		wantErr bool
+	}{
+		{expr: "1+2*3", want: 7},
+		{expr: "-2^2", want: -4},
+		{expr: "2^3^2", want: 512},
+		{expr: "(3+4)*2", want: 14},
+		{expr: "sin(30)", want: 0.5},
+		{expr: "y^x", want: 25},
+		{expr: "x*pi", want: 2 * math.Pi},
+		{expr: "1/0", wantErr: true},
+		{expr: "3+", wantErr: true},
+		{expr: "(3+4", wantErr: true},
+		{expr: "nosuchreg+1", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := EvalExpression(tc.expr)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("EvalExpression(%q) = %g, want an error", tc.expr, got)
+			}
+			continue
+		}
+		if err != nil || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("EvalExpression(%q) = %g, %v, want %g", tc.expr, got, err, tc.want)
+		}
+	}
+}
+
+// TestEvalPush -- the result is pushed onto the stack of the current mode, so the rest of that stack is what it was.
+func TestEvalPush(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	defer SetCalcMode(FloatMode)
+
+	SetCalcMode(FloatMode)
+	GetResult("5")
+	GetResult("eval x+1")
+	if Stack[X] != 6 || Stack[Y] != 5 {
+		t.Errorf("5 eval x+1 in FLOAT: X = %g and Y = %g, want 6 and 5", Stack[X], Stack[Y])
+	}
+
+	SetCalcMode(ComplexMode)
+	GetResult("3 4i +")
+	GetResult("eval 1+1")
+	if cplxStack[X] != 2 || cplxStack[Y] != 3+4i {
+		t.Errorf("3+4i eval 1+1: X = %v and Y = %v, want 2 and 3+4i", cplxStack[X], cplxStack[Y])
+	}
+	_, ss := GetResult("eval y+1")
+	if len(ss) == 0 || !strings.Contains(ss[0], "real numbers") || cplxStack[X] != 2 {
+		t.Errorf("eval y+1 w/ Y = 3+4i: X = %v, msg %q, want an error and X left alone", cplxStack[X], ss)
+	}
+
+	SetCalcMode(BigFloatMode)
+	GetResult("1 3 /")
+	GetResult("eval 1+1")
+	if got := bigStack[Y].Text('g', 30); got != "0.333333333333333333333333333333" || Stack[X] != 2 {
+		t.Errorf("1/3 eval 1+1 in BIGFLOAT: Y = %s and X = %g, want 1/3 to 30 digits and 2", got, Stack[X])
+	}
+
+	SetCalcMode(RationalMode)
+	GetResult("1 3 /")
+	GetResult("eval 1+1")
+	if got := ratStack[Y].RatString(); got != "1/3" || ratStack[X].RatString() != "2" {
+		t.Errorf("1/3 eval 1+1 in RAT: Y = %s and X = %s, want 1/3 and 2", got, ratStack[X].RatString())
+	}
+
+	useProgMode(t)
+	GetResult("8 wsize")
+	GetResult("eval 300")
+	if progStack[X] != 300&0xff || Stack[X] != 300&0xff {
+		t.Errorf("eval 300 in 8 bit PROG: X = %d and Stack[X] = %g, want %d", progStack[X], Stack[X], 300&0xff)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"math"
+	"math/cmplx"
 	"math/rand"
 	"os"
 	"sort"
@@ -160,9 +161,16 @@ REVISION HISTORY
  8 May 25 -- Increased the initial capacity of the undo slice.
 20 Jan 26 -- Fixing a line of the help text.  And wrote SetSigFig function, to be used by a menu item in rpnf.
  3 Apr 26 -- Exported MapWriteAndClose function, to be used by map commands in the client programs.
+18 Oct 26 -- Added user defined macros, ie, keystroke programs.  DEFINE, UNDEF and MACROS commands.  Code is in macros.go.
+               And added BIGFLOAT and RAT modes, backing the stack w/ math/big, and the FLOAT, BIGPREC and MODE commands.  Code is in bigmode.go.
+               And added COMPLEX mode, w/ the I, J, CPLX, RE, IM, CONJ, R>P and P>R commands.  Code is in complexmode.go.
+               And added CONV and UNITS, backed by a unit table in units.go that the user can add to.  The old conversion commands are now aliases into the table.
+               And HOL takes years from 1700 thru 2500, and shows the holidays that are observed on another day from the US calendar in holidaycalc.
+               DEFINE, EVAL and UNDEF have to be a whole word now, so a macro named DEFINED or EVALX isn't taken for one of them.
+18 Oct 26 -- The 3 character PROG commands, like ROL, NOT and DEC, need the exact name, so ROLL, NOTE and DECIMAL are still unrecognized instead of switching to PROG mode.
 */
 
-const LastAlteredDate = "3 Apr 2026"
+const LastAlteredDate = "18 Oct 2026"
 
 const HeaderDivider = "+-------------------+------------------------------+"
 const SpaceFiller = "     |     "
@@ -213,14 +221,11 @@ var homedir string
 var mappedRegExists bool
 var CurUndoRedoIdx int
 
-const lb2g = 453.59238
-const oz2g = 28.34952
-const in2cm = 2.54
-const m2ft = 3.28084
-const mi2km = 1.609344
 const veryLargeNumber = 1e10
 const verySmallNumber = 1e-10
 
+var polarReplacer = strings.NewReplacer("R>P", "R2P", "r>p", "R2P", "R>p", "R2P", "r>P", "R2P", "P>R", "P2R", "p>r", "P2R", "P>r", "P2R", "p>R", "P2R")
+
 // -----------------------------------------------------------------------------------------------------------------------------
 func init() {
 	StackUndoMatrix = make([]StackType, 0, StackSize*StackSize) // initial capacity is larger than it used to be.  Not sure it matters, but I'm having fun here.
@@ -327,6 +332,71 @@ func init() {
 	cmdMap["CLEAN5"] = 660
 	cmdMap["SNAPSHOT"] = 680
 	cmdMap["SNAP"] = 680
+	cmdMap["MACROS"] = 690
+	cmdMap["BIGFLOAT"] = 700
+	cmdMap["BIGF"] = 700
+	cmdMap["RAT"] = 710
+	cmdMap["RATIONAL"] = 710
+	cmdMap["FLOAT"] = 720
+	cmdMap["BIGPREC"] = 730
+	cmdMap["MODE"] = 740
+	cmdMap["I"] = 750 // only means imaginary, as in 4i, and switches to complex mode if needed.
+	cmdMap["J"] = 750
+	cmdMap["CPLX"] = 760
+	cmdMap["COMPLEX"] = 770
+	cmdMap["RE"] = 780
+	cmdMap["IM"] = 785
+	cmdMap["CONJ"] = 790
+	cmdMap["R2P"] = 800 // R>P and P>R are changed to these in GetResult, since > is an operator to the tokenizer.
+	cmdMap["P2R"] = 810
+	cmdMap["UNITS"] = 820
+	cmdMap["SUMPLUS"] = 830 // Σ+ and SIGMA+ are changed to this in GetResult, since + is an operator to the tokenizer.
+	cmdMap["SUMMINUS"] = 840
+	cmdMap["CLSUM"] = 850
+	cmdMap["MEAN"] = 860
+	cmdMap["SDEV"] = 870
+	cmdMap["LR"] = 880
+	cmdMap["CORR"] = 890
+	cmdMap["YHAT"] = 900
+	cmdMap["XHAT"] = 905
+	cmdMap["SUMSHO"] = 910
+	cmdMap["N"] = 920 // IR, not I, since I is imaginary.  And not RATE, since that would be taken as RAT.
+	cmdMap["IR"] = 922
+	cmdMap["PV"] = 924
+	cmdMap["PMT"] = 926
+	cmdMap["FV"] = 928
+	cmdMap["SOLVEN"] = 930
+	cmdMap["SOLVEIR"] = 932
+	cmdMap["SOLVEPV"] = 934
+	cmdMap["SOLVEPMT"] = 936
+	cmdMap["SOLVEFV"] = 938
+	cmdMap["TVMBEG"] = 940
+	cmdMap["TVMEND"] = 942
+	cmdMap["TVMSHO"] = 944
+	cmdMap["CLTVM"] = 946
+	cmdMap["AMORT"] = 950
+	cmdMap["NPV"] = 960
+	cmdMap["IRR"] = 970
+	cmdMap["AND"] = 1000
+	cmdMap["OR"] = 1010
+	cmdMap["XOR"] = 1020
+	cmdMap["NOT"] = 1030
+	cmdMap["SHL"] = 1040
+	cmdMap["SHR"] = 1050
+	cmdMap["ASR"] = 1055
+	cmdMap["ROL"] = 1060 // ROLLDN has its own entry, so this doesn't take it over.  And ROL is in exactCmds, so ROLL isn't ROL.
+	cmdMap["ROR"] = 1070
+	cmdMap["SETBIT"] = 1080
+	cmdMap["CLRBIT"] = 1090
+	cmdMap["TESTBIT"] = 1100
+	cmdMap["WSIZE"] = 1110
+	cmdMap["SIGNED"] = 1120
+	cmdMap["UNSIGNED"] = 1130
+	cmdMap["BIN"] = 1140
+	cmdMap["OCT"] = 1150
+	cmdMap["DEC"] = 1160
+	cmdMap["PROG"] = 1170
+	cmdMap["MASK"] = 1180
 
 	homedir, err = os.UserHomeDir() // This func became available as of Go 1.12
 	if err != nil {
@@ -335,6 +405,8 @@ func init() {
 	}
 
 	fullMappedRegFilename = homedir + string(os.PathSeparator) + mappedRegFilename
+	fullUserProgFilename = homedir + string(os.PathSeparator) + userProgFilename // user programs are kept next to the mapped registers.
+	fullUserUnitsFilename = homedir + string(os.PathSeparator) + userUnitsFilename
 
 	mappedReg = make(map[string]float64, 100)
 	mappedRegFile, er := os.Open(fullMappedRegFilename) // open for reading
@@ -357,6 +429,17 @@ func init() {
 	//}
 } // init
 
+// exactCmds are the 3 character commands that aren't matched by the first 3 characters of a longer word, so ROLL, NOTE, DECIMAL and BINARY aren't taken as PROG commands.
+var exactCmds = map[string]bool{"AND": true, "XOR": true, "NOT": true, "SHL": true, "SHR": true, "ASR": true, "ROL": true, "ROR": true, "BIN": true, "OCT": true, "DEC": true}
+
+// abbrevCmdNum -- the command number from the first 3 characters of s, as in SIGFIG4 or STOA.  It's 0 if s is too short, or it starts w/ one of the exactCmds.
+func abbrevCmdNum(s string) int {
+	if len(s) < 3 || exactCmds[s[:3]] {
+		return 0
+	}
+	return cmdMap[s[:3]]
+}
+
 // -----------------------------------------------------mapWriteAndClose --------------------------------------------------------------------
 
 func MapWriteAndClose() {
@@ -513,6 +596,10 @@ func DumpStackFixed() []string {
 	//                                       var SRN int   I would never do this now, so I'm removing it
 	var str string
 
+	if calcMode != FloatMode {
+		return dumpModeStack('f')
+	}
+
 	ss := make([]string, 0, StackSize+2)
 	ss = append(ss, HeaderDivider)
 	for SRN := T1; SRN >= X; SRN-- {
@@ -538,6 +625,10 @@ func DumpStackFloat() []string {
 	var SRN int
 	var str string
 
+	if calcMode != FloatMode {
+		return dumpModeStack('e')
+	}
+
 	ss := make([]string, 0, StackSize+2)
 	ss = append(ss, HeaderDivider)
 	for SRN = T1; SRN >= X; SRN-- {
@@ -568,6 +659,10 @@ func DumpStackGeneral() []string {
 	var SRN int
 	var str string
 
+	if calcMode != FloatMode {
+		return dumpModeStack('g')
+	}
+
 	ss := make([]string, 0, StackSize+2)
 	ss = append(ss, HeaderDivider)
 	for SRN = T1; SRN >= X; SRN-- {
@@ -578,6 +673,67 @@ func DumpStackGeneral() []string {
 	return ss
 } // DumpStackGeneral
 
+// dumpModeStack -- dumps the stack that backs the current mode, when that's not the float64 stack.
+func dumpModeStack(format byte) []string {
+	switch calcMode {
+	case ComplexMode:
+		return dumpComplexStack(format)
+	case ProgrammerMode:
+		return dumpProgStack()
+	}
+	return dumpBigStack(format)
+} // dumpModeStack
+
+// modePushX -- PUSHX for the code that's called from GetResult instead of Result, like EVAL.  The stack that backs the current mode is pushed too,
+// so its other registers keep what the float64 stack can't hold, like the imaginary parts or the big precision.
+func modePushX(r float64) {
+	switch calcMode {
+	case BigFloatMode, RationalMode:
+		bigFillFromFloat()
+		bigStackUp()
+		if calcMode == BigFloatMode {
+			bigStack[X] = floatToBig(r)
+		} else {
+			ratStack[X] = floatToRat(r)
+		}
+	case ComplexMode:
+		cplxFillFromFloat()
+		cplxStackUp()
+		cplxStack[X] = complex(r, 0)
+	case ProgrammerMode:
+		progFillFromFloat()
+		progStackUp()
+		progStack[X] = floatToProg(r)
+		defer progToFloatStack() // so the float64 X is masked to the word size.
+	}
+	PUSHX(r)
+} // modePushX
+
+// modeSetX -- replaces X w/ r, and saves LastX, in the float64 stack and the stack that backs the current mode.
+func modeSetX(r float64) {
+	switch calcMode {
+	case BigFloatMode, RationalMode:
+		bigFillFromFloat()
+		bigSaveLastX()
+		if calcMode == BigFloatMode {
+			bigStack[X] = floatToBig(r)
+		} else {
+			ratStack[X] = floatToRat(r)
+		}
+	case ComplexMode:
+		cplxFillFromFloat()
+		cplxLastX = cplxStack[X]
+		cplxStack[X] = complex(r, 0)
+	case ProgrammerMode:
+		progFillFromFloat()
+		progLastX = progStack[X]
+		progStack[X] = floatToProg(r)
+		defer progToFloatStack()
+	}
+	LastX = Stack[X]
+	Stack[X] = r
+} // modeSetX
+
 // ToHex -- Uses an elegant algorithm I recently read about.
 func ToHex(L float64) string {
 	const hexDigits = "0123456789abcdef"
@@ -718,6 +874,11 @@ func PWRI(R float64, I int) float64 {
 // PushMatrixStacks -- Saves the stack state prior to an operation that will change the stack state.
 func PushMatrixStacks() { // this is called from GetResult before an operation that would change the stack.
 	StackUndoMatrix = append(StackUndoMatrix, Stack)
+	bigUndoMatrix = append(bigUndoMatrix, bigStack) // the big stacks are kept parallel to the float64 stack, even when not in use, so the indices match.
+	ratUndoMatrix = append(ratUndoMatrix, ratStack)
+	cplxUndoMatrix = append(cplxUndoMatrix, cplxStack)
+	progUndoMatrix = append(progUndoMatrix, progStack)
+	statsUndoMatrix = append(statsUndoMatrix, statsSnapshot())
 	CurUndoRedoIdx = len(StackUndoMatrix) - 1
 }
 
@@ -726,10 +887,19 @@ func UndoMatrixStacks() {
 	// only push the current state if it's not already been pushed.  IE, first undo for this stack state.  This is so I can redo to this stack if I want.
 	if CurUndoRedoIdx == len(StackUndoMatrix)-1 {
 		StackUndoMatrix = append(StackUndoMatrix, Stack)
+		bigUndoMatrix = append(bigUndoMatrix, bigStack)
+		ratUndoMatrix = append(ratUndoMatrix, ratStack)
+		cplxUndoMatrix = append(cplxUndoMatrix, cplxStack)
+		progUndoMatrix = append(progUndoMatrix, progStack)
+		statsUndoMatrix = append(statsUndoMatrix, statsSnapshot())
 	}
 	// There was a subtle bug here.  In versions of this code before 5/7/25, I first decremented the pointer and then used it.  That's a mistake and caused it to skip the most recent previous stack state in the undo operation.
 	// In other words, this needs to be a post-decremented pointer, and I was pre decrementing it since I wrote this code in Apr 2024.  Oops.
 	Stack = StackUndoMatrix[CurUndoRedoIdx]
+	bigStack, ratStack = bigUndoMatrix[CurUndoRedoIdx], ratUndoMatrix[CurUndoRedoIdx]
+	cplxStack = cplxUndoMatrix[CurUndoRedoIdx]
+	progStack = progUndoMatrix[CurUndoRedoIdx]
+	undoStatsReg(statsUndoMatrix[CurUndoRedoIdx])
 	if CurUndoRedoIdx > 0 {
 		CurUndoRedoIdx--
 	}
@@ -744,6 +914,10 @@ func RedoMatrixStacks() {
 		CurUndoRedoIdx++
 	}
 	Stack = StackUndoMatrix[CurUndoRedoIdx]
+	bigStack, ratStack = bigUndoMatrix[CurUndoRedoIdx], ratUndoMatrix[CurUndoRedoIdx]
+	cplxStack = cplxUndoMatrix[CurUndoRedoIdx]
+	progStack = progUndoMatrix[CurUndoRedoIdx]
+	undoStatsReg(statsUndoMatrix[CurUndoRedoIdx])
 }
 
 // Floor -- To automatically fix the small floating point errors introduced by the conversions.  Real can be negative, places cannot be negative or > 10.
@@ -924,6 +1098,18 @@ func mapRoutines(s string) (float64, []string) {
 	return R, ss
 } // end mapRoutines.
 
+// hasKeyword -- true if scap starts w/ the keyword as a whole word, so a macro named EVALX or DEFINED isn't taken as EVAL or DEFINE.
+func hasKeyword(scap, keyword string) bool {
+	if !strings.HasPrefix(scap, keyword) {
+		return false
+	}
+	if len(scap) == len(keyword) {
+		return true
+	}
+	next := scap[len(keyword)]
+	return !(next >= 'a' && next <= 'z' || next >= '0' && next <= '9')
+}
+
 // GetResult -- Input a string of commands and operations, return the result as a float64 and a message as a slice of strings.
 func GetResult(s string) (float64, []string) {
 	var token tknptr.TokenType
@@ -937,7 +1123,28 @@ func GetResult(s string) (float64, []string) {
 		R, stringslice = mapRoutines(s)
 		return R, stringslice
 	}
+	if hasKeyword(scap, "define") { // the rest of the line is the name and the program, so it can't go thru the tokenizer.
+		stringslice = defineRoutine(s[6:])
+		return READX(), stringslice
+	}
+	if hasKeyword(scap, "eval") { // the rest of the line is an infix expression, which the RPN tokenizer can't handle.
+		stringslice = evalRoutine(s[4:])
+		return READX(), stringslice
+	}
+	if idx := convIndex(scap); idx >= 0 { // the rest of the line is the 2 units.  Anything before conv is done first, as in 30 conv psi kpa.
+		if idx > 0 {
+			_, stringslice = GetResult(s[:idx])
+		}
+		stringslice = append(stringslice, convRoutine(s[idx+4:])...)
+		return READX(), stringslice
+	}
+	if hasKeyword(scap, "undef") {
+		stringslice = undefineRoutine(s[5:])
+		return READX(), stringslice
+	}
 
+	s = polarReplacer.Replace(s)  // R>P and P>R would otherwise be 3 tokens.
+	s = sigmaReplacer.Replace(s)  // and Σ+ would be 2 tokens, or none since Σ isn't ASCII.
 	tokenPointer := tknptr.New(s) // Changed to use the Go idiom instead of INITKN(s)
 	for {
 		token, EOL = tokenPointer.TokenReal()
@@ -952,6 +1159,27 @@ func GetResult(s string) (float64, []string) {
 func Result(tkn tknptr.TokenType) (float64, []string) {
 	ss := make([]string, 0, 100) // ss is abbrev for stringslice.
 
+	switch calcMode { // the other modes handle what they can, and the rest falls thru to the float64 code below.
+	case BigFloatMode, RationalMode:
+		handled, stringslice := bigResult(tkn)
+		if handled {
+			return Stack[X], stringslice
+		}
+		defer bigFillFromFloat() // so the registers changed by the float64 code get back into the big stack.
+	case ComplexMode:
+		handled, stringslice := complexResult(tkn)
+		if handled {
+			return Stack[X], stringslice
+		}
+		defer cplxFillFromFloat()
+	case ProgrammerMode:
+		handled, stringslice := progResult(tkn)
+		if handled {
+			return Stack[X], stringslice
+		}
+		defer progFillFromFloat()
+	}
+
 outerloop:
 	switch tkn.State {
 	case tknptr.DELIM:
@@ -992,9 +1220,14 @@ outerloop:
 		} // opcode value condition
 	case tknptr.ALLELSE:
 		cmdnum := cmdMap[tkn.Str]
-		if cmdnum == 0 && len(tkn.Str) > 2 {
-			TokenStrShortened := tkn.Str[:3] // First 3 characters, i.e., characters at positions 0, 1 and 2
-			cmdnum = cmdMap[TokenStrShortened]
+		if cmdnum == 0 { // user programs are checked before the 3 character abbreviations, so a macro named HOLD doesn't run HOL.
+			if body, ok := isMacro(tkn.Str); ok {
+				ss = append(ss, runMacro(tkn.Str, body)...)
+				break
+			}
+		}
+		if cmdnum == 0 {
+			cmdnum = abbrevCmdNum(tkn.Str) // First 3 characters, i.e., characters at positions 0, 1 and 2
 		}
 		if cmdnum == 0 {
 			ss = append(ss, fmt.Sprintf(" %s is an unrecognized command.", tkn.Str))
@@ -1090,8 +1323,22 @@ outerloop:
 			ss = append(ss, " clean, clean4, clean5, cleanN -- Automatically correct the small floating point errors to 4, 5 or N decimal places.")
 			ss = append(ss, " SigFigN,FixN -- Set the significant figures to N for the stack display string.  Default is -1.")
 			ss = append(ss, " substitutions: = for +, ; for *.")
-			ss = append(ss, " lb2g, oz2g, cm2in, m2ft, mi2km, c2f and their inverses -- unit conversions.")
+			ss = append(ss, " CONV from to -- converts X in place, as in conv psi kpa.  UNITS lists the units, and more can be added in ~/"+userUnitsFilename+".")
+			ss = append(ss, " lb2g, oz2g, cm2in, m2ft, mi2km, c2f and their inverses -- unit conversions that push the result.")
 			ss = append(ss, " mapsho, mapsto, maprcl, mapdel -- mappedReg commands.  !` become spaces in the name, but spaces are now allowed.")
+			ss = append(ss, " BIGFLOAT, RAT, FLOAT -- back the stack w/ math/big.Float, exact math/big.Rat fractions, or float64.  BIGPREC sets big.Float digits from X.  MODE shows the mode.")
+			ss = append(ss, " COMPLEX -- complex128 stack.  4i or 4j is imaginary, CPLX makes Y + Xi.  RE, IM, CONJ.  SQRT, LN and EXP are complex.  Other ops are an error while the stack has imaginary parts.")
+			ss = append(ss, " R>P, P>R or R2P, P2R -- rectangular <--> polar, angle in degrees.  Uses X in COMPLEX mode, else X and Y.")
+			ss = append(ss, " Σ+, Σ- or SIGMA+, SIGMA- -- add or remove the x in X and y in Y from the statistics registers.  CLSUM clears them, SUMSHO shows them.")
+			ss = append(ss, " MEAN, SDEV -- x and y mean or sample std dev into X and Y.  LR -- intercept into X, slope into Y.  CORR -- r.  YHAT, XHAT -- estimates from X.")
+			ss = append(ss, " N, IR, PV, PMT, FV -- store X in the TVM register.  IR is percent per period.  SOLVEN, SOLVEIR, SOLVEPV, SOLVEPMT, SOLVEFV compute one from the others.")
+			ss = append(ss, " TVMBEG, TVMEND, TVMSHO, CLTVM -- payment timing, show and clear.  AMORT -- schedule.  NPV, IRR -- of mapped registers CF0, CF1, ... at IR.")
+			ss = append(ss, " PROG -- integer stack.  WSIZE from X of 8, 16, 32, 64.  SIGNED, UNSIGNED.  BIN, OCT, DEC, HEX set the base.  0b, 0o, 0x literals.")
+			ss = append(ss, " AND, OR, XOR, SHL, SHR, ASR, ROL, ROR of Y by X.  SETBIT, CLRBIT, TESTBIT of Y w/ bit number in X.  NOT, MASK of X.")
+			ss = append(ss, " EVAL expr -- infix, as in eval (3+4)*sqrt(2)/x.  Names are X, Y, Z, T5..T1, LASTX, PI, E and mapped registers.  Shows the RPN and pushes the result.")
+			ss = append(ss, ` DEFINE name "tokens" -- saves a user program that runs when name is entered.  UNDEF name deletes it, MACROS lists them.`)
+			ss = append(ss, " In a program, X=0? X#0? X<0? X>0? X=Y? X#Y? X<Y? X>Y? X<=Y? X>=Y? run the next step only if true.")
+			ss = append(ss, " In a program, LBL n and GTO n branch, STOI and RCLI use the loop count register I, DSZ decrements I and skips next step if zero, RTN stops.")
 			ss = append(ss, fmt.Sprintf(" last altered hpcalc2 %s.\n", LastAlteredDate))
 		case 130: // STO not used by rpnf, rpnf2 and probably rpng, rpnt, which have their own storage registers.
 			MemReg = Stack[X]
@@ -1265,7 +1512,7 @@ outerloop:
 			} else if year < 100 {
 				year += 1900
 			}
-			if (year >= 1900) && (year <= 2100) {
+			if (year >= 1700) && (year <= 2500) { // the range of timlibg.JULIAN
 				Holiday := holidaycalc.GetHolidays(year)
 				Holiday.Valid = true
 				ss = append(ss, fmt.Sprintf(" For year %d:", Holiday.Year))
@@ -1282,6 +1529,20 @@ outerloop:
 				ChristmasD := timlibg.JULIAN(12, 25, Y) % 7
 				ss = append(ss, fmt.Sprintf("Election Day is November %d, Veteran's Day is a %s, Thanksgiving is November %d, and Christmas Day is a %s.",
 					Holiday.Election.D, timlibg.DayNames[VetD], Holiday.Thanksgiving.D, timlibg.DayNames[ChristmasD]))
+
+				if cal, err := holidaycalc.GetCalendar("US"); err != nil {
+					ss = append(ss, fmt.Sprintf(" %s", err))
+				} else {
+					observed := make([]string, 0, 5)
+					for _, h := range cal.Holidays(Y) {
+						if !h.Observed.Equal(h.Date) {
+							observed = append(observed, fmt.Sprintf("%s on %s", h.Name, h.Observed.Format("Mon Jan 2")))
+						}
+					}
+					if len(observed) > 0 {
+						ss = append(ss, "Observed "+strings.Join(observed, ", ")+".")
+					}
+				}
 			} else { // added 1/22/20.
 				s := " X register is not a valid year.  Command ignored."
 				ss = append(ss, s)
@@ -1427,127 +1688,8 @@ outerloop:
 			//	}
 			//}
 
-		case 540: // lb2g = 453.59238
-			r := READX() * lb2g
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s1 := fmt.Sprintf("%s pounds is %s grams", x, s0)
-			ss = append(ss, s1)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 550: // oz2g = 28.34952
-			r := READX() * oz2g
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s oz is %s grams", x, s0)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 560: // cm2in = 2.54
-			r := READX() / in2cm
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s cm is %s inches", x, s0)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 570: // m2ft = 3.28084
-			r := READX() * m2ft
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s meters is %s feet", x, s0)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 580: // mi2km = 1.609344
-			r := READX() * mi2km
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s miles is %s km", x, s0)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 590: // g2lb
-			r := READX() / lb2g
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s1 := fmt.Sprintf("%s grams is %s pounds", x, s0)
-			ss = append(ss, s1)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 600: // g2oz
-			r := READX() / oz2g
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s1 := fmt.Sprintf("%s grams is %s oz", x, s0)
-			ss = append(ss, s1)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 610: //in2cm
-			r := READX() * in2cm
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s1 := fmt.Sprintf("%s inches is %s cm", x, s0)
-			ss = append(ss, s1)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 620: // ft2m
-			r := READX() / m2ft
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s ft is %s meters", x, s0)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 630: // km2mi
-			r := READX() / mi2km
-			x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
-			s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s km is %s mi", x, s0)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(r)
-
-		case 633: // C2F
-			x := READX()
-			xstr := strconv.FormatFloat(x, 'f', sigfig, 64)
-			fdeg := x*1.8 + 32
-			fstr := strconv.FormatFloat(fdeg, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s deg C is %s deg F", xstr, fstr)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(fdeg)
-
-		case 636: // F2C
-			x := READX()
-			xstr := strconv.FormatFloat(x, 'f', sigfig, 64)
-			cdeg := (x - 32) / 1.8
-			cstr := strconv.FormatFloat(cdeg, 'f', sigfig, 64)
-			s := fmt.Sprintf("%s deg F is %s deg C", xstr, cstr)
-			ss = append(ss, s)
-			PushMatrixStacks()
-			LastX = Stack[X]
-			PUSHX(cdeg)
+		case 540, 550, 560, 570, 580, 590, 600, 610, 620, 630, 633, 636: // LB2G, OZ2G, CM2IN, M2FT, MI2KM, G2LB, G2OZ, IN2CM, FT2M, KM2MI, C2F, F2C are now aliases into the unit table.
+			ss = append(ss, convAlias(cmdnum)...)
 
 		case 640: // map.   Now to deal w/ subcommands mapsto, maprcl, mapdel and mapsho, etc.
 			// Will read a fresh copy of the map reg file because I want it to not be stale.
@@ -1668,6 +1810,60 @@ outerloop:
 		// case 670 is volpi, above right under vol.
 		case 680: // SNAPSHOT
 			PushMatrixStacks()
+		case 690: // MACROS
+			ss = append(ss, macroList()...)
+		case 700: // BIGFLOAT or BIGF
+			SetCalcMode(BigFloatMode)
+			ss = append(ss, fmt.Sprintf(" Stack is now math/big.Float w/ %d bits of precision.", bigPrec))
+		case 710: // RAT or RATIONAL
+			SetCalcMode(RationalMode)
+			ss = append(ss, " Stack is now math/big.Rat, exact fractions.")
+		case 720: // FLOAT
+			SetCalcMode(FloatMode)
+			ss = append(ss, " Stack is now float64.")
+		case 730: // BIGPREC
+			digits := int(Round(Stack[X]))
+			if digits < 1 || digits > 100_000 {
+				ss = append(ss, " BIGPREC needs the number of decimal digits in X, from 1 to 100,000.  Command ignored.")
+				break
+			}
+			SetBigPrec(digits)
+			ss = append(ss, fmt.Sprintf(" BIGFLOAT precision is now %d decimal digits, which is %d bits.", digits, bigPrec))
+		case 740: // MODE
+			ss = append(ss, fmt.Sprintf(" mode is %s, BIGFLOAT precision is %d bits.", CalcModeNames[calcMode], bigPrec))
+		case 750, 760: // I, J or CPLX when not already in complex mode.
+			SetCalcMode(ComplexMode)
+			ss = append(ss, " Stack is now complex128.")
+			_, stringslice := complexResult(tkn)
+			ss = append(ss, stringslice...)
+		case 770: // COMPLEX
+			SetCalcMode(ComplexMode)
+			ss = append(ss, " Stack is now complex128.")
+		case 780, 785, 790: // RE, IM, CONJ
+			ss = append(ss, fmt.Sprintf(" %s only works in COMPLEX mode.  Command ignored.", tkn.Str))
+		case 800: // R2P, or R>P.  X and Y are x and y, and become r in X and theta in Y.
+			PushMatrixStacks()
+			LastX = Stack[X]
+			r, theta := cmplx.Polar(complex(Stack[X], Stack[Y]))
+			Stack[X] = r
+			Stack[Y] = theta * 180.0 / PI
+		case 810: // P2R, or P>R.  X is r and Y is theta in degrees, and become x in X and y in Y.
+			PushMatrixStacks()
+			LastX = Stack[X]
+			z := cmplx.Rect(Stack[X], Stack[Y]*PI/180.0)
+			Stack[X] = real(z)
+			Stack[Y] = imag(z)
+		case 1000, 1010, 1020, 1030, 1040, 1050, 1055, 1060, 1070, 1080, 1090, 1100, 1110, 1120, 1130, 1140, 1150, 1160, 1170, 1180: // AND thru MASK when not in PROG mode.
+			SetCalcMode(ProgrammerMode)
+			ss = append(ss, " Stack is now integers.")
+			_, stringslice := progResult(tkn)
+			ss = append(ss, stringslice...)
+		case 820: // UNITS
+			ss = append(ss, unitList()...)
+		case 830, 840, 850, 860, 870, 880, 890, 900, 905, 910: // the statistics commands, SUMPLUS thru SUMSHO.
+			ss = append(ss, statsResult(cmdnum)...)
+		case 920, 922, 924, 926, 928, 930, 932, 934, 936, 938, 940, 942, 944, 946, 950, 960, 970: // the TVM commands, N thru IRR.
+			ss = append(ss, tvmResult(cmdnum)...)
 
 		case 999: // do nothing, ignore me but don't generate an error message.
 
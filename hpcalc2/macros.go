@@ -0,0 +1,323 @@
+package hpcalc2
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sort"
+	"src/tknptr"
+	"strings"
+	"time"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- User defined macros, or keystroke programs, like the old HP-25.  DEFINE name "tokens..." saves a named sequence of tokens, and then the name is a
+                 command that replays the tokens thru Result.  The definitions are saved in a gob file next to the mapped register file, so all the front ends share them.
+                 Within a program, X<Y? style tests execute the next step only if true, like the HP-25.  LBL, GTO, STOI, RCLI and DSZ give loops w/ a loop count in the I register.
+  18 Oct 26 -- Steps now go thru GetResult, so MAPSTO, CONV, R>P and Σ+ work in a program the same as typed in.  MAPSTO, MAPRCL and MAPDEL keep their name, and CONV its
+                 2 units, as one step.  The programs are kept in memory, and the file is only decoded again when its time or size changes, instead of for every
+                 unknown token.  DEFINE only takes a name that the tokenizer gives back as the same one word, so every macro can be run.
+*/
+
+const userProgFilename = "userprog.gob"
+const maxMacroSteps = 10_000 // so a runaway GTO loop can't hang the calculator.
+const maxMacroDepth = 20     // macros can call other macros, but not forever.
+
+var userProg map[string]string // name -> body of the program, all upper case.
+var fullUserProgFilename string
+var userProgReadFrom string // the file userProg was read from, and its time and size then, so it's only decoded again after it changes.
+var userProgModTime time.Time
+var userProgSize int64
+var macroIReg float64 // the I register used for loop counts, as in the HP-67 and HP-29C.
+var macroDepth int
+
+type macroStructType struct { // so the macro listing can be sorted, like mappedRegStructType.
+	name string
+	body string
+}
+
+// ------------------------------------------------------------ readUserProg --------------------------------------------------
+
+// readUserProg -- reads the user program file if it changed since it was last read, so one front end doesn't have a stale copy of what another one defined.
+func readUserProg() {
+	fi, err := os.Stat(fullUserProgFilename)
+	if err != nil { // no file yet is not an error, it just means nothing has been defined.
+		userProg = make(map[string]string)
+		userProgReadFrom = ""
+		return
+	}
+	if userProg != nil && userProgReadFrom == fullUserProgFilename && fi.ModTime().Equal(userProgModTime) && fi.Size() == userProgSize {
+		return
+	}
+	userProgReadFrom, userProgModTime, userProgSize = fullUserProgFilename, fi.ModTime(), fi.Size()
+	userProg = make(map[string]string)
+	userProgFile, err := os.Open(fullUserProgFilename)
+	if err != nil {
+		return
+	}
+	defer userProgFile.Close()
+	decoder := gob.NewDecoder(userProgFile)
+	err = decoder.Decode(&userProg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "from gob decoder of user programs", err)
+	}
+	if userProg == nil { // an empty file decodes into a nil map.
+		userProg = make(map[string]string)
+	}
+} // readUserProg
+
+// ------------------------------------------------------------ writeUserProg --------------------------------------------------
+
+// writeUserProg -- writes the user programs to their file, truncating it first, same as MapWriteAndClose does.
+func writeUserProg() {
+	userProgFile, err := os.Create(fullUserProgFilename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "from os.Create", err)
+		return
+	}
+	defer userProgFile.Close()
+	encoder := gob.NewEncoder(userProgFile)
+	err = encoder.Encode(&userProg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "from gob encoder of user programs", err)
+	}
+	userProgFile.Sync()
+	if fi, err := userProgFile.Stat(); err == nil { // userProg is what was just written, so it doesn't need to be read back.
+		userProgReadFrom, userProgModTime, userProgSize = fullUserProgFilename, fi.ModTime(), fi.Size()
+	}
+} // writeUserProg
+
+// ------------------------------------------------------------ defineRoutine --------------------------------------------------
+
+// defineRoutine -- input string has had the "define" keyword removed, so it starts w/ the name, followed by the tokens in quotes.
+func defineRoutine(s string) []string {
+	ss := make([]string, 0, 5)
+	s = strings.TrimSpace(s)
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		ss = append(ss, `define needs a name and a program, as in define sq2 "2 sqrt".  Command ignored.`)
+		return ss
+	}
+	name := strings.ToUpper(fields[0])
+	if cmdMap[name] != 0 || (name[0] >= '0' && name[0] <= '9') {
+		ss = append(ss, fmt.Sprintf("%s is a built in command or a number, and cannot be a macro name.  Command ignored.", name))
+		return ss
+	}
+	if !macroNameOK(name) {
+		ss = append(ss, fmt.Sprintf("%s can't be entered as one word, or is used by GetResult or in programs, so it cannot be a macro name.  Command ignored.", name))
+		return ss
+	}
+	body := strings.TrimSpace(s[len(fields[0]):])
+	body = strings.Trim(body, `"'`)
+	body = strings.ToUpper(strings.TrimSpace(body))
+	if body == "" {
+		ss = append(ss, fmt.Sprintf("define %s has an empty program.  Command ignored.", name))
+		return ss
+	}
+
+	readUserProg()
+	userProg[name] = body
+	writeUserProg()
+	ss = append(ss, fmt.Sprintf("defined %s as %q", name, body))
+	return ss
+} // defineRoutine
+
+// ------------------------------------------------------------ macroNameOK ---------------------------------------------------
+
+// macroNameOK -- a macro only runs if the tokenizer gives its name back as one ALLELSE token, and GetResult and runMacro don't take it first.
+func macroNameOK(name string) bool {
+	if strings.HasPrefix(name, "MAP") { // the map commands don't need a space, as in mapsto.
+		return false
+	}
+	switch name {
+	case "DEFINE", "UNDEF", "EVAL", "CONV", "LBL", "GTO", "RTN", "STOP", "STOI", "RCLI", "DSZ":
+		return false
+	}
+	if polarReplacer.Replace(name) != name || sigmaReplacer.Replace(name) != name {
+		return false
+	}
+	tokenPointer := tknptr.New(name)
+	token, EOL := tokenPointer.TokenReal()
+	if EOL || token.State != tknptr.ALLELSE || token.Str != name {
+		return false
+	}
+	_, EOL = tokenPointer.TokenReal()
+	return EOL
+} // macroNameOK
+
+// ------------------------------------------------------------ undefineRoutine ------------------------------------------------
+
+func undefineRoutine(s string) []string {
+	ss := make([]string, 0, 2)
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if name == "" {
+		ss = append(ss, "undef needs a macro name.  None found so command ignored.")
+		return ss
+	}
+	readUserProg()
+	if _, ok := userProg[name]; !ok {
+		ss = append(ss, fmt.Sprintf("macro %s not found in undef cmd.  Command ignored.", name))
+		return ss
+	}
+	delete(userProg, name)
+	writeUserProg()
+	ss = append(ss, fmt.Sprint("deleted macro ", name))
+	return ss
+} // undefineRoutine
+
+// ------------------------------------------------------------ macroList ----------------------------------------------------
+
+// macroList -- returns the user programs sorted by name, ready for output.
+func macroList() []string {
+	readUserProg()
+	ss := make([]string, 0, len(userProg)+1)
+	if len(userProg) == 0 {
+		ss = append(ss, "No macros are defined.")
+		return ss
+	}
+	macros := make([]macroStructType, 0, len(userProg))
+	for name, body := range userProg {
+		macros = append(macros, macroStructType{name, body})
+	}
+	sort.Slice(macros, func(i, j int) bool {
+		return macros[i].name < macros[j].name
+	})
+	ss = append(ss, fmt.Sprint("Number of macros is ", len(macros)))
+	for _, m := range macros {
+		ss = append(ss, fmt.Sprintf("%s = %q", m.name, m.body))
+	}
+	return ss
+} // macroList
+
+// ------------------------------------------------------------ isMacro ------------------------------------------------------
+
+// isMacro -- returns the body of the program and true if name is a user defined program.  The file is only read again if it changed.
+func isMacro(name string) (string, bool) {
+	readUserProg()
+	body, ok := userProg[name]
+	return body, ok
+}
+
+// ------------------------------------------------------------ macroSteps ---------------------------------------------------
+
+// macroSteps -- splits a program body into its steps.  LBL and GTO take the next field as their label, so they stay together as one step.
+// So do MAPSTO, MAPRCL and MAPDEL and their register name, and CONV and its 2 units, since GetResult needs them on the same line.
+func macroSteps(body string) []string {
+	fields := strings.Fields(body)
+	steps := make([]string, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		args := 0
+		switch f {
+		case "LBL", "GTO", "MAPSTO", "MAPRCL", "MAPDEL":
+			args = 1
+		case "CONV":
+			args = 2
+		}
+		for ; args > 0 && i+1 < len(fields); args-- {
+			i++
+			f = f + " " + fields[i]
+		}
+		steps = append(steps, f)
+	}
+	return steps
+} // macroSteps
+
+// ------------------------------------------------------------ macroTest ----------------------------------------------------
+
+// macroTest -- returns the result of a conditional test step, and whether the step is a conditional test at all.
+func macroTest(step string) (bool, bool) {
+	x, y := Stack[X], Stack[Y]
+	switch step {
+	case "X=0?":
+		return x == 0, true
+	case "X#0?", "X!=0?":
+		return x != 0, true
+	case "X<0?":
+		return x < 0, true
+	case "X>0?":
+		return x > 0, true
+	case "X=Y?":
+		return x == y, true
+	case "X#Y?", "X!=Y?":
+		return x != y, true
+	case "X<Y?":
+		return x < y, true
+	case "X>Y?":
+		return x > y, true
+	case "X<=Y?":
+		return x <= y, true
+	case "X>=Y?":
+		return x >= y, true
+	}
+	return false, false
+} // macroTest
+
+// ------------------------------------------------------------ runMacro -----------------------------------------------------
+
+// runMacro -- replays the steps of a user program thru GetResult.  A conditional test that is false skips the next step, as does DSZ when I reaches zero.
+// A step that is a macro comes back here thru GetResult and Result, so macroDepth stops a macro that calls itself.
+func runMacro(name, body string) []string {
+	ss := make([]string, 0, 10)
+	if macroDepth >= maxMacroDepth {
+		ss = append(ss, fmt.Sprintf("macro %s nested more than %d deep.  Stopped.", name, maxMacroDepth))
+		return ss
+	}
+	macroDepth++
+	defer func() { macroDepth-- }()
+
+	steps := macroSteps(body)
+	labels := make(map[string]int)
+	for i, step := range steps {
+		if strings.HasPrefix(step, "LBL ") {
+			labels[step[4:]] = i
+		}
+	}
+
+	count := 0
+	for pc := 0; pc < len(steps); {
+		count++
+		if count > maxMacroSteps {
+			ss = append(ss, fmt.Sprintf("macro %s exceeded %d steps.  Stopped.", name, maxMacroSteps))
+			return ss
+		}
+		step := steps[pc]
+		pc++
+
+		if result, ok := macroTest(step); ok {
+			if !result {
+				pc++ // skip the next step
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(step, "LBL "): // only a marker
+		case strings.HasPrefix(step, "GTO "):
+			idx, ok := labels[step[4:]]
+			if !ok {
+				ss = append(ss, fmt.Sprintf("macro %s: label %s not found.  Stopped.", name, step[4:]))
+				return ss
+			}
+			pc = idx
+		case step == "RTN" || step == "STOP":
+			return ss
+		case step == "STOI":
+			macroIReg = Stack[X]
+		case step == "RCLI":
+			PushMatrixStacks()
+			PUSHX(macroIReg)
+		case step == "DSZ": // Decrement and Skip if Zero
+			macroIReg--
+			if macroIReg == 0 {
+				pc++
+			}
+		default:
+			_, stringslice := GetResult(step)
+			ss = append(ss, stringslice...)
+		}
+	}
+	return ss
+} // runMacro
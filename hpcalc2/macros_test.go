@@ -0,0 +1,38 @@
+package hpcalc2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMacros(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	SetCalcMode(FloatMode)
+
+	tests := []struct {
+		define string
+		run    string
+		want   float64
+	}{
+		{`define sq2 "2 sqrt sqr"`, "sq2", 2},
+		{`define keepit "mapsto kept 1 maprcl kept +"`, "5 keepit", 6}, // map commands only work thru GetResult.
+		{`define topa "conv psi kpa"`, "1 topa", 6.894757293168361},
+		{`define evalx "3 4 +"`, "evalx", 7}, // not taken as EVAL.
+		{`define again "again"`, "again", 7}, // stops at maxMacroDepth, w/ X unchanged.
+	}
+	for _, tc := range tests {
+		GetResult(tc.define)
+		r, ss := GetResult(tc.run)
+		if r < tc.want-1e-9 || r > tc.want+1e-9 {
+			t.Errorf("%s then %s: X = %g, want %g %q", tc.define, tc.run, r, tc.want, ss)
+		}
+	}
+
+	for _, name := range []string{"A+B", "MAPX", "R>P", "DSZ", "SIN", "2X", "EVAL"} {
+		GetResult("define " + name + ` "1"`)
+		if _, ok := isMacro(name); ok {
+			t.Errorf("define %s was accepted, but that name can't be run", name)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package hpcalc2
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"src/tknptr"
+	"strconv"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Programmer mode, like the HP-16C.  Each stack register holds a uint64 masked to the word size, which WSIZE sets to 8, 16, 32 or 64 bits.
+                 SIGNED and UNSIGNED set whether the top bit is a two's complement sign.  BIN, OCT, DEC and HEX set the display base.  HEX outside of PROG mode still just shows X in hex.
+                 AND, OR, XOR, SHL, SHR, ASR, ROL, ROR, SETBIT, CLRBIT and TESTBIT work on Y and X, where X is the bit count or bit number.  NOT and MASK work on X.
+                 0b, 0o and 0x literals come from tknptr.  The float64 Stack mirrors the registers as signed or unsigned integers, and what isn't done here falls thru to the float64 code.
+  18 Oct 26 -- A user program is run before the 3 character abbreviations are checked, as Result does.  And AND, NOT, ROL, DEC and the like need the exact name.
+*/
+
+const ProgrammerMode = ComplexMode + 1
+
+type progStackType [StackSize]uint64
+
+var progStack progStackType
+var progLastX uint64
+var progUndoMatrix []progStackType // parallel to StackUndoMatrix, like the big and complex stacks.
+var wordSize = 64
+var signedMode = true // two's complement, as the HP-16C defaults to.
+var displayBase = 16
+
+// ----------------------------------------------------------- progMask ---------------------------------------------------
+
+func progMask() uint64 {
+	if wordSize >= 64 {
+		return math.MaxUint64
+	}
+	return 1<<uint(wordSize) - 1
+}
+
+// ----------------------------------------------------------- progSigned -------------------------------------------------
+
+// progSigned -- returns v sign extended from the word size, for the two's complement interpretation.
+func progSigned(v uint64) int64 {
+	v &= progMask()
+	if wordSize < 64 && v&(1<<uint(wordSize-1)) != 0 {
+		v |= ^progMask()
+	}
+	return int64(v)
+}
+
+// ----------------------------------------------------------- progToFloat ------------------------------------------------
+
+func progToFloat(v uint64) float64 {
+	if signedMode {
+		return float64(progSigned(v))
+	}
+	return float64(v & progMask())
+}
+
+// ----------------------------------------------------------- floatToProg ------------------------------------------------
+
+// floatToProg -- truncates toward zero, and negative values become their two's complement.  Out of range values are saturated, as float64 can't hold the wrapped bits anyway.
+func floatToProg(r float64) uint64 {
+	r = math.Trunc(r)
+	switch {
+	case math.IsNaN(r):
+		return 0
+	case r >= math.MaxUint64:
+		return math.MaxUint64 & progMask()
+	case r >= math.MaxInt64:
+		return uint64(r) & progMask()
+	case r <= math.MinInt64:
+		return uint64(1<<63) & progMask()
+	}
+	return uint64(int64(r)) & progMask()
+}
+
+// ----------------------------------------------------------- progFillFromFloat ------------------------------------------
+
+// progFillFromFloat -- any register that no longer agrees w/ the float64 stack is set from it, like cplxFillFromFloat.
+func progFillFromFloat() {
+	if calcMode != ProgrammerMode {
+		return
+	}
+	for i := X; i < StackSize; i++ {
+		if progToFloat(progStack[i]) != Stack[i] {
+			progStack[i] = floatToProg(Stack[i])
+		}
+	}
+	if progToFloat(progLastX) != LastX {
+		progLastX = floatToProg(LastX)
+	}
+}
+
+// ----------------------------------------------------------- progToFloatStack -------------------------------------------
+
+func progToFloatStack() {
+	for i := X; i < StackSize; i++ {
+		progStack[i] &= progMask()
+		Stack[i] = progToFloat(progStack[i])
+	}
+	progLastX &= progMask()
+	LastX = progToFloat(progLastX)
+}
+
+// ----------------------------------------------------------- progStackUp, progStackDn -----------------------------------
+
+func progStackUp() {
+	for S := T2; S >= X; S-- {
+		progStack[S+1] = progStack[S]
+	}
+}
+
+func progStackDn() { // Does not affect X, same as STACKDN.
+	for S := Y; S < T1; S++ {
+		progStack[S] = progStack[S+1]
+	}
+}
+
+// ----------------------------------------------------------- ProgString -------------------------------------------------
+
+// ProgString -- formats v in the base, padded to the word size for BIN, OCT and HEX.  Binary is grouped by 4 bits w/ underscores, as Go allows in literals.
+func ProgString(v uint64, base int) string {
+	v &= progMask()
+	switch base {
+	case 2:
+		s := fmt.Sprintf("%0*b", wordSize, v)
+		groups := make([]string, 0, wordSize/4)
+		for i := 0; i < len(s); i += 4 {
+			groups = append(groups, s[i:i+4])
+		}
+		return "0b" + strings.Join(groups, "_")
+	case 8:
+		return "0o" + strconv.FormatUint(v, 8)
+	case 10:
+		if signedMode {
+			return strconv.FormatInt(progSigned(v), 10)
+		}
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("0x%0*X", wordSize/4, v)
+}
+
+// progModeString -- the word size, signedness and base, for messages and the dump.
+func progModeString() string {
+	sign := "unsigned"
+	if signedMode {
+		sign = "signed"
+	}
+	return fmt.Sprintf(" mode is PROG, %d bit %s, base %d", wordSize, sign, displayBase)
+}
+
+// ----------------------------------------------------------- dumpProgStack ----------------------------------------------
+
+// dumpProgStack -- the Dump functions call this when in programmer mode.  Each register shows the display base, and decimal if that's not the display base.
+func dumpProgStack() []string {
+	ss := make([]string, 0, StackSize+3)
+	ss = append(ss, HeaderDivider)
+	for SRN := T1; SRN >= X; SRN-- {
+		s := fmt.Sprintf("%2s: %s", StackRegNamesString[SRN], ProgString(progStack[SRN], displayBase))
+		if displayBase != 10 {
+			s += SpaceFiller + ProgString(progStack[SRN], 10)
+		}
+		ss = append(ss, s)
+	}
+	ss = append(ss, HeaderDivider)
+	ss = append(ss, progModeString())
+	return ss
+}
+
+// ----------------------------------------------------------- progTokenValue ---------------------------------------------
+
+// progTokenValue -- the value of a number token.  Decimal integers are parsed from the string, so all 64 bits are kept.
+func progTokenValue(tkn tknptr.TokenType) uint64 {
+	if tkn.HexFlag || tkn.BinFlag || tkn.OctFlag {
+		return uint64(tkn.Isum) & progMask() // Isum has the bits, even when the top bit makes it negative.
+	}
+	if !tkn.RealFlag {
+		if i, err := strconv.ParseInt(tkn.FullString, 10, 64); err == nil {
+			return uint64(i) & progMask()
+		}
+		if u, err := strconv.ParseUint(tkn.FullString, 10, 64); err == nil {
+			return u & progMask()
+		}
+	}
+	return floatToProg(tkn.Rsum)
+}
+
+// ----------------------------------------------------------- progResult -------------------------------------------------
+
+// progResult -- Result calls this first when in programmer mode.  It returns false for tokens it doesn't handle, and those fall thru to the float64 code.
+func progResult(tkn tknptr.TokenType) (bool, []string) {
+	ss := make([]string, 0, 10)
+	progFillFromFloat() // in case a front end changed the float64 stack directly.
+
+	binary := func(f func(y, x uint64) uint64) {
+		PushMatrixStacks()
+		progLastX = progStack[X]
+		progStack[X] = f(progStack[Y], progStack[X]) & progMask()
+		progStackDn()
+	}
+	unary := func(f func(x uint64) uint64) {
+		PushMatrixStacks()
+		progLastX = progStack[X]
+		progStack[X] = f(progStack[X]) & progMask()
+	}
+	shiftCount := func(x uint64) uint {
+		if x > uint64(wordSize) {
+			return uint(wordSize)
+		}
+		return uint(x)
+	}
+
+	switch tkn.State {
+	case tknptr.DGT:
+		PushMatrixStacks()
+		progStackUp()
+		progStack[X] = progTokenValue(tkn)
+
+	case tknptr.OP:
+		I := tkn.Isum
+		if (I == 6) || (I == 20) || (I == 1) || (I == 3) { // <>, ><, <, > will all SWAP
+			progStack[X], progStack[Y] = progStack[Y], progStack[X]
+			break
+		}
+		switch I {
+		case 5, 8:
+			binary(func(y, x uint64) uint64 { return y + x })
+		case 10:
+			binary(func(y, x uint64) uint64 { return y - x })
+		case 12:
+			binary(func(y, x uint64) uint64 { return y * x })
+		case 14:
+			if progStack[X]&progMask() == 0 {
+				ss = append(ss, " Divide by zero in PROG mode.  Command ignored.")
+				return true, ss
+			}
+			binary(func(y, x uint64) uint64 { // integer division truncates, like the 16C.
+				if signedMode {
+					return uint64(progSigned(y) / progSigned(x))
+				}
+				return (y & progMask()) / (x & progMask())
+			})
+		default:
+			return false, nil
+		}
+
+	case tknptr.ALLELSE:
+		cmdnum := cmdMap[tkn.Str]
+		if cmdnum == 0 {
+			if _, ok := isMacro(tkn.Str); ok {
+				return false, nil // Result runs the program, and its steps come back here.
+			}
+			cmdnum = abbrevCmdNum(tkn.Str)
+		}
+		switch cmdnum {
+		case 10, 20, 30: // DUMP, DUMPFIX, DUMPFLOAT
+			ss = append(ss, dumpProgStack()...)
+		case 160: // SWAP
+			PushMatrixStacks()
+			progStack[X], progStack[Y] = progStack[Y], progStack[X]
+		case 170: // LASTX
+			PushMatrixStacks()
+			progStackUp()
+			progStack[X] = progLastX
+		case 180: // ROLLDN
+			PushMatrixStacks()
+			temp := progStack[X]
+			progStack[X] = progStack[Y]
+			progStackDn()
+			progStack[T1] = temp
+		case 190: // UP
+			PushMatrixStacks()
+			progStackUp()
+		case 200: // DN
+			PushMatrixStacks()
+			progStack[X] = progStack[Y]
+			progStackDn()
+		case 210: // POP
+			PushMatrixStacks()
+			ss = append(ss, ProgString(progStack[X], displayBase))
+			progStack[X] = progStack[Y]
+			progStackDn()
+		case 270: // HEX
+			displayBase = 16
+			ss = append(ss, ProgString(progStack[X], displayBase))
+		case 370: // CHS, two's complement negation
+			unary(func(x uint64) uint64 { return -x })
+		case 1000: // AND
+			binary(func(y, x uint64) uint64 { return y & x })
+		case 1010: // OR
+			binary(func(y, x uint64) uint64 { return y | x })
+		case 1020: // XOR
+			binary(func(y, x uint64) uint64 { return y ^ x })
+		case 1030: // NOT
+			unary(func(x uint64) uint64 { return ^x })
+		case 1040: // SHL
+			binary(func(y, x uint64) uint64 { return y << shiftCount(x) })
+		case 1050: // SHR, logical
+			binary(func(y, x uint64) uint64 { return (y & progMask()) >> shiftCount(x) })
+		case 1055: // ASR, arithmetic so the sign bit is copied
+			binary(func(y, x uint64) uint64 { return uint64(progSigned(y) >> shiftCount(x)) })
+		case 1060, 1070: // ROL, ROR
+			binary(func(y, x uint64) uint64 {
+				n := int(x % uint64(wordSize))
+				if cmdnum == 1070 {
+					n = wordSize - n
+				}
+				y &= progMask()
+				if wordSize == 64 {
+					return bits.RotateLeft64(y, n)
+				}
+				return y<<uint(n) | y>>uint(wordSize-n)
+			})
+		case 1080, 1090, 1100: // SETBIT, CLRBIT, TESTBIT.  Bit number is in X, value in Y.
+			if progStack[X] >= uint64(wordSize) {
+				ss = append(ss, fmt.Sprintf(" bit %d is outside the %d bit word.  Command ignored.", progStack[X], wordSize))
+				break
+			}
+			bit := uint64(1) << progStack[X]
+			switch cmdnum {
+			case 1080:
+				binary(func(y, x uint64) uint64 { return y | bit })
+			case 1090:
+				binary(func(y, x uint64) uint64 { return y &^ bit })
+			case 1100:
+				n := progStack[X]
+				binary(func(y, x uint64) uint64 {
+					if y&bit != 0 {
+						return 1
+					}
+					return 0
+				})
+				ss = append(ss, fmt.Sprintf(" bit %d is %d", n, progStack[X]))
+			}
+		case 1110: // WSIZE, from X
+			ws := progStack[X]
+			if ws != 8 && ws != 16 && ws != 32 && ws != 64 {
+				ss = append(ss, " WSIZE needs 8, 16, 32 or 64 in X.  Command ignored.")
+				break
+			}
+			PushMatrixStacks()
+			progStack[X] = progStack[Y]
+			progStackDn()
+			wordSize = int(ws)
+			ss = append(ss, progModeString())
+		case 1120: // SIGNED
+			signedMode = true
+			ss = append(ss, progModeString())
+		case 1130: // UNSIGNED
+			signedMode = false
+			ss = append(ss, progModeString())
+		case 1140, 1150, 1160: // BIN, OCT, DEC
+			displayBase = map[int]int{1140: 2, 1150: 8, 1160: 10}[cmdnum]
+			ss = append(ss, ProgString(progStack[X], displayBase))
+		case 1170: // PROG
+			ss = append(ss, progModeString())
+		case 1180: // MASK, X low bits set, like MASKR on the 16C.
+			if progStack[X] > uint64(wordSize) {
+				ss = append(ss, fmt.Sprintf(" MASK needs 0 to %d in X.  Command ignored.", wordSize))
+				break
+			}
+			unary(func(x uint64) uint64 {
+				if x >= 64 {
+					return math.MaxUint64
+				}
+				return 1<<x - 1
+			})
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	progToFloatStack()
+	return true, ss
+} // progResult
@@ -0,0 +1,110 @@
+package hpcalc2
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useProgMode -- PROG mode w/ the default word size, signedness and base, which are put back when the test is done.
+func useProgMode(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	SetCalcMode(ProgrammerMode)
+	t.Cleanup(func() {
+		SetCalcMode(FloatMode)
+		wordSize, signedMode, displayBase = 64, true, 16
+	})
+}
+
+func TestProgMode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint64
+	}{
+		{"8 wsize 255 1 +", 0},  // wraps at the word size
+		{"0x1ff 8 wsize", 0xff}, // the rest of the stack is masked to the new word size
+		{"8 wsize 0x0f not", 0xf0},
+		{"16 wsize 0x0f not", 0xfff0},
+		{"16 wsize 5 mask", 0x1f},
+		{"0b1010 0b0110 and", 0b0010},
+		{"0b1010 0b0110 or", 0b1110},
+		{"0b1010 0b0110 xor", 0b1100},
+		{"8 wsize 0x80 1 shl", 0},
+		{"8 wsize 0x80 1 shr", 0x40},
+		{"8 wsize 0x80 1 asr", 0xc0}, // the sign bit is copied
+		{"8 wsize 0x40 1 asr", 0x20},
+		{"8 wsize 0x81 1 rol", 0x03},
+		{"8 wsize 0x81 1 ror", 0xc0},
+		{"8 wsize 0x81 9 rol", 0x03}, // the count is modulo the word size
+		{"0x8000000000000001 1 rol", 3},
+		{"0 3 setbit", 8},
+		{"15 0 clrbit", 14},
+		{"8 0 testbit", 0},
+		{"8 3 testbit", 1},
+		{"8 wsize 7 chs 2 /", 0xfd}, // -3 in two's complement, as signed division truncates toward zero
+		{"8 wsize unsigned 0xf9 2 /", 0x7c},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			useProgMode(t)
+			for _, s := range strings.Fields(tc.input) {
+				GetResult(s)
+			}
+			if progStack[X] != tc.want {
+				t.Errorf("%s: X = %#x, want %#x", tc.input, progStack[X], tc.want)
+			}
+		})
+	}
+}
+
+// TestProgFloatMirror -- the float64 stack has the registers as signed or unsigned integers.
+func TestProgFloatMirror(t *testing.T) {
+	useProgMode(t)
+	GetResult("8 wsize 0xff")
+	if Stack[X] != -1 {
+		t.Errorf("0xff signed in 8 bits: Stack[X] = %g, want -1", Stack[X])
+	}
+	GetResult("unsigned")
+	if Stack[X] != 255 {
+		t.Errorf("0xff unsigned in 8 bits: Stack[X] = %g, want 255", Stack[X])
+	}
+	_, ss := GetResult("0 /")
+	if len(ss) == 0 || progStack[X] != 0 || progStack[Y] != 0xff {
+		t.Errorf("divide by 0: X = %#x, Y = %#x, msg %q, want an error and the stack left alone", progStack[X], progStack[Y], ss)
+	}
+	GetResult("bin")
+	if got := ProgString(0xa5, displayBase); got != "0b1010_0101" {
+		t.Errorf("0xa5 in BIN: %s, want 0b1010_0101", got)
+	}
+}
+
+// TestProgExactNames -- the 3 character PROG commands need their exact name, so a longer word that starts w/ one isn't taken for it.
+func TestProgExactNames(t *testing.T) {
+	useProgMode(t)
+	SetCalcMode(FloatMode)
+	for _, word := range []string{"roll", "note", "decimal", "binary", "andy", "shlx", "octet", "rorx"} {
+		_, ss := GetResult(word)
+		if CalcMode() != FloatMode {
+			t.Errorf("%s switched to %s mode", word, CalcModeNames[CalcMode()])
+			SetCalcMode(FloatMode)
+		}
+		if len(ss) == 0 || !strings.Contains(ss[0], "unrecognized") {
+			t.Errorf("%s gave %q, want unrecognized", word, ss)
+		}
+	}
+	GetResult("rol")
+	if CalcMode() != ProgrammerMode {
+		t.Errorf("rol didn't switch to PROG mode")
+	}
+}
+
+// TestProgMacro -- a user program is run before the 3 character abbreviations in PROG mode too, so popx isn't POP.
+func TestProgMacro(t *testing.T) {
+	useProgMode(t)
+	GetResult(`define popx "2 +"`)
+	GetResult("5 popx")
+	if progStack[X] != 7 {
+		t.Errorf("5 popx: X = %d, want 7", progStack[X])
+	}
+}
@@ -0,0 +1,268 @@
+package hpcalc2
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Statistics registers, like the Σ+ key on the old HP calculators.  Σ+ accumulates x from X and y from Y into n, Σx, Σy, Σx², Σy² and Σxy, and Σ- takes them back out.
+                 MEAN, SDEV, LR, CORR, YHAT and XHAT work from the accumulators.  The accumulators are part of the undo snapshot, and are saved in the mapped register file
+                 under names that begin w/ Σ, so mapsho shows them and they persist across front ends.
+                 The tokenizer works on bytes, and Σ isn't ASCII, so Σ+, Σ-, SIGMA+ and SIGMA- are changed to SUMPLUS and SUMMINUS in GetResult.
+  18 Oct 26 -- Undoing past the first Σ+ wrote the empty accumulators of a snapshot taken before they were read from the file, which deleted the saved points.
+                 Now the snapshot remembers if the accumulators had been loaded, and a snapshot from before that is not written back.
+*/
+
+type StatsRegType struct {
+	N, SumX, SumY, SumX2, SumY2, SumXY float64
+}
+
+// statsSnapshotType is what the undo matrix keeps.  Until loaded, statsReg is only the zero value, not what's in the mapped register file.
+type statsSnapshotType struct {
+	reg    StatsRegType
+	loaded bool
+}
+
+var statsReg StatsRegType
+var statsLoaded bool                    // statsReg has been read from the mapped register file at least once.
+var statsUndoMatrix []statsSnapshotType // parallel to StackUndoMatrix.
+
+var statsRegNames = []string{"Σn", "Σx", "Σy", "Σx2", "Σy2", "Σxy"} // names in the mapped register file.
+
+var sigmaReplacer = strings.NewReplacer("Σ+", " SUMPLUS ", "Σ-", " SUMMINUS ", "SIGMA+", " SUMPLUS ", "SIGMA-", " SUMMINUS ",
+	"sigma+", " SUMPLUS ", "sigma-", " SUMMINUS ", "Sigma+", " SUMPLUS ", "Sigma-", " SUMMINUS ")
+
+// ----------------------------------------------------------- StatsRegs --------------------------------------------------
+
+// StatsRegs -- returns the statistics accumulators, read fresh from the mapped register file.
+func StatsRegs() StatsRegType {
+	statsLoad()
+	return statsReg
+}
+
+// ----------------------------------------------------------- statsFields ------------------------------------------------
+
+// statsFields -- pointers to the accumulators in the same order as statsRegNames, so loading and saving is a loop.
+func statsFields(s *StatsRegType) []*float64 {
+	return []*float64{&s.N, &s.SumX, &s.SumY, &s.SumX2, &s.SumY2, &s.SumXY}
+}
+
+// ----------------------------------------------------------- readMappedReg ----------------------------------------------
+
+// readMappedReg -- reads a fresh copy of the mapped register file, the same way the map commands do.
+func readMappedReg() {
+	mappedRegFile, err := os.Open(fullMappedRegFilename)
+	if err != nil {
+		mappedRegExists = false
+		if mappedReg == nil {
+			mappedReg = make(map[string]float64, 100)
+		}
+		return
+	}
+	mappedRegExists = true
+	mappedReg = nil
+	decoder := gob.NewDecoder(mappedRegFile)
+	err = decoder.Decode(&mappedReg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	mappedRegFile.Close() // closed now, not deferred, since the file may be written right after this.
+	if mappedReg == nil {
+		mappedReg = make(map[string]float64, 100)
+	}
+}
+
+// ----------------------------------------------------------- statsLoad --------------------------------------------------
+
+func statsLoad() {
+	readMappedReg()
+	statsReg = StatsRegType{}
+	for i, p := range statsFields(&statsReg) {
+		*p = mappedReg[statsRegNames[i]]
+	}
+	statsLoaded = true
+}
+
+// ----------------------------------------------------------- statsSave --------------------------------------------------
+
+// statsSave -- writes the accumulators into the mapped register file.  Cleared accumulators are removed from the file, so mapsho isn't cluttered.
+func statsSave() {
+	readMappedReg()
+	for i, p := range statsFields(&statsReg) {
+		if statsReg.N == 0 {
+			delete(mappedReg, statsRegNames[i])
+		} else {
+			mappedReg[statsRegNames[i]] = *p
+		}
+	}
+	MapWriteAndClose()
+}
+
+// ----------------------------------------------------------- undoStatsReg -----------------------------------------------
+
+// statsSnapshot -- what PushMatrixStacks and UndoMatrixStacks append to statsUndoMatrix.
+func statsSnapshot() statsSnapshotType {
+	return statsSnapshotType{reg: statsReg, loaded: statsLoaded}
+}
+
+// undoStatsReg -- UNDO and REDO call this.  The file is only written if the accumulators actually changed, so most undo's don't touch it.
+// A snapshot from before the accumulators were loaded doesn't know what's in the file, so it's not restored.
+func undoStatsReg(saved statsSnapshotType) {
+	if !saved.loaded || saved.reg == statsReg {
+		return
+	}
+	statsReg = saved.reg
+	statsSave()
+}
+
+// ----------------------------------------------------------- statsAccumulate --------------------------------------------
+
+// statsAccumulate -- sign is +1 for Σ+ and -1 for Σ-.  Afterwards n is in X, as on the HP-25.
+func statsAccumulate(sign float64) string {
+	statsLoad()
+	PushMatrixStacks()
+	x, y := Stack[X], Stack[Y]
+	statsReg.N += sign
+	statsReg.SumX += sign * x
+	statsReg.SumY += sign * y
+	statsReg.SumX2 += sign * x * x
+	statsReg.SumY2 += sign * y * y
+	statsReg.SumXY += sign * x * y
+	if statsReg.N <= 0 { // removed more than was added, so start over.
+		statsReg = StatsRegType{}
+	}
+	statsSave()
+	LastX = x
+	Stack[X] = statsReg.N
+	return fmt.Sprintf(" n = %g", statsReg.N)
+}
+
+// ----------------------------------------------------------- statsLR ----------------------------------------------------
+
+// statsLR -- returns the intercept and slope of the least squares line thru the accumulated points.
+func statsLR() (float64, float64, error) {
+	n := statsReg.N
+	denom := n*statsReg.SumX2 - statsReg.SumX*statsReg.SumX
+	if n < 2 || denom == 0 {
+		return 0, 0, fmt.Errorf("need at least 2 points w/ different x values")
+	}
+	slope := (n*statsReg.SumXY - statsReg.SumX*statsReg.SumY) / denom
+	intercept := (statsReg.SumY - slope*statsReg.SumX) / n
+	return intercept, slope, nil
+}
+
+// ----------------------------------------------------------- statsResult ------------------------------------------------
+
+// statsResult -- does the statistics commands, which are cmdMap numbers 830 thru 910.
+func statsResult(cmdnum int) []string {
+	ss := make([]string, 0, 10)
+	formatFloat := func(r float64) string {
+		return strconv.FormatFloat(r, 'g', sigfig, 64)
+	}
+
+	switch cmdnum {
+	case 830: // SUMPLUS
+		ss = append(ss, statsAccumulate(1))
+		return ss
+	case 840: // SUMMINUS
+		ss = append(ss, statsAccumulate(-1))
+		return ss
+	case 850: // CLSUM
+		PushMatrixStacks()
+		statsReg = StatsRegType{}
+		statsSave()
+		ss = append(ss, " statistics registers cleared.")
+		return ss
+	}
+
+	statsLoad()
+	n := statsReg.N
+	if n < 1 {
+		ss = append(ss, " No points have been accumulated w/ Σ+.  Command ignored.")
+		return ss
+	}
+
+	switch cmdnum {
+	case 860: // MEAN, x mean in X and y mean in Y
+		PushMatrixStacks()
+		LastX = Stack[X]
+		STACKUP()
+		STACKUP()
+		Stack[X] = statsReg.SumX / n
+		Stack[Y] = statsReg.SumY / n
+		ss = append(ss, fmt.Sprintf(" mean x = %s, mean y = %s", formatFloat(Stack[X]), formatFloat(Stack[Y])))
+	case 870: // SDEV, sample standard deviations, sx in X and sy in Y
+		if n < 2 {
+			ss = append(ss, " SDEV needs at least 2 points.  Command ignored.")
+			break
+		}
+		PushMatrixStacks()
+		LastX = Stack[X]
+		STACKUP()
+		STACKUP()
+		Stack[X] = math.Sqrt(math.Max(0, (statsReg.SumX2-statsReg.SumX*statsReg.SumX/n)/(n-1)))
+		Stack[Y] = math.Sqrt(math.Max(0, (statsReg.SumY2-statsReg.SumY*statsReg.SumY/n)/(n-1)))
+		ss = append(ss, fmt.Sprintf(" sx = %s, sy = %s", formatFloat(Stack[X]), formatFloat(Stack[Y])))
+	case 880: // LR, intercept in X and slope in Y, as on the HP-25
+		intercept, slope, err := statsLR()
+		if err != nil {
+			ss = append(ss, fmt.Sprintf(" LR %s.  Command ignored.", err))
+			break
+		}
+		PushMatrixStacks()
+		LastX = Stack[X]
+		STACKUP()
+		STACKUP()
+		Stack[X] = intercept
+		Stack[Y] = slope
+		ss = append(ss, fmt.Sprintf(" y = %s + %s x", formatFloat(intercept), formatFloat(slope)))
+	case 890: // CORR
+		sxx := n*statsReg.SumX2 - statsReg.SumX*statsReg.SumX
+		syy := n*statsReg.SumY2 - statsReg.SumY*statsReg.SumY
+		if n < 2 || sxx <= 0 || syy <= 0 {
+			ss = append(ss, " CORR needs at least 2 points, and x and y can't be constant.  Command ignored.")
+			break
+		}
+		PushMatrixStacks()
+		LastX = Stack[X]
+		STACKUP()
+		Stack[X] = (n*statsReg.SumXY - statsReg.SumX*statsReg.SumY) / math.Sqrt(sxx*syy)
+		ss = append(ss, fmt.Sprintf(" r = %s", formatFloat(Stack[X])))
+	case 900, 905: // YHAT estimates y from x in X, XHAT estimates x from y in X.
+		intercept, slope, err := statsLR()
+		if err != nil {
+			ss = append(ss, fmt.Sprintf(" %s %s.  Command ignored.", statsCmdName(cmdnum), err))
+			break
+		}
+		if cmdnum == 905 && slope == 0 {
+			ss = append(ss, " XHAT can't estimate x when the slope is zero.  Command ignored.")
+			break
+		}
+		PushMatrixStacks()
+		LastX = Stack[X]
+		if cmdnum == 900 {
+			Stack[X] = intercept + slope*LastX
+		} else {
+			Stack[X] = (LastX - intercept) / slope
+		}
+	case 910: // SUMSHO
+		for i, p := range statsFields(&statsReg) {
+			ss = append(ss, fmt.Sprintf(" %s = %s", statsRegNames[i], formatFloat(*p)))
+		}
+	}
+	return ss
+} // statsResult
+
+func statsCmdName(cmdnum int) string {
+	if cmdnum == 900 {
+		return "YHAT"
+	}
+	return "XHAT"
+}
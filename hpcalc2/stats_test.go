@@ -0,0 +1,28 @@
+package hpcalc2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestUndoPastFirstLoad -- undoing past the first Σ+ used to write the empty accumulators of the snapshots taken before the file was read.
+func TestUndoPastFirstLoad(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	mappedReg = map[string]float64{"Σn": 3, "Σx": 6, "Σy": 12, "Σx2": 14, "Σy2": 56, "Σxy": 28}
+	MapWriteAndClose()
+	statsReg, statsLoaded = StatsRegType{}, false
+	StackUndoMatrix, bigUndoMatrix, ratUndoMatrix, cplxUndoMatrix, progUndoMatrix, statsUndoMatrix = nil, nil, nil, nil, nil, nil
+	CurUndoRedoIdx = 0
+
+	GetResult("7")
+	GetResult("5 10 sigma+")
+	if n := StatsRegs().N; n != 4 {
+		t.Fatalf("after sigma+, n = %g, want 4", n)
+	}
+	for range 4 {
+		GetResult("undo")
+	}
+	if got := StatsRegs(); got.N != 3 || got.SumX != 6 || got.SumXY != 28 {
+		t.Errorf("after undo past the first load, stats = %+v, want the 3 saved points", got)
+	}
+}
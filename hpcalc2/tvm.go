@@ -0,0 +1,336 @@
+package hpcalc2
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Time value of money, like the HP-12C.  N, IR, PV, PMT and FV store X into their register, and SOLVEN, SOLVEIR, SOLVEPV, SOLVEPMT and SOLVEFV compute that register
+                 from the other four and push it.  IR is the percent per period, so 6% a year paid monthly is 6 12 / IR.  I couldn't use I, as that's the imaginary unit in COMPLEX mode.
+                 The sign convention is the HP cash flow convention, money received is positive and money paid out is negative.  TVMBEG and TVMEND set when payments are made.
+                 Everything is closed form except the rate, which is Newton's method w/ a bisection fallback.
+                 AMORT returns the amortization schedule.  NPV and IRR use cash flows in the mapped registers named CF0, CF1, CF2 ..., like the CFj key on the 12C.
+  18 Oct 26 -- SOLVEN is an error when N comes out zero or negative, which it did when the cash flows were all the same sign.
+*/
+
+const tvmMaxIterations = 200
+const tvmTolerance = 1e-12
+const amortMaxPeriods = 1200 // 100 years of monthly payments is plenty of output.
+
+type TVMType struct {
+	N, IR, PV, PMT, FV float64 // IR is percent per period
+	Begin              bool    // payments at the beginning of the period, else at the end
+}
+
+var tvm TVMType
+
+var cfRegex = regexp.MustCompile(`^CF([0-9]+)$`)
+
+// ----------------------------------------------------------- TVMRegs ----------------------------------------------------
+
+// TVMRegs -- returns the TVM registers, so a front end can show them.
+func TVMRegs() TVMType {
+	return tvm
+}
+
+// ----------------------------------------------------------- tvmFunc ----------------------------------------------------
+
+// tvmFunc -- the TVM equation, which is zero when the registers agree.  i is the rate per period as a fraction, not percent.
+func tvmFunc(t TVMType, i float64) float64 {
+	if math.Abs(i) < 1e-10 {
+		return t.PV + t.PMT*t.N + t.FV
+	}
+	b := 0.0
+	if t.Begin {
+		b = 1
+	}
+	compound := math.Pow(1+i, t.N)
+	return t.PV*compound + t.PMT*(1+i*b)*(compound-1)/i + t.FV
+}
+
+// ----------------------------------------------------------- solveRate --------------------------------------------------
+
+// solveRate -- finds a root of f between -100% and a large rate.  Tries Newton's method from a few starting points, then bisection over a scan for a sign change.
+func solveRate(f func(float64) float64) (float64, error) {
+	for _, guess := range []float64{0.1, 0.01, 0.5, -0.05} {
+		i := guess
+		for iter := 0; iter < tvmMaxIterations; iter++ {
+			h := math.Max(1e-7, math.Abs(i)*1e-7)
+			fi := f(i)
+			deriv := (f(i+h) - f(i-h)) / (2 * h)
+			if deriv == 0 || math.IsNaN(deriv) || math.IsInf(deriv, 0) {
+				break
+			}
+			next := i - fi/deriv
+			if next <= -1 || math.IsNaN(next) {
+				break
+			}
+			if math.Abs(next-i) < tvmTolerance*math.Max(1, math.Abs(next)) {
+				return next, nil
+			}
+			i = next
+		}
+	}
+
+	lo := -0.99
+	flo := f(lo)
+	for hi := lo + 0.01; hi <= 10; hi += 0.01 {
+		fhi := f(hi)
+		if math.Signbit(flo) != math.Signbit(fhi) {
+			for iter := 0; iter < tvmMaxIterations; iter++ {
+				mid := (lo + hi) / 2
+				fmid := f(mid)
+				if math.Signbit(flo) == math.Signbit(fmid) {
+					lo, flo = mid, fmid
+				} else {
+					hi = mid
+				}
+			}
+			return (lo + hi) / 2, nil
+		}
+		lo, flo = hi, fhi
+	}
+	return 0, fmt.Errorf("no rate found.  Check the signs of the cash flows")
+} // solveRate
+
+// ----------------------------------------------------------- tvmSolve ---------------------------------------------------
+
+// tvmSolve -- solves for one register from the other four.  which is the cmdMap number of the register, 920 thru 928.
+func tvmSolve(which int) (float64, error) {
+	t := tvm
+	i := t.IR / 100
+	b := 0.0
+	if t.Begin {
+		b = 1
+	}
+	zeroRate := math.Abs(i) < 1e-10
+
+	switch which {
+	case 920: // N
+		if zeroRate {
+			if t.PMT == 0 {
+				return 0, fmt.Errorf("N needs a nonzero PMT when IR is zero")
+			}
+			if n := -(t.PV + t.FV) / t.PMT; n > 0 {
+				return n, nil
+			}
+			return 0, fmt.Errorf("no N solves this.  Check the signs of PV, PMT and FV")
+		}
+		a := t.PMT * (1 + i*b) / i
+		ratio := (a - t.FV) / (a + t.PV)
+		if ratio <= 0 || (a+t.PV) == 0 {
+			return 0, fmt.Errorf("no N solves this.  Check the signs of PV, PMT and FV")
+		}
+		n := math.Log(ratio) / math.Log(1+i)
+		if n <= 0 { // as when the cash flows are all the same sign.
+			return 0, fmt.Errorf("no N solves this.  Check the signs of PV, PMT and FV")
+		}
+		return n, nil
+
+	case 922: // IR
+		if t.N <= 0 {
+			return 0, fmt.Errorf("IR needs N > 0")
+		}
+		r, err := solveRate(func(x float64) float64 { return tvmFunc(t, x) })
+		return r * 100, err
+
+	case 924: // PV
+		if zeroRate {
+			return -(t.FV + t.PMT*t.N), nil
+		}
+		discount := math.Pow(1+i, -t.N)
+		return -(t.FV*discount + t.PMT*(1+i*b)*(1-discount)/i), nil
+
+	case 926: // PMT
+		if t.N == 0 {
+			return 0, fmt.Errorf("PMT needs N > 0")
+		}
+		if zeroRate {
+			return -(t.PV + t.FV) / t.N, nil
+		}
+		compound := math.Pow(1+i, t.N)
+		return -(t.PV*compound + t.FV) * i / ((1 + i*b) * (compound - 1)), nil
+
+	case 928: // FV
+		if zeroRate {
+			return -(t.PV + t.PMT*t.N), nil
+		}
+		compound := math.Pow(1+i, t.N)
+		return -(t.PV*compound + t.PMT*(1+i*b)*(compound-1)/i), nil
+	}
+	return 0, fmt.Errorf("not a TVM register")
+} // tvmSolve
+
+// ----------------------------------------------------------- tvmRegName ------------------------------------------------
+
+func tvmRegName(which int) string {
+	return map[int]string{920: "N", 922: "IR", 924: "PV", 926: "PMT", 928: "FV"}[which]
+}
+
+func tvmRegPtr(which int) *float64 {
+	switch which {
+	case 920:
+		return &tvm.N
+	case 922:
+		return &tvm.IR
+	case 924:
+		return &tvm.PV
+	case 926:
+		return &tvm.PMT
+	case 928:
+		return &tvm.FV
+	}
+	return nil
+}
+
+// ----------------------------------------------------------- amortSchedule ----------------------------------------------
+
+// amortSchedule -- returns one line per period of the payment, interest, principal and remaining balance, starting from PV.
+func amortSchedule() []string {
+	ss := make([]string, 0, 50)
+	periods := int(math.Ceil(tvm.N - 1e-9))
+	if periods <= 0 {
+		ss = append(ss, " AMORT needs N > 0.  Command ignored.")
+		return ss
+	}
+	if periods > amortMaxPeriods {
+		ss = append(ss, fmt.Sprintf(" AMORT only shows the first %d periods.", amortMaxPeriods))
+		periods = amortMaxPeriods
+	}
+	i := tvm.IR / 100
+	balance := tvm.PV
+	var totalInterest float64
+	ss = append(ss, fmt.Sprintf(" %6s %14s %14s %14s %16s", "period", "payment", "interest", "principal", "balance"))
+	for p := 1; p <= periods; p++ {
+		var interest float64
+		if tvm.Begin {
+			balance += tvm.PMT
+			interest = balance * i
+			balance += interest
+		} else {
+			interest = balance * i
+			balance += interest + tvm.PMT
+		}
+		principal := -tvm.PMT - interest
+		totalInterest += interest
+		ss = append(ss, fmt.Sprintf(" %6d %14.2f %14.2f %14.2f %16.2f", p, tvm.PMT, interest, principal, balance))
+	}
+	ss = append(ss, fmt.Sprintf(" total interest is %.2f", totalInterest))
+	return ss
+} // amortSchedule
+
+// ----------------------------------------------------------- cashFlows --------------------------------------------------
+
+// cashFlows -- returns the mapped registers CF0, CF1 ... in order.  Missing ones in between are zero, as when a period has no cash flow.
+func cashFlows() []float64 {
+	readMappedReg()
+	found := make(map[int]float64)
+	indices := make([]int, 0, 10)
+	for name, value := range mappedReg {
+		m := cfRegex.FindStringSubmatch(strings.ToUpper(name))
+		if m == nil {
+			continue
+		}
+		j, err := strconv.Atoi(m[1])
+		if err != nil || j > amortMaxPeriods {
+			continue
+		}
+		found[j] = value
+		indices = append(indices, j)
+	}
+	if len(indices) == 0 {
+		return nil
+	}
+	sort.Ints(indices)
+	flows := make([]float64, indices[len(indices)-1]+1)
+	for j, value := range found {
+		flows[j] = value
+	}
+	return flows
+} // cashFlows
+
+func npv(flows []float64, i float64) float64 {
+	var sum float64
+	for j, cf := range flows {
+		sum += cf / math.Pow(1+i, float64(j))
+	}
+	return sum
+}
+
+// ----------------------------------------------------------- tvmResult --------------------------------------------------
+
+// tvmResult -- does the TVM commands, which are cmdMap numbers 920 thru 970.
+func tvmResult(cmdnum int) []string {
+	ss := make([]string, 0, 10)
+	formatFloat := func(r float64) string {
+		return strconv.FormatFloat(r, 'g', sigfig, 64)
+	}
+
+	switch cmdnum {
+	case 920, 922, 924, 926, 928: // N, IR, PV, PMT, FV store X
+		*tvmRegPtr(cmdnum) = Stack[X]
+		ss = append(ss, fmt.Sprintf(" %s = %s", tvmRegName(cmdnum), formatFloat(Stack[X])))
+	case 930, 932, 934, 936, 938: // SOLVEN thru SOLVEFV
+		which := cmdnum - 10
+		r, err := tvmSolve(which)
+		if err != nil || math.IsNaN(r) || math.IsInf(r, 0) {
+			if err == nil {
+				err = fmt.Errorf("%s has no finite solution", tvmRegName(which))
+			}
+			ss = append(ss, fmt.Sprintf(" %s.  Command ignored.", err))
+			break
+		}
+		*tvmRegPtr(which) = r
+		PushMatrixStacks()
+		PUSHX(r)
+		ss = append(ss, fmt.Sprintf(" %s = %s", tvmRegName(which), formatFloat(r)))
+	case 940: // TVMBEG
+		tvm.Begin = true
+		ss = append(ss, " payments are at the beginning of each period.")
+	case 942: // TVMEND
+		tvm.Begin = false
+		ss = append(ss, " payments are at the end of each period.")
+	case 944: // TVMSHO
+		when := "END"
+		if tvm.Begin {
+			when = "BEGIN"
+		}
+		ss = append(ss, fmt.Sprintf(" N = %s, IR = %s%%, PV = %s, PMT = %s, FV = %s, %s mode", formatFloat(tvm.N), formatFloat(tvm.IR),
+			formatFloat(tvm.PV), formatFloat(tvm.PMT), formatFloat(tvm.FV), when))
+	case 946: // CLTVM
+		tvm = TVMType{}
+		ss = append(ss, " TVM registers cleared.")
+	case 950: // AMORT
+		ss = append(ss, amortSchedule()...)
+	case 960, 970: // NPV, IRR
+		flows := cashFlows()
+		if len(flows) == 0 {
+			ss = append(ss, " No cash flows found.  Use mapsto CF0, mapsto CF1, etc.  Command ignored.")
+			break
+		}
+		var r float64
+		if cmdnum == 960 {
+			r = npv(flows, tvm.IR/100)
+			ss = append(ss, fmt.Sprintf(" NPV of %d cash flows at %s%% is %s", len(flows), formatFloat(tvm.IR), formatFloat(r)))
+		} else {
+			rate, err := solveRate(func(x float64) float64 { return npv(flows, x) })
+			if err != nil {
+				ss = append(ss, fmt.Sprintf(" IRR %s.  Command ignored.", err))
+				break
+			}
+			r = rate * 100
+			tvm.IR = r // like the 12C, so NPV right after IRR is zero.
+			ss = append(ss, fmt.Sprintf(" IRR of %d cash flows is %s%%", len(flows), formatFloat(r)))
+		}
+		PushMatrixStacks()
+		PUSHX(r)
+	}
+	return ss
+} // tvmResult
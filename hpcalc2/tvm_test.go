@@ -0,0 +1,88 @@
+package hpcalc2
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTVMSolve(t *testing.T) {
+	defer func() { tvm = TVMType{} }()
+
+	const pmt = -1199.1010503055138 // a 30 year mortgage of 200,000 at 6%
+	tests := []struct {
+		name    string
+		regs    TVMType
+		which   int
+		want    float64
+		wantErr bool
+	}{
+		{name: "mortgage PMT", regs: TVMType{N: 360, IR: 0.5, PV: 200000}, which: 926, want: pmt},
+		{name: "mortgage N", regs: TVMType{IR: 0.5, PV: 200000, PMT: pmt}, which: 920, want: 360},
+		{name: "mortgage IR", regs: TVMType{N: 360, PV: 200000, PMT: pmt}, which: 922, want: 0.5},
+		{name: "mortgage PV", regs: TVMType{N: 360, IR: 0.5, PMT: pmt}, which: 924, want: 200000},
+		{name: "mortgage FV", regs: TVMType{N: 360, IR: 0.5, PV: 200000, PMT: pmt}, which: 928, want: 0},
+		{name: "compound FV", regs: TVMType{N: 10, IR: 5, PV: -1000}, which: 928, want: 1628.894626777442},
+		{name: "compound IR", regs: TVMType{N: 10, PV: -1000, FV: 1628.894626777442}, which: 922, want: 5},
+		{name: "compound N", regs: TVMType{IR: 5, PV: -1000, FV: 1628.894626777442}, which: 920, want: 10},
+		{name: "annuity PV", regs: TVMType{N: 10, IR: 5, PMT: -100}, which: 924, want: 772.1734929184818},
+		{name: "annuity due PV", regs: TVMType{N: 10, IR: 5, PMT: -100, Begin: true}, which: 924, want: 810.7821675644059},
+		{name: "annuity due PMT", regs: TVMType{N: 10, IR: 5, PV: 810.7821675644059, Begin: true}, which: 926, want: -100},
+		{name: "savings FV", regs: TVMType{N: 10, IR: 5, PV: -1000, PMT: -100}, which: 928, want: 2886.683880332326},
+
+		{name: "I=0 PMT", regs: TVMType{N: 12, PV: 1200}, which: 926, want: -100},
+		{name: "I=0 N", regs: TVMType{PV: 1200, PMT: -100}, which: 920, want: 12},
+		{name: "I=0 PV", regs: TVMType{N: 5, PMT: -100}, which: 924, want: 500},
+		{name: "I=0 FV", regs: TVMType{N: 5, PV: -1000, PMT: -100}, which: 928, want: 1500},
+		{name: "I=0 IR", regs: TVMType{N: 12, PV: 1200, PMT: -100}, which: 922, want: 0},
+
+		{name: "N w/ the cash flows all one sign", regs: TVMType{IR: 5, PV: 100, PMT: 10, FV: 10}, which: 920, wantErr: true},
+		{name: "N w/ a payment that only pays the interest", regs: TVMType{IR: 1, PV: 1000, PMT: -10}, which: 920, wantErr: true},
+		{name: "I=0 N w/ the cash flows all one sign", regs: TVMType{PV: 1200, PMT: 100}, which: 920, wantErr: true},
+		{name: "I=0 N w/o a payment", regs: TVMType{PV: 1000, FV: -1000}, which: 920, wantErr: true},
+		{name: "IR w/ the cash flows all one sign", regs: TVMType{N: 10, PV: 1000, PMT: 100, FV: 50}, which: 922, wantErr: true},
+		{name: "IR w/o N", regs: TVMType{PV: 1000, PMT: -100}, which: 922, wantErr: true},
+		{name: "PMT w/o N", regs: TVMType{IR: 5, PV: 1000}, which: 926, wantErr: true},
+	}
+	for _, tc := range tests {
+		tvm = tc.regs
+		got, err := tvmSolve(tc.which)
+		if tc.wantErr {
+			if err == nil && !math.IsNaN(got) && !math.IsInf(got, 0) {
+				t.Errorf("%s: %s = %g, want no solution", tc.name, tvmRegName(tc.which), got)
+			}
+			continue
+		}
+		if err != nil || math.Abs(got-tc.want) > 1e-6*math.Max(1, math.Abs(tc.want)) {
+			t.Errorf("%s: %s = %.10g, %v, want %.10g", tc.name, tvmRegName(tc.which), got, err, tc.want)
+		}
+	}
+}
+
+// TestTVMCommands -- the register and solve commands thru GetResult.  A solve pushes its answer, and no solution leaves the stack alone.
+func TestTVMCommands(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	defer func() { tvm = TVMType{} }()
+
+	GetResult("cltvm tvmend 360 n 6 12 / ir 200000 pv 0 fv 0 pmt")
+	GetResult("solvepmt")
+	if math.Abs(Stack[X]+1199.1010503055138) > 1e-6 || tvm.PMT != Stack[X] || Stack[Y] != 0 {
+		t.Errorf("solvepmt: X = %g, PMT = %g and Y = %g, want -1199.10 in both and 0 pushed up", Stack[X], tvm.PMT, Stack[Y])
+	}
+	GetResult("0 ir solvepmt")
+	if math.Abs(Stack[X]+200000.0/360) > 1e-9 {
+		t.Errorf("solvepmt at 0%%: X = %g, want %g", Stack[X], -200000.0/360)
+	}
+
+	GetResult("cltvm 5 ir 100 pv 10 pmt 10 fv 1 2 3")
+	_, ss := GetResult("solven")
+	if len(ss) == 0 || !strings.Contains(ss[len(ss)-1], "ignored") || Stack[X] != 3 || Stack[Y] != 2 || tvm.N != 0 {
+		t.Errorf("solven w/ no solution: X = %g, Y = %g and N = %g, msg %q, want an error and the stack left alone", Stack[X], Stack[Y], tvm.N, ss)
+	}
+	_, ss = GetResult("10 n solveir")
+	if len(ss) == 0 || !strings.Contains(ss[len(ss)-1], "ignored") || Stack[X] != 10 || tvm.IR != 5 {
+		t.Errorf("solveir w/ no solution: X = %g and IR = %g, msg %q, want an error and the stack left alone", Stack[X], tvm.IR, ss)
+	}
+}
@@ -0,0 +1,300 @@
+package hpcalc2
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Unit conversion table, instead of a cmdMap entry and switch case for each conversion.  CONV psi kpa converts X in place, saving LastX and pushing the undo stack.
+                 Each unit is a factor to the base unit of its category, and an offset that only temperatures need.
+                 User conversions are read from hpcalc2units.txt in the home directory, one unit per line as name category factor [offset], where factor converts 1 unit to the base.
+                 Factors and offsets are big.Rat, so the conversions are exact, and a factor can be written as a fraction like 5/9.
+                 LB2G, OZ2G, CM2IN, M2FT, MI2KM, C2F, F2C and the rest are now aliases into the table.  They still push their result, as they've done since Jan 2021.
+                 M2FT and FT2M now use the exact 0.3048 m per ft, instead of 3.28084 ft per m.
+  18 Oct 26 -- CONV and the aliases set X thru the stack of the current mode, so the complex, big and PROG stacks agree w/ the float64 stack.
+                 An X w/ an imaginary part, and an X that's Inf or NaN, can't be converted, instead of converting the real part or converting 0.
+*/
+
+const userUnitsFilename = "hpcalc2units.txt"
+
+type unitType struct {
+	category string
+	factor   *big.Rat // multiply by this to get the base unit of the category
+	offset   *big.Rat // added after the factor.  Only temperatures need it.
+}
+
+type convAliasType struct { // the old conversion commands, now done thru the table.
+	from, to string
+}
+
+var fullUserUnitsFilename string
+
+// The factors are strings so they're exact as big.Rat, and 100 C is 212 F, not 211.99999999999991.  Base units are m, g, L, K, Pa, J and s.
+var builtinUnits = [][4]string{ // name, category, factor, offset
+	{"m", "length", "1", "0"},
+	{"km", "length", "1000", "0"},
+	{"cm", "length", "0.01", "0"},
+	{"mm", "length", "0.001", "0"},
+	{"um", "length", "1e-6", "0"},
+	{"nm", "length", "1e-9", "0"},
+	{"in", "length", "0.0254", "0"},
+	{"ft", "length", "0.3048", "0"},
+	{"yd", "length", "0.9144", "0"},
+	{"mi", "length", "1609.344", "0"},
+	{"nmi", "length", "1852", "0"},
+	{"au", "length", "149597870700", "0"},
+
+	{"g", "mass", "1", "0"},
+	{"kg", "mass", "1000", "0"},
+	{"mg", "mass", "0.001", "0"},
+	{"ug", "mass", "1e-6", "0"},
+	{"lb", "mass", "453.59238", "0"},
+	{"oz", "mass", "28.34952", "0"},
+	{"st", "mass", "6350.29332", "0"},
+	{"ton", "mass", "907184.76", "0"},
+	{"t", "mass", "1e6", "0"}, // metric tonne
+	{"gr", "mass", "0.06479891", "0"},
+
+	{"l", "volume", "1", "0"},
+	{"ml", "volume", "0.001", "0"},
+	{"cc", "volume", "0.001", "0"},
+	{"m3", "volume", "1000", "0"},
+	{"gal", "volume", "3.785411784", "0"},
+	{"qt", "volume", "0.946352946", "0"},
+	{"pt", "volume", "0.473176473", "0"},
+	{"cup", "volume", "0.2365882365", "0"},
+	{"floz", "volume", "0.0295735295625", "0"},
+	{"tbsp", "volume", "0.01478676478125", "0"},
+	{"tsp", "volume", "0.00492892159375", "0"},
+	{"in3", "volume", "0.016387064", "0"},
+	{"ft3", "volume", "28.316846592", "0"},
+	{"impgal", "volume", "4.54609", "0"},
+
+	{"k", "temperature", "1", "0"},
+	{"c", "temperature", "1", "273.15"},
+	{"f", "temperature", "5/9", "45967/180"}, // 459.67 * 5/9
+	{"r", "temperature", "5/9", "0"},
+
+	{"pa", "pressure", "1", "0"},
+	{"kpa", "pressure", "1000", "0"},
+	{"mpa", "pressure", "1e6", "0"},
+	{"bar", "pressure", "1e5", "0"},
+	{"mbar", "pressure", "100", "0"},
+	{"atm", "pressure", "101325", "0"},
+	{"psi", "pressure", "6894.757293168", "0"},
+	{"mmhg", "pressure", "133.322387415", "0"},
+	{"torr", "pressure", "101325/760", "0"},
+	{"inhg", "pressure", "3386.389", "0"},
+
+	{"j", "energy", "1", "0"},
+	{"kj", "energy", "1000", "0"},
+	{"cal", "energy", "4.184", "0"},
+	{"kcal", "energy", "4184", "0"},
+	{"wh", "energy", "3600", "0"},
+	{"kwh", "energy", "3.6e6", "0"},
+	{"btu", "energy", "1055.05585262", "0"},
+	{"ev", "energy", "1.602176634e-19", "0"},
+	{"ftlb", "energy", "1.3558179483314004", "0"},
+
+	{"s", "time", "1", "0"},
+	{"ms", "time", "0.001", "0"},
+	{"us", "time", "1e-6", "0"},
+	{"min", "time", "60", "0"},
+	{"hr", "time", "3600", "0"},
+	{"h", "time", "3600", "0"},
+	{"day", "time", "86400", "0"},
+	{"wk", "time", "604800", "0"},
+	{"yr", "time", "31557600", "0"}, // Julian year of 365.25 days
+}
+
+var convAliases = map[int]convAliasType{ // keyed by the old cmdMap number
+	540: {"lb", "g"},
+	550: {"oz", "g"},
+	560: {"cm", "in"},
+	570: {"m", "ft"},
+	580: {"mi", "km"},
+	590: {"g", "lb"},
+	600: {"g", "oz"},
+	610: {"in", "cm"},
+	620: {"ft", "m"},
+	630: {"km", "mi"},
+	633: {"c", "f"},
+	636: {"f", "c"},
+}
+
+// ----------------------------------------------------------- unitTable --------------------------------------------------
+
+// unitTable -- returns the built in units w/ the user's units from hpcalc2units.txt merged in.  The file is read fresh each time, so edits don't need a restart.
+// Problems w/ the file are returned as messages, and those lines are skipped.
+func unitTable() (map[string]unitType, []string) {
+	ss := make([]string, 0, 5)
+	units := make(map[string]unitType, len(builtinUnits)+20)
+	for _, u := range builtinUnits {
+		factor, _ := new(big.Rat).SetString(u[2])
+		offset, _ := new(big.Rat).SetString(u[3])
+		units[u[0]] = unitType{u[1], factor, offset}
+	}
+
+	userUnitsFile, err := os.Open(fullUserUnitsFilename)
+	if err != nil { // no user file is fine.
+		return units, ss
+	}
+	defer userUnitsFile.Close()
+
+	scanner := bufio.NewScanner(userUnitsFile)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 || len(fields) > 4 {
+			ss = append(ss, fmt.Sprintf(" %s line %d needs name category factor [offset].  Line skipped.", userUnitsFilename, lineNum))
+			continue
+		}
+		factor, ok := new(big.Rat).SetString(fields[2]) // so 5/9 works as well as 0.3048 or 1e-6
+		if !ok || factor.Sign() == 0 {
+			ss = append(ss, fmt.Sprintf(" %s line %d has a bad factor %q.  Line skipped.", userUnitsFilename, lineNum, fields[2]))
+			continue
+		}
+		offset := new(big.Rat)
+		if len(fields) == 4 {
+			offset, ok = offset.SetString(fields[3])
+			if !ok {
+				ss = append(ss, fmt.Sprintf(" %s line %d has a bad offset %q.  Line skipped.", userUnitsFilename, lineNum, fields[3]))
+				continue
+			}
+		}
+		units[strings.ToLower(fields[0])] = unitType{strings.ToLower(fields[1]), factor, offset}
+	}
+	if err := scanner.Err(); err != nil {
+		ss = append(ss, fmt.Sprintf(" reading %s: %s", userUnitsFilename, err))
+	}
+	return units, ss
+} // unitTable
+
+// ----------------------------------------------------------- ConvertUnits -----------------------------------------------
+
+// ConvertUnits -- converts r from one unit to another.  Unit names are not case sensitive.  Returns an error if either unit is unknown or the categories differ.
+func ConvertUnits(r float64, from, to string) (float64, error) {
+	units, _ := unitTable()
+	return convertWith(units, r, from, to)
+}
+
+func convertWith(units map[string]unitType, r float64, from, to string) (float64, error) {
+	if math.IsInf(r, 0) || math.IsNaN(r) {
+		return 0, fmt.Errorf("%g can't be converted", r)
+	}
+	f, ok := units[strings.ToLower(from)]
+	if !ok {
+		return 0, fmt.Errorf("unit %s not found", from)
+	}
+	t, ok := units[strings.ToLower(to)]
+	if !ok {
+		return 0, fmt.Errorf("unit %s not found", to)
+	}
+	if f.category != t.category {
+		return 0, fmt.Errorf("cannot convert %s, which is %s, to %s, which is %s", from, f.category, to, t.category)
+	}
+	base := new(big.Rat).Mul(floatToRat(r), f.factor)
+	base.Add(base, f.offset)
+	base.Sub(base, t.offset)
+	result, _ := base.Quo(base, t.factor).Float64()
+	return result, nil
+}
+
+// ----------------------------------------------------------- convIndex --------------------------------------------------
+
+// convIndex -- returns where the conv keyword starts in the lower cased input line, or -1.  It has to be a whole word.
+func convIndex(scap string) int {
+	fields := strings.Fields(scap)
+	pos := 0
+	for _, f := range fields {
+		idx := strings.Index(scap[pos:], f) + pos
+		if f == "conv" {
+			return idx
+		}
+		pos = idx + len(f)
+	}
+	return -1
+}
+
+// ----------------------------------------------------------- convRoutine ------------------------------------------------
+
+// convRoutine -- input string has had the "conv" keyword removed, so it is the from and to units.  X is converted in place.
+func convRoutine(s string) []string {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return []string{" conv needs 2 units, as in conv psi kpa.  UNITS lists them.  Command ignored."}
+	}
+	cplxFillFromFloat() // only does something in complex mode.
+	if calcMode == ComplexMode && imag(cplxStack[X]) != 0 {
+		return []string{" conv isn't done for complex numbers.  Command ignored."}
+	}
+	units, ss := unitTable()
+	r, err := convertWith(units, READX(), fields[0], fields[1])
+	if err != nil {
+		ss = append(ss, fmt.Sprintf(" %s.  Command ignored.", err))
+		return ss
+	}
+	x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
+	s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
+	ss = append(ss, fmt.Sprintf("%s %s is %s %s", x, fields[0], s0, fields[1]))
+	PushMatrixStacks()
+	modeSetX(r)
+	return ss
+} // convRoutine
+
+// ----------------------------------------------------------- convAlias --------------------------------------------------
+
+// convAlias -- the old conversion commands, like LB2G.  These push their result instead of converting X in place.
+func convAlias(cmdnum int) []string {
+	alias := convAliases[cmdnum]
+	units, ss := unitTable()
+	r, err := convertWith(units, READX(), alias.from, alias.to)
+	if err != nil {
+		ss = append(ss, fmt.Sprintf(" %s.  Command ignored.", err))
+		return ss
+	}
+	x := strconv.FormatFloat(READX(), 'f', sigfig, 64)
+	s0 := strconv.FormatFloat(r, 'f', sigfig, 64)
+	ss = append(ss, fmt.Sprintf("%s %s is %s %s", x, alias.from, s0, alias.to))
+	PushMatrixStacks()
+	LastX = Stack[X]
+	modePushX(r)
+	return ss
+} // convAlias
+
+// ----------------------------------------------------------- unitList ---------------------------------------------------
+
+// unitList -- returns the units grouped by category, for the UNITS command.
+func unitList() []string {
+	units, ss := unitTable()
+	byCategory := make(map[string][]string)
+	for name, u := range units {
+		byCategory[u.category] = append(byCategory[u.category], name)
+	}
+	categories := make([]string, 0, len(byCategory))
+	for c := range byCategory {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	for _, c := range categories {
+		names := byCategory[c]
+		sort.Strings(names)
+		ss = append(ss, fmt.Sprintf(" %s: %s", c, strings.Join(names, ", ")))
+	}
+	return ss
+} // unitList
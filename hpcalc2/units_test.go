@@ -0,0 +1,106 @@
+package hpcalc2
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertUnits(t *testing.T) {
+	fullUserUnitsFilename = filepath.Join(t.TempDir(), userUnitsFilename)
+	err := os.WriteFile(fullUserUnitsFilename, []byte("furlong length 201.168  # 220 yd\nsmoot length 1.7018\nbad length\nzero length 0\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		r        float64
+		from, to string
+		want     float64
+		wantErr  bool
+	}{
+		{r: 1, from: "ft", to: "m", want: 0.3048},
+		{r: 1, from: "FT", to: "M", want: 0.3048}, // not case sensitive
+		{r: 1, from: "yd", to: "in", want: 36},
+		{r: 100, from: "c", to: "f", want: 212},
+		{r: 32, from: "f", to: "c", want: 0},
+		{r: 0, from: "k", to: "c", want: -273.15},
+		{r: 1, from: "atm", to: "torr", want: 760},
+		{r: 1, from: "furlong", to: "yd", want: 220}, // from the user's file
+		{r: 1, from: "zero", to: "m", wantErr: true}, // a 0 factor is skipped
+		{r: 1, from: "parsec", to: "m", wantErr: true},
+		{r: 1, from: "m", to: "g", wantErr: true},
+		{r: math.Inf(1), from: "ft", to: "m", wantErr: true},
+		{r: math.NaN(), from: "ft", to: "m", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := ConvertUnits(tc.r, tc.from, tc.to)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ConvertUnits(%g, %s, %s) = %g, want an error", tc.r, tc.from, tc.to, got)
+			}
+			continue
+		}
+		if err != nil || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("ConvertUnits(%g, %s, %s) = %g, %v, want %g", tc.r, tc.from, tc.to, got, err, tc.want)
+		}
+	}
+
+	_, ss := unitTable()
+	if len(ss) != 2 {
+		t.Errorf("unitTable messages are %q, want 1 for the bad line and 1 for the 0 factor", ss)
+	}
+}
+
+// TestConvModes -- CONV sets X, and the aliases push, thru the stack of the current mode, so it agrees w/ the float64 stack.
+func TestConvModes(t *testing.T) {
+	fullMappedRegFilename = filepath.Join(t.TempDir(), "mappedreg.gob")
+	fullUserProgFilename = filepath.Join(t.TempDir(), "userprog.gob")
+	fullUserUnitsFilename = filepath.Join(t.TempDir(), userUnitsFilename)
+	defer SetCalcMode(FloatMode)
+
+	SetCalcMode(FloatMode)
+	GetResult("1 0 /")
+	_, ss := GetResult("conv ft m")
+	if len(ss) == 0 || !strings.Contains(ss[len(ss)-1], "ignored") || !math.IsInf(Stack[X], 1) {
+		t.Errorf("Inf conv ft m: X = %g, msg %q, want an error and X left alone", Stack[X], ss)
+	}
+
+	SetCalcMode(ComplexMode)
+	GetResult("3 4i +")
+	_, ss = GetResult("conv ft m")
+	if len(ss) == 0 || cplxStack[X] != 3+4i || Stack[X] != 3 {
+		t.Errorf("3+4i conv ft m: X = %v and Stack[X] = %g, msg %q, want an error and the stack left alone", cplxStack[X], Stack[X], ss)
+	}
+	GetResult("3 conv ft m")
+	if math.Abs(real(cplxStack[X])-0.9144) > 1e-12 || imag(cplxStack[X]) != 0 || Stack[X] != real(cplxStack[X]) || cplxStack[Y] != 3+4i {
+		t.Errorf("3 conv ft m in COMPLEX: X = %v, Stack[X] = %g and Y = %v, want 0.9144 and 3+4i", cplxStack[X], Stack[X], cplxStack[Y])
+	}
+	GetResult("~ re ~ 1 lb2g") // the alias isn't done while Y has an imaginary part.
+	if math.Abs(real(cplxStack[X])-453.59238) > 1e-9 || cplxStack[Y] != 1 || cplxStack[Z] != 0.9144 {
+		t.Errorf("1 lb2g in COMPLEX: X = %v, Y = %v and Z = %v, want 453.59238, 1 and 0.9144", cplxStack[X], cplxStack[Y], cplxStack[Z])
+	}
+
+	SetCalcMode(BigFloatMode)
+	GetResult("1 3 / 3 conv ft m")
+	if got := bigStack[Y].Text('g', 30); got != "0.333333333333333333333333333333" || bigStack[X].Text('g', 10) != "0.9144" {
+		t.Errorf("1/3 3 conv ft m in BIGFLOAT: X = %s and Y = %s, want 0.9144 and 1/3 to 30 digits", bigStack[X].Text('g', 10), got)
+	}
+	if bigLastX == nil || bigLastX.Text('g', 10) != "3" {
+		t.Errorf("LastX after 3 conv ft m in BIGFLOAT is %v, want 3", bigLastX)
+	}
+
+	SetCalcMode(RationalMode)
+	GetResult("1 3 / 3 conv ft m")
+	if ratStack[X].RatString() != "1143/1250" || ratStack[Y].RatString() != "1/3" {
+		t.Errorf("1/3 3 conv ft m in RAT: X = %s and Y = %s, want 1143/1250 and 1/3", ratStack[X].RatString(), ratStack[Y].RatString())
+	}
+
+	useProgMode(t)
+	GetResult("16 wsize 100 conv in cm")
+	if progStack[X] != 254 || Stack[X] != 254 {
+		t.Errorf("100 conv in cm in PROG: X = %d and Stack[X] = %g, want 254", progStack[X], Stack[X])
+	}
+}
@@ -125,9 +125,35 @@ import (
   13 Feb 26 -- Added rowOffset to allow for a date row in the schedule to the lint library.  Here, I added a message for the lint library last modified date.
   18 Feb 26 -- Debugging problem w/ not finding upgradelint.exe
    9 May 26 -- Added use of lint.CheckRowNames.
+  18 Oct 26 -- The checks are now rules, which can be in the config file after the off and startdirectory lines.  lint.FindAndReadRules reads them.
+  18 Oct 26 -- Added the fairness mode, -f.  It reads all the schedules in the months threshold, or the ones on the command line, and tallies for each person
+                the late shifts, fluoro days, weekends, remote days and days off.  Imbalances are flagged against the target lines in the config file.
+                It writes the report to fairness.xlsx, or the -r file, and shows a summary.
+  18 Oct 26 -- Added --format text|json|csv for the violations, and exit codes so a script can tell what happened.  0 means no violations, 1 means only warnings,
+                2 means at least 1 error, and 3 means lint couldn't do its job, like no schedule or a bad config file.  json and csv only write the violations to stdout;
+                anything else goes to stderr, the newest schedule is used if there isn't one on the command line, and upgradelint isn't run.
+  18 Oct 26 -- Reads the roster, the doc and ignore lines of the config file, w/ lint.FindAndReadRoster.  Names that aren't in the roster are warnings.
 */
 
-const lastModified = "11 May 2026"
+const lastModified = "18 Oct 2026"
+
+// exit codes
+const (
+	exitOK         = 0
+	exitWarnings   = 1 // only warnings
+	exitViolations = 2 // at least 1 violation w/ error severity
+	exitFailure    = 3 // couldn't read the config file, or find or scan the schedule
+)
+
+// exitCodeFor -- exitViolations if any violation is an error, exitWarnings if they're all warnings, and exitOK if there are none.
+func exitCodeFor(violations []lint.Violation) int {
+	if lint.HasErrors(violations) {
+		return exitViolations
+	} else if len(violations) > 0 {
+		return exitWarnings
+	}
+	return exitOK
+}
 
 var verboseFlag bool
 var veryVerboseFlag bool
@@ -146,6 +172,9 @@ func main() {
 	var err error
 	var noUpgradeLint bool
 	var whichURL int
+	var fairnessFlag bool
+	var reportFilename string
+	var format string
 	flag.BoolVarP(&verboseFlag, "verbose", "v", false, "verbose debugging output")
 	flag.BoolVarP(&veryVerboseFlag, "vv", "w", false, "very verbose debugging output")
 	flag.IntVarP(&monthsThreshold, "months", "m", 1, "months threshold for schedule files")
@@ -153,6 +182,9 @@ func main() {
 	flag.IntVarP(&whichURL, "url", "u", 0, "which URL to use for the auto updating of lint.exe")
 	u1 := flag.BoolP("u1", "1", false, "Shortcut for -u 1")
 	u2 := flag.BoolP("u2", "2", false, "Shortcut for -u 2")
+	flag.BoolVarP(&fairnessFlag, "fairness", "f", false, "fairness report over all schedules in the months threshold, or the ones on the command line")
+	flag.StringVarP(&reportFilename, "report", "r", "fairness.xlsx", "xlsx file for the fairness report")
+	flag.StringVar(&format, "format", lint.FormatText, "output format for the violations: text, json or csv")
 
 	flag.Usage = func() {
 		fmt.Printf(" %s last modified main.go %s and lint.go %s, compiled with %s, using pflag.\n", os.Args[0],
@@ -160,6 +192,10 @@ func main() {
 		fmt.Printf(" Usage: %s <weekly xlsx file> \n", os.Args[0])
 		fmt.Printf(" Looks for lint.conf or lint.ini in current, home and config directories.\n")
 		fmt.Printf(" First line must begin with off, and 2nd line, if present, must begin with startdirectory.\n")
+		fmt.Printf(" Lines after that that begin with rule are the checks, as in rule late-on-fluoro notboth late fluoro.  See rules.go.\n")
+		fmt.Printf(" Lines that begin with target are per week targets for the fairness report, as in target late 1 2, or target fluoro mean 0.25.  See fairness.go.\n")
+		fmt.Printf(" Lines that begin with doc are the roster, as in doc kim kimberly kk, where kim is the ID and the rest are aliases.  See roster.go.\n")
+		fmt.Printf(" Exit code is 0 for no violations, 1 for only warnings, 2 for any errors, and 3 if the schedule couldn't be checked.\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
@@ -173,9 +209,18 @@ func main() {
 		whichURL = 2
 	}
 
+	format = strings.ToLower(format)
+	if format != lint.FormatText && format != lint.FormatJSON && format != lint.FormatCSV {
+		fmt.Fprintf(os.Stderr, " --format must be text, json or csv, not %q.  Exiting \n", format)
+		os.Exit(exitFailure)
+	}
+	textFormat := format == lint.FormatText
+
 	var filename, ans string
 
-	fmt.Printf(" lint V 3.2 for the weekly schedule, last modified %s, last modified lint library %s\n", lastModified, lint.LastModified)
+	if textFormat {
+		fmt.Printf(" lint V 3.2 for the weekly schedule, last modified %s, last modified lint library %s\n", lastModified, lint.LastModified)
+	}
 
 	_, startDirFromConfigFile, err = lint.FindAndReadConfIni() // ignore the doc names list from the config file, as that's now extracted from the schedule itself.
 	if err != nil {
@@ -191,10 +236,28 @@ func main() {
 
 	lint.VeryVerboseFlag = veryVerboseFlag
 	lint.VerboseFlag = verboseFlag
+
+	lint.Rules, err = lint.FindAndReadRules() // no rules means lint.DefaultRules are used.
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error in the rules of the config file: %s.  Exiting \n", err)
+		os.Exit(exitFailure)
+	}
+	docs, ignore, err := lint.FindAndReadRoster() // no docs means the names in the schedule are the roster.
+	if err == nil {
+		lint.Roster, err = lint.NewRoster(docs, ignore)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error in the roster of the config file: %s.  Exiting \n", err)
+		os.Exit(exitFailure)
+	}
 	lint.StartDirFromConfigFile = startDirFromConfigFile
 	lint.MonthsThreshold = monthsThreshold
 	whichexec.VerboseFlag = verboseFlag
 
+	if fairnessFlag {
+		os.Exit(fairnessReport(reportFilename))
+	}
+
 	if flag.NArg() == 0 {
 		//if includeODrive {  O: drive is gone as of 8/8/25.
 		//	filenames, err = walkRegexFullFilenames() // function is below.  "o:\\week.*xls.?$"
@@ -267,32 +330,40 @@ func main() {
 
 		filenames, err := lint.GetScheduleFilenames()
 		if err != nil {
-			fmt.Printf(" Error from GetFilenames is %s.  Exiting \n", err)
-			return
-		}
-
-		for i := 0; i < min(len(filenames), 26); i++ {
-			fmt.Printf("filename[%d, %c] is %s\n", i, i+'a', filenames[i])
+			fmt.Fprintf(os.Stderr, " Error from GetFilenames is %s.  Exiting \n", err)
+			os.Exit(exitFailure)
 		}
-		fmt.Print(" Enter filename choice : ")
-		n, err := fmt.Scanln(&ans)
-		if n == 0 || err != nil {
-			ans = "0"
-		} else if ans == "999" || ans == "." || ans == "," || ans == ";" {
-			fmt.Println(" No files entered.  Exiting.")
-			return
+		if len(filenames) == 0 {
+			fmt.Fprintf(os.Stderr, " No schedule files found.  Exiting \n")
+			os.Exit(exitFailure)
 		}
-		i, e := strconv.Atoi(ans)
-		if e == nil {
-			filename = filenames[i]
+		if !textFormat { // no one to answer the prompt, so use the newest.
+			filename = filenames[0]
+			fmt.Fprintf(os.Stderr, " Checking the newest schedule, %s\n", filename)
 		} else {
-			s := strings.ToUpper(ans)
-			s = strings.TrimSpace(s)
-			s0 := s[0]
-			i = int(s0 - 'A')
-			filename = filenames[i]
+			for i := 0; i < min(len(filenames), 26); i++ {
+				fmt.Printf("filename[%d, %c] is %s\n", i, i+'a', filenames[i])
+			}
+			fmt.Print(" Enter filename choice : ")
+			n, err := fmt.Scanln(&ans)
+			if n == 0 || err != nil {
+				ans = "0"
+			} else if ans == "999" || ans == "." || ans == "," || ans == ";" {
+				fmt.Println(" No files entered.  Exiting.")
+				return
+			}
+			i, e := strconv.Atoi(ans)
+			if e == nil {
+				filename = filenames[i]
+			} else {
+				s := strings.ToUpper(ans)
+				s = strings.TrimSpace(s)
+				s0 := s[0]
+				i = int(s0 - 'A')
+				filename = filenames[i]
+			}
+			fmt.Println(" Picked spreadsheet is", filename)
 		}
-		fmt.Println(" Picked spreadsheet is", filename)
 	} else { // will use filename entered on commandline
 		filename = flag.Arg(0)
 	}
@@ -300,12 +371,14 @@ func main() {
 	if verboseFlag {
 		fmt.Printf(" spreadsheet picked is %s\n", filename)
 	}
-	fmt.Println()
+	if textFormat {
+		fmt.Println()
+	}
 
 	names, err := lint.GetDocNames(filename)
 	if err != nil {
-		ctfmt.Printf(ct.Red, true, " Error from getDocNames: %s.  Exiting \n", err)
-		return
+		fmt.Fprintf(os.Stderr, " Error from getDocNames: %s.  Exiting \n", err)
+		os.Exit(exitFailure)
 	}
 	if verboseFlag {
 		fmt.Printf(" doc names extracted from %s length: %d\n", filename, len(names))
@@ -318,51 +391,75 @@ func main() {
 	soundx := lint.GetSoundex(names)
 	spellingErrors := lint.ShowSpellingErrors(soundx)
 	if len(spellingErrors) > 0 {
-		ctfmt.Printf(ct.Cyan, true, "\n\n %d spelling error(s) detected in %s: ", len(spellingErrors)/2, filename)
-		for _, spell := range spellingErrors {
-			ctfmt.Printf(ct.Red, true, " %s  ", spell)
+		if textFormat {
+			ctfmt.Printf(ct.Cyan, true, "\n\n %d spelling error(s) detected in %s: ", len(spellingErrors)/2, filename)
+			for _, spell := range spellingErrors {
+				ctfmt.Printf(ct.Red, true, " %s  ", spell)
+			}
+			fmt.Printf("\n\n\n")
+		} else {
+			fmt.Fprintf(os.Stderr, " %d spelling error(s) detected in %s: %s\n", len(spellingErrors)/2, filename, strings.Join(spellingErrors, "  "))
 		}
-		fmt.Printf("\n\n\n")
 	}
 
 	// Check to see if there schedule format has changed
 	mismatched, err := lint.CheckRowNames(filename)
 	if err != nil {
-		ctfmt.Printf(ct.Red, true, " Error from CheckRowNames: %s.  Exiting \n", err)
-		return
+		fmt.Fprintf(os.Stderr, " Error from CheckRowNames: %s.  Exiting \n", err)
+		os.Exit(exitFailure)
 	}
 
 	if mismatched {
-		ctfmt.Printf(ct.White, true, "\n********************************************************************")
-		ctfmt.Printf(ct.Red, true, "\n Warning: ")
-		ctfmt.Printf(ct.White, true, "CheckRowNames is mismatched.  Results may not be reliable.")
-		ctfmt.Printf(ct.Red, true, "  Warning: \n")
-		ctfmt.Printf(ct.White, true, "*******************************************************************\n")
+		if textFormat {
+			ctfmt.Printf(ct.White, true, "\n********************************************************************")
+			ctfmt.Printf(ct.Red, true, "\n Warning: ")
+			ctfmt.Printf(ct.White, true, "CheckRowNames is mismatched.  Results may not be reliable.")
+			ctfmt.Printf(ct.Red, true, "  Warning: \n")
+			ctfmt.Printf(ct.White, true, "*******************************************************************\n")
+		} else {
+			fmt.Fprintf(os.Stderr, " Warning: CheckRowNames is mismatched.  Results may not be reliable.\n")
+		}
 	}
 
 	// scan the xlsx schedule file
 
-	messages, err := lint.ScanXLSfile(filename)
-	if len(messages) > 0 {
-		ctfmt.Printf(ct.Cyan, true, "\n\n %d message(s) generated from %s: \n", len(messages), filename)
-		for _, msg := range messages {
-			ctfmt.Printf(ct.Yellow, true, " %s \n", msg)
+	violations, err := lint.CheckXLSfile(filename)
+	if err != nil {
+		if textFormat {
+			ctfmt.Printf(ct.Red, true, "\n\n Error scanning %s is %s\n\n", filename, err)
+		} else {
+			fmt.Fprintf(os.Stderr, " Error scanning %s is %s\n", filename, err)
 		}
+		os.Exit(exitFailure)
 	}
 
-	if err == nil {
-		ctfmt.Printf(ct.Green, true, "\n\n Finished scanning %s\n\n", filename)
-	} else {
-		ctfmt.Printf(ct.Red, true, "\n\n Error scanning %s is %s\n\n", filename, err)
-		return
+	exitCode := exitCodeFor(violations)
+
+	if !textFormat {
+		err = lint.WriteViolations(os.Stdout, violations, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error writing the violations: %s\n", err)
+			os.Exit(exitFailure)
+		}
+		os.Exit(exitCode) // upgradelint writes to stdout, so it's not run.
 	}
 
-	if noUpgradeLint {
-		return
-	} // this flag is a param above.
+	if len(violations) > 0 {
+		ctfmt.Printf(ct.Cyan, true, "\n\n %d message(s) generated from %s: \n", len(violations), filename)
+		for _, violation := range violations {
+			ctfmt.Printf(ct.Yellow, true, " %s \n", violation.String())
+		}
+	}
+	ctfmt.Printf(ct.Green, true, "\n\n Finished scanning %s\n\n", filename)
 
-	// Time to run the updatelist cmd.
+	if !noUpgradeLint { // this flag is a param above.
+		runUpgradeLint(whichURL)
+	}
+	os.Exit(exitCode)
+}
 
+// runUpgradeLint starts upgradelint, and doesn't wait for it.
+func runUpgradeLint(whichURL int) {
 	workingDir, err := os.Getwd()
 	if err != nil {
 		ctfmt.Printf(ct.Red, true, "\n\n Error getting working directory: %s.  Contact Rob Solomon\n\n", err)
@@ -392,3 +489,50 @@ func main() {
 		ctfmt.Printf(ct.Red, true, "\n\n Error starting upgradelint: %s.  Contact Rob Solomon\n\n", err)
 	}
 }
+
+// fairnessReport reads the schedules on the command line, or all of them in the months threshold, and shows and writes the fairness report.  It returns the exit code.
+func fairnessReport(reportFilename string) int {
+	targets, err := lint.FindAndReadTargets() // no targets means lint.DefaultTargets are used.
+	if err != nil {
+		ctfmt.Printf(ct.Red, true, " Error in the targets of the config file: %s.  Exiting \n", err)
+		return exitFailure
+	}
+
+	filenames := flag.Args()
+	if len(filenames) == 0 {
+		filenames, err = lint.GetScheduleFilenames()
+		if err != nil {
+			fmt.Printf(" Error from GetFilenames is %s.  Exiting \n", err)
+			return exitFailure
+		}
+	}
+	if verboseFlag {
+		fmt.Printf(" fairness report from %d schedule file(s): %#v\n", len(filenames), filenames)
+	}
+
+	report, err := lint.BuildFairnessReport(filenames, targets)
+	if err != nil {
+		ctfmt.Printf(ct.Red, true, " Error from BuildFairnessReport: %s.  Exiting \n", err)
+		return exitFailure
+	}
+
+	summary := report.Summary()
+	nImbalances := len(report.Imbalances)
+	for _, line := range summary[:len(summary)-nImbalances] {
+		ctfmt.Printf(ct.Cyan, true, "%s\n", line)
+	}
+	if nImbalances > 0 {
+		ctfmt.Printf(ct.Cyan, true, "\n %d imbalance(s): \n", nImbalances)
+		for _, line := range summary[len(summary)-nImbalances:] {
+			ctfmt.Printf(ct.Yellow, true, "%s\n", line)
+		}
+	}
+
+	err = lint.WriteFairnessXLSX(report, reportFilename)
+	if err != nil {
+		ctfmt.Printf(ct.Red, true, "\n Error writing %s: %s\n", reportFilename, err)
+		return exitFailure
+	}
+	ctfmt.Printf(ct.Green, true, "\n Fairness report written to %s\n\n", reportFilename)
+	return exitOK
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"src/lint"
+	"testing"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	warning := lint.Violation{Rule: "late-limit", Severity: lint.SeverityWarning}
+	violation := lint.Violation{Rule: "off-but-assigned", Severity: lint.SeverityError}
+	tests := []struct {
+		name       string
+		violations []lint.Violation
+		want       int
+	}{
+		{"none", nil, exitOK},
+		{"only warnings", []lint.Violation{warning, warning}, exitWarnings},
+		{"an error", []lint.Violation{warning, violation}, exitViolations},
+		{"no severity is an error", []lint.Violation{{Rule: "old"}}, exitViolations},
+	}
+	for _, tc := range tests {
+		if got := exitCodeFor(tc.violations); got != tc.want {
+			t.Errorf("%s: exitCodeFor = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+// Package lint: fairness.go
+package lint
+
+/*
+  18 Oct 26 -- Started the fairness report.  It loads a range of weekly schedules, the same ones GetScheduleFilenames finds (use -m to go back further),
+                and counts for each person the late shifts, fluoro days, weekends, remote days and days off.  A weekend is a week the person is in one of the
+                weekend rows below the blue bar.  The counts are divided by the weeks the person is on the schedule, so part timers aren't flagged for being part time.
+                The targets are per week, and are target lines in the config file, after the off and startdirectory lines:
+				target late     1 2          -- flag anyone outside of 1 to 2 late shifts per week
+				target fluoro   mean 0.25    -- flag anyone more than 25% away from the average of everyone
+                If there are no target lines, DefaultTargets are used.  The report is an xlsx workbook, and Summary is for the console.
+  18 Oct 26 -- The tallies are by roster ID, so a doc w/ a nickname or a misspelling in some weeks is still 1 person.
+  18 Oct 26 -- The means and the imbalances are in applyTargets, so they can be tested w/o schedule files.
+*/
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stoewer/go-strcase"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// Fairness categories
+const (
+	LateCategory    = "late"
+	FluoroCategory  = "fluoro"
+	WeekendCategory = "weekend"
+	RemoteCategory  = "remote"
+	OffCategory     = "off"
+)
+
+var FairnessCategories = []string{LateCategory, FluoroCategory, WeekendCategory, RemoteCategory, OffCategory}
+
+const weekendRows = 4 // weekend neuro, weekend body, On-call IR and On-call MD, below the bluebarweekendcoverage row.
+
+// TallyType is one person's counts over all the weeks of the report.
+type TallyType struct {
+	Person   string
+	Weeks    int // weeks the person is on the schedule
+	Late     int // days
+	Fluoro   int // days
+	Weekends int
+	Remote   int // days
+	Off      int // days
+}
+
+func (t TallyType) count(category string) int {
+	switch category {
+	case LateCategory:
+		return t.Late
+	case FluoroCategory:
+		return t.Fluoro
+	case WeekendCategory:
+		return t.Weekends
+	case RemoteCategory:
+		return t.Remote
+	case OffCategory:
+		return t.Off
+	}
+	return 0
+}
+
+// PerWeek is the count for the category divided by the weeks the person is on the schedule.
+func (t TallyType) PerWeek(category string) float64 {
+	if t.Weeks == 0 {
+		return 0
+	}
+	return float64(t.count(category)) / float64(t.Weeks)
+}
+
+// TargetType is the per week range for a category.  If Mean is set, the range is the average of everyone plus or minus Tolerance of that average.
+type TargetType struct {
+	Category  string
+	Mean      bool
+	Tolerance float64
+	Min, Max  float64
+}
+
+// DefaultTargets are used when there are no target lines in the config file.  Days off are counted, but not flagged, as that's vacation.
+var DefaultTargets = []TargetType{
+	{Category: LateCategory, Mean: true, Tolerance: 0.5},
+	{Category: FluoroCategory, Mean: true, Tolerance: 0.5},
+	{Category: WeekendCategory, Mean: true, Tolerance: 0.5},
+	{Category: RemoteCategory, Mean: true, Tolerance: 0.5},
+}
+
+// ImbalanceType is a person whose per week count is outside of the target range.
+type ImbalanceType struct {
+	Person    string
+	Category  string
+	PerWeek   float64
+	Low, High float64
+}
+
+func (imb ImbalanceType) String() string {
+	return fmt.Sprintf(" %s has %.2f %s per week, but the target is %.2f to %.2f", strcase.UpperCamelCase(imb.Person), imb.PerWeek, imb.Category, imb.Low, imb.High)
+}
+
+type weekType struct {
+	label    string // the date line from Monday
+	date     time.Time
+	filename string
+}
+
+// FairnessReport is the result of BuildFairnessReport, sorted by week and by person.
+type FairnessReport struct {
+	Weeks      []string // the date line from each week's Monday
+	Filenames  []string // in the same order as Weeks
+	Tallies    []TallyType
+	Means      map[string]float64 // per week, for each category
+	Imbalances []ImbalanceType
+}
+
+// ParseTarget parses a target line of the config file, w/o the word target.
+func ParseTarget(line string) (TargetType, error) {
+	fields := strings.Fields(strings.ToLower(line))
+	if len(fields) != 3 {
+		return TargetType{}, fmt.Errorf("target %q needs a category and either min max, or mean and a tolerance", line)
+	}
+	target := TargetType{Category: fields[0]}
+	if !slices.Contains(FairnessCategories, target.Category) {
+		return TargetType{}, fmt.Errorf("target category must be one of %s, not %q", strings.Join(FairnessCategories, ", "), fields[0])
+	}
+	var err error
+	if fields[1] == "mean" {
+		target.Mean = true
+		target.Tolerance, err = strconv.ParseFloat(fields[2], 64)
+		if err == nil && target.Tolerance < 0 {
+			err = fmt.Errorf("tolerance %g is negative", target.Tolerance)
+		}
+	} else if target.Min, err = strconv.ParseFloat(fields[1], 64); err == nil {
+		target.Max, err = strconv.ParseFloat(fields[2], 64)
+		if err == nil && target.Min > target.Max {
+			err = fmt.Errorf("min %g is more than max %g", target.Min, target.Max)
+		}
+	}
+	if err != nil {
+		return TargetType{}, fmt.Errorf("target %s: %w", target.Category, err)
+	}
+	return target, nil
+}
+
+// FindAndReadTargets reads the target lines from the same config file as FindAndReadRules.  No config file, or no targets in it, returns nil.
+func FindAndReadTargets() ([]TargetType, error) {
+	var targets []TargetType
+	fullFile, err := readConfigLines("target", func(targetLine string) error {
+		target, err := ParseTarget(targetLine)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if VerboseFlag {
+		fmt.Printf(" Read %d targets from %s: %+v\n", len(targets), fullFile, targets)
+	}
+	return targets, nil
+}
+
+// weekDate parses the date line, which is either Month Day Year, or has the day of the week in front.  The commas were already removed by ReadEntireDay.
+func weekDate(dateLine string) time.Time {
+	for _, layout := range []string{"January 2 2006", "Monday January 2 2006", "Jan 2 2006", "Mon Jan 2 2006"} {
+		if t, err := time.Parse(layout, dateLine); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// readWeekendText returns all of the weekend rows as one string.  These rows are below totalAmt, so they're not in the WorkWeekType.
+func readWeekendText(workBook *xlsx.File) string {
+	sheet := workBook.Sheets[0]
+	var sb strings.Builder
+	for row := totalAmt + rowOffset; row < totalAmt+rowOffset+weekendRows && row < sheet.MaxRow; row++ {
+		for col := 1; col < sheet.MaxCol; col++ {
+			cell, err := sheet.Cell(row, col)
+			if err != nil {
+				continue
+			}
+			sb.WriteString(strings.ToLower(cell.String()))
+			sb.WriteString(" ")
+		}
+	}
+	return sb.String()
+}
+
+// tallyWeek adds one week to the tallies, by roster ID.  The Roster has to be set for this week.
+func tallyWeek(week WorkWeekType, weekendText string, tallies map[string]*TallyType) {
+	onSchedule := make(map[string]bool)
+	add := func(name string) *TallyType {
+		if tallies[name] == nil {
+			tallies[name] = &TallyType{Person: name}
+		}
+		onSchedule[name] = true
+		return tallies[name]
+	}
+
+	for dayCol := monday; dayCol < saturday; dayCol++ {
+		for _, row := range RowKeywords["work"] {
+			for _, name := range namesInRow(week, dayCol, row) {
+				add(name)
+			}
+		}
+		for _, name := range namesInRow(week, dayCol, late) {
+			add(name).Late++
+		}
+		var fluoroToday []string
+		for _, row := range RowKeywords["fluoro"] {
+			fluoroToday = append(fluoroToday, namesInRow(week, dayCol, row)...)
+		}
+		slices.Sort(fluoroToday)
+		for _, name := range slices.Compact(fluoroToday) { // on both fluoro rows is still 1 day
+			add(name).Fluoro++
+		}
+		for _, name := range whosRemoteToday(week, dayCol) { // already de-duplicated
+			add(name).Remote++
+		}
+		for _, name := range namesInRow(week, dayCol, mdOff) {
+			add(name).Off++
+		}
+	}
+	weekendIDs, _ := Roster.Resolve(weekendText)
+	for _, name := range weekendIDs {
+		add(name).Weekends++
+	}
+
+	for name := range onSchedule {
+		tallies[name].Weeks++
+	}
+}
+
+// BuildFairnessReport reads each schedule file and tallies each person over all the weeks.  If the same week is in more than 1 file, as happens w/ a copy
+// in Documents and in OneDrive, the 1st one is used.  As GetScheduleFilenames is sorted newest first, that's the latest copy.  This sets Names for each week,
+// and the Roster too if it's not from the config file.
+func BuildFairnessReport(filenames []string, targets []TargetType) (FairnessReport, error) {
+	var weeks []weekType
+	seen := make(map[string]bool)
+	tallies := make(map[string]*TallyType)
+
+	for _, filename := range filenames {
+		workBook, err := xlsx.OpenFile(filename)
+		if err != nil {
+			return FairnessReport{}, fmt.Errorf("%s: %w", filename, err)
+		}
+		week, err := ReadWorkWeek(workBook)
+		if err != nil {
+			return FairnessReport{}, fmt.Errorf("%s: %w", filename, err)
+		}
+		label := strings.Join(strings.Fields(week[monday][dateLine+rowOffset]), " ")
+		if label == "" {
+			label = filepath.Base(filename)
+		}
+		if seen[label] {
+			if VerboseFlag {
+				fmt.Printf(" Skipping %s, as the week of %s is already in the report\n", filename, label)
+			}
+			continue
+		}
+		seen[label] = true
+
+		Names, err = GetDocNames(filename) // after ReadWorkWeek, which sets the rowOffset GetDocNames uses.
+		if err != nil {
+			return FairnessReport{}, fmt.Errorf("%s: %w", filename, err)
+		}
+		useNamesIfNoRoster()
+		tallyWeek(week, readWeekendText(workBook), tallies)
+		weeks = append(weeks, weekType{label: label, date: weekDate(label), filename: filename})
+	}
+	if len(weeks) == 0 {
+		return FairnessReport{}, fmt.Errorf("no schedules to report on")
+	}
+
+	sort.SliceStable(weeks, func(i, j int) bool { return weeks[i].date.Before(weeks[j].date) })
+	var report FairnessReport
+	for _, w := range weeks {
+		report.Weeks = append(report.Weeks, w.label)
+		report.Filenames = append(report.Filenames, w.filename)
+	}
+	for _, tally := range tallies {
+		if tally.Weeks > 0 { // a word that's not a name is in Names, but never in a row.
+			report.Tallies = append(report.Tallies, *tally)
+		}
+	}
+	sort.Slice(report.Tallies, func(i, j int) bool { return report.Tallies[i].Person < report.Tallies[j].Person })
+
+	report.applyTargets(targets)
+	return report, nil
+}
+
+// applyTargets sets the per week Means of each category, and the Imbalances of the tallies that are outside of the targets, or DefaultTargets if there are none.
+// A Mean target's range is the mean of the category, less and plus Tolerance times the mean, so a tolerance of 0.5 is from half to 1.5 times the mean.
+func (report *FairnessReport) applyTargets(targets []TargetType) {
+	report.Means = make(map[string]float64)
+	for _, category := range FairnessCategories {
+		var sum float64
+		for _, tally := range report.Tallies {
+			sum += tally.PerWeek(category)
+		}
+		if len(report.Tallies) > 0 {
+			report.Means[category] = sum / float64(len(report.Tallies))
+		}
+	}
+
+	if len(targets) == 0 {
+		targets = DefaultTargets
+	}
+	for _, target := range targets {
+		low, high := target.Min, target.Max
+		if target.Mean {
+			mean := report.Means[target.Category]
+			low, high = mean*(1-target.Tolerance), mean*(1+target.Tolerance)
+		}
+		for _, tally := range report.Tallies {
+			perWeek := tally.PerWeek(target.Category)
+			if perWeek < low || perWeek > high {
+				report.Imbalances = append(report.Imbalances, ImbalanceType{Person: tally.Person, Category: target.Category, PerWeek: perWeek, Low: low, High: high})
+			}
+		}
+	}
+}
+
+// Summary is the report as lines for the console; the tallies, the averages, and then the imbalances.
+func (report FairnessReport) Summary() []string {
+	lines := make([]string, 0, len(report.Tallies)+len(report.Imbalances)+6)
+	lines = append(lines, fmt.Sprintf(" %d weeks, from %s to %s", len(report.Weeks), report.Weeks[0], report.Weeks[len(report.Weeks)-1]))
+	lines = append(lines, fmt.Sprintf(" %-16s %5s %5s %6s %8s %6s %4s", "Name", "Weeks", "Late", "Fluoro", "Weekends", "Remote", "Off"))
+	for _, t := range report.Tallies {
+		lines = append(lines, fmt.Sprintf(" %-16s %5d %5d %6d %8d %6d %4d", strcase.UpperCamelCase(t.Person), t.Weeks, t.Late, t.Fluoro, t.Weekends, t.Remote, t.Off))
+	}
+	m := report.Means
+	lines = append(lines, fmt.Sprintf(" %-16s %5s %5.2f %6.2f %8.2f %6.2f %4.2f", "Average/week", "", m[LateCategory], m[FluoroCategory], m[WeekendCategory],
+		m[RemoteCategory], m[OffCategory]))
+	for _, imb := range report.Imbalances {
+		lines = append(lines, imb.String())
+	}
+	return lines
+}
+
+// WriteFairnessXLSX writes the report as a workbook w/ 3 sheets; the tallies, the imbalances, and the weeks that were read.
+func WriteFairnessXLSX(report FairnessReport, filename string) error {
+	workBook := xlsx.NewFile()
+
+	tallySheet, err := workBook.AddSheet("Tallies")
+	if err != nil {
+		return err
+	}
+	addStrings(tallySheet.AddRow(), "Name", "Weeks", "Late", "Fluoro", "Weekends", "Remote", "Off",
+		"Late/week", "Fluoro/week", "Weekends/week", "Remote/week", "Off/week")
+	for _, t := range report.Tallies {
+		row := tallySheet.AddRow()
+		row.AddCell().SetString(strcase.UpperCamelCase(t.Person))
+		for _, n := range []int{t.Weeks, t.Late, t.Fluoro, t.Weekends, t.Remote, t.Off} {
+			row.AddCell().SetInt(n)
+		}
+		for _, category := range FairnessCategories {
+			row.AddCell().SetFloat(t.PerWeek(category))
+		}
+	}
+	row := tallySheet.AddRow()
+	row.AddCell().SetString("Average/week")
+	for range 6 {
+		row.AddCell()
+	}
+	for _, category := range FairnessCategories {
+		row.AddCell().SetFloat(report.Means[category])
+	}
+	tallySheet.SetColWidth(1, 1, 18)
+
+	imbalanceSheet, err := workBook.AddSheet("Imbalances")
+	if err != nil {
+		return err
+	}
+	addStrings(imbalanceSheet.AddRow(), "Name", "Category", "Per week", "Target low", "Target high")
+	for _, imb := range report.Imbalances {
+		row := imbalanceSheet.AddRow()
+		row.AddCell().SetString(strcase.UpperCamelCase(imb.Person))
+		row.AddCell().SetString(imb.Category)
+		row.AddCell().SetFloat(imb.PerWeek)
+		row.AddCell().SetFloat(imb.Low)
+		row.AddCell().SetFloat(imb.High)
+	}
+	imbalanceSheet.SetColWidth(1, 1, 18)
+
+	weekSheet, err := workBook.AddSheet("Weeks")
+	if err != nil {
+		return err
+	}
+	addStrings(weekSheet.AddRow(), "Week", "Schedule file")
+	for i, week := range report.Weeks {
+		addStrings(weekSheet.AddRow(), week, report.Filenames[i])
+	}
+	weekSheet.SetColWidth(1, 1, 30)
+
+	return workBook.Save(filename)
+}
+
+func addStrings(row *xlsx.Row, s ...string) {
+	for _, str := range s {
+		row.AddCell().SetString(str)
+	}
+}
@@ -0,0 +1,102 @@
+package lint
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    TargetType
+		wantErr bool
+	}{
+		{line: "late 1 2", want: TargetType{Category: LateCategory, Min: 1, Max: 2}},
+		{line: "Fluoro mean 0.25", want: TargetType{Category: FluoroCategory, Mean: true, Tolerance: 0.25}},
+		{line: "weekend 0 0.5", want: TargetType{Category: WeekendCategory, Max: 0.5}},
+		{line: "late 2 1", wantErr: true},
+		{line: "late mean -0.1", wantErr: true},
+		{line: "late mean", wantErr: true},
+		{line: "call 1 2", wantErr: true},
+		{line: "remote one two", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			got, err := ParseTarget(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseTarget(%q) = %+v, want an error", tc.line, got)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("ParseTarget(%q) = %+v, %v, want %+v", tc.line, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestTallyWeek(t *testing.T) {
+	useTestRoster(t)
+	week := testWeek(map[int]map[int]string{
+		monday:  {body: "Johnson (*R)", fluoroJH: "Smith", fluoroFH: "smith", late: "Kim, Johnson", mdOff: "vd"},
+		tuesday: {late: "Kimberly"},
+		friday:  {msk: "Smyth (*R)"},
+	})
+	tallies := make(map[string]*TallyType)
+	for range 2 {
+		tallyWeek(week, "kim van dyke", tallies)
+	}
+	want := map[string]TallyType{
+		"kim":     {Person: "kim", Weeks: 2, Late: 4, Weekends: 2},
+		"johnson": {Person: "johnson", Weeks: 2, Late: 2, Remote: 2},
+		"smith":   {Person: "smith", Weeks: 2, Fluoro: 2, Remote: 2}, // both fluoro rows on Monday is 1 day.
+		"vandyke": {Person: "vandyke", Weeks: 2, Weekends: 2, Off: 2},
+	}
+	if len(tallies) != len(want) {
+		t.Errorf("tallies are for %d people, want %d: %v", len(tallies), len(want), tallies)
+	}
+	for id, w := range want {
+		if got := tallies[id]; got == nil || *got != w {
+			t.Errorf("tally of %s is %+v, want %+v", id, got, w)
+		}
+	}
+}
+
+func TestApplyTargets(t *testing.T) {
+	tallies := []TallyType{
+		{Person: "a", Weeks: 2, Late: 2, Fluoro: 1}, // 1 late and 0.5 fluoro per week
+		{Person: "b", Weeks: 1},
+		{Person: "c", Weeks: 4, Late: 8, Fluoro: 2}, // 2 late and 0.5 fluoro per week
+	}
+	tests := []struct {
+		name    string
+		targets []TargetType
+		want    []string
+	}{
+		{"mean w/ a tolerance", []TargetType{{Category: LateCategory, Mean: true, Tolerance: 0.5}},
+			[]string{"b late 0.00 0.50 1.50", "c late 2.00 0.50 1.50"}}, // the mean is 1, so the range is 0.5 to 1.5.
+		{"mean w/ no tolerance", []TargetType{{Category: LateCategory, Mean: true}},
+			[]string{"b late 0.00 1.00 1.00", "c late 2.00 1.00 1.00"}},
+		{"mean w/ a wide tolerance", []TargetType{{Category: LateCategory, Mean: true, Tolerance: 1}}, nil},
+		{"min and max", []TargetType{{Category: FluoroCategory, Min: 0.25, Max: 1}}, []string{"b fluoro 0.00 0.25 1.00"}},
+		{"default targets", nil, []string{"b late 0.00 0.50 1.50", "c late 2.00 0.50 1.50",
+			"b fluoro 0.00 0.17 0.50"}}, // the fluoro mean is 1/3, and 0.5 is just inside of 1.5 times that.
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report := FairnessReport{Tallies: tallies}
+			report.applyTargets(tc.targets)
+			var got []string
+			for _, imb := range report.Imbalances {
+				got = append(got, fmt.Sprintf("%s %s %.2f %.2f %.2f", imb.Person, imb.Category, imb.PerWeek, imb.Low, imb.High))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("imbalances are %q, want %q", got, tc.want)
+			}
+			if report.Means[LateCategory] != 1 {
+				t.Errorf("late mean is %g, want 1", report.Means[LateCategory])
+			}
+		})
+	}
+}
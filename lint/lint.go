@@ -22,7 +22,6 @@ import (
 	ct "github.com/daviddengcn/go-colortext"
 	ctfmt "github.com/daviddengcn/go-colortext/fmt"
 	flag "github.com/spf13/pflag"
-	"github.com/stoewer/go-strcase"
 	"github.com/tealeg/xlsx/v3"
 	"github.com/umahmood/soundex"
 	//"flag"
@@ -147,9 +146,14 @@ import (
    4 Jun 26 -- Another format change for the schedule I have to account for.  I'll do that in the definition of the row names.
   18 Jul 26 -- Another format change for the schedule I have to account for.  I had to fix the categoryNamesListForDisplay, and the rowNames to distinguish between the ON-Call Interventional
 				and the ON-Call Radiologist.
+  18 Oct 26 -- The 3 checks in ScanXLSfile are now rules that can be set in the config file, in rules.go.  Added the count, only, and maxperweek rules.
+  18 Oct 26 -- Split ReadWorkWeek out of ScanXLSfile, so fairness.go can read many weeks the same way.
+  18 Oct 26 -- ScanXLSfile is now a wrapper for CheckXLSfile, which returns the violations as structured values.  The lintgui pgms still get the messages.
+  18 Oct 26 -- The names in a cell are now matched against the Roster in roster.go, so kim doesn't match kimberly any more.  whosRemoteToday returns roster IDs.
+  18 Oct 26 -- A config file of only the off line printed an EOF error to stdout, which broke the json and csv output.  EOF there isn't an error.
 */
 
-const LastModified = "19 July 2026"
+const LastModified = "18 Oct 2026"
 const conf = "lint.conf"
 const ini = "lint.ini"
 const numOfDocs = 40 // used to dimension a string slice.
@@ -309,12 +313,10 @@ func FindAndReadConfIni() ([]string, string, error) {
 		fmt.Printf(" In FindAndReadConfIni after 2nd ReadLine BytesReader.Len=%d, and .size=%d, inputline=%q\n",
 			bytesReader.Len(), bytesReader.Size(), inputLine)
 	}
-	if err != nil {
-		fmt.Printf(" Error reading 2nd config line: %s\n", err)
-		if errors.Is(err, io.EOF) {
-			return docNames, "", nil
-		}
-		return docNames, "", err
+	if errors.Is(err, io.EOF) { // a config file of only the off line is fine.
+		return docNames, "", nil
+	} else if err != nil {
+		return docNames, "", fmt.Errorf("reading 2nd config line: %w", err)
 	}
 
 	trimmedInputLine, ok := strings.CutPrefix(inputLine, "startdirectory") // CutPrefix became available as of Go 1.20
@@ -403,6 +405,9 @@ func whosRemoteToday(week WorkWeekType, dayCol int) []string { // week is an arr
 				if prevField == "dr." { // skip if there is no doc name in this cell
 					continue
 				}
+				if id, ok := Roster.Match(strings.Trim(prevField, ".")); ok { // the rules use the roster IDs
+					prevField = id
+				}
 				remoteDocs = append(remoteDocs, prevField)
 			}
 		}
@@ -518,15 +523,52 @@ func GetNeuroRowOffset(wb *xlsx.File) (int, error) {
 	return 0, fmt.Errorf("neuro row not found")
 }
 
-// ScanXLSfile -- takes a filename and checks for 3 errors; vacation people assigned to work, fluoro also late person, and fluoro also remote person.
+// ReadWorkWeek sets rowOffset for this workbook, and then reads Monday thru Friday into the array for the entire work week.  Used by ScanXLSfile and BuildFairnessReport.
+func ReadWorkWeek(workBook *xlsx.File) (WorkWeekType, error) {
+	var err error
+	rowOffset, err = GetNeuroRowOffset(workBook)
+	if err != nil {
+		return WorkWeekType{}, err
+	}
+	rowOffset-- // subtract one because I need the offset for the dateLine.
+	if VerboseFlag {
+		ctfmt.Printf(ct.Yellow, true, " dateLine rowOffset = %d\n", rowOffset)
+	}
+
+	// Populate the wholeWorkWeek's schedule
+	var wholeWorkWeek WorkWeekType       // [6]dayType  Only need 5 workdays.  Element 0 is not used.
+	for i := monday; i < saturday; i++ { // Monday = 1, Friday = 5
+		wholeWorkWeek[i], err = ReadEntireDay(workBook, i) // the subscripts are reversed, as a column represents a day.  Each row is a different subspeciality.
+		if err != nil {
+			fmt.Printf("Error reading day %d: %s, skipping\n", i, err)
+			continue
+		}
+	}
+	return wholeWorkWeek, nil
+}
+
+// ScanXLSfile -- takes a filename and checks the week against the Rules, which by default are the 3 checks it always did; vacation people assigned to work,
+// fluoro also late person, and fluoro also remote person.
 //
 //		Used to not need to return anything except error to main.
 //	 Now that I'm changing this to include lintGUI, I need to change the signature of this routine.  It has to return a []string of messages that
 //	 can be sent to display or a fyne widget.
 //
-// For now, it still writes some strings to the terminal.
+// For now, it still writes some strings to the terminal.  The violations themselves are from CheckXLSfile.
 func ScanXLSfile(filename string) ([]string, error) {
-	var messages []string
+	violations, err := CheckXLSfile(filename)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.String())
+	}
+	return messages, nil
+}
+
+// CheckXLSfile -- takes a filename and returns the structured violations of the Rules, for the lint cmd's json and csv output.
+func CheckXLSfile(filename string) ([]Violation, error) {
 
 	// First this reads the entire file into the array for the entire work week.
 
@@ -536,24 +578,11 @@ func ScanXLSfile(filename string) ([]string, error) {
 		return nil, err
 	}
 
-	rowOffset, err = GetNeuroRowOffset(workBook)
+	wholeWorkWeek, err := ReadWorkWeek(workBook)
 	if err != nil {
 		return nil, err
 	}
-	rowOffset-- // subtract one because I need the offset for the dateLine.
-	if VerboseFlag {
-		ctfmt.Printf(ct.Yellow, true, " dateLine rowOffset = %d\n", rowOffset)
-	}
-
-	// Populate the wholeWorkWeek's schedule
-	var wholeWorkWeek WorkWeekType       // [6]dayType  Only need 5 workdays.  Element 0 is not used.
-	for i := monday; i < saturday; i++ { // Monday = 1, Friday = 5
-		wholeWorkWeek[i], err = ReadEntireDay(workBook, i) // the subscripts are reversed, as a column represents a day.  Each row is a different subspeciality.
-		if err != nil {
-			fmt.Printf("Error reading day %d: %s, skipping\n", i, err)
-			continue
-		}
-	}
+	useNamesIfNoRoster()
 
 	// wholeWorkWeek is now fully populated w/ the data from the Excel file.
 
@@ -636,57 +665,20 @@ func ScanXLSfile(filename string) ([]string, error) {
 			fmt.Printf("\n Late shift docs on day %d are %#v\n", dayCol, lateDocsToday)
 		}
 
-		// Now, mdsOffToday is a slice of several Names of who is off today.
-
-		for _, name := range mdsOffToday {
-			for i := neuro + rowOffset; i < mdOff+rowOffset; i++ { // since mdoff is the last one, can test for < mdOff.  Don't test against MD off as we already know whose off that day.
-				if lower := strings.ToLower(wholeWorkWeek[dayCol][i]); strings.Contains(lower, name) {
-					msg := fmt.Sprintf(" %s is off on %s, but is on %s", strcase.UpperCamelCase(name), DayNames[dayCol], CategoryNamesList[i-rowOffset])
-					messages = append(messages, msg)
-				}
-			}
-		}
-
-		// Now, lateDocsToday is a slice of two Names of who is covering the late shift today.  Only checks against fluoro, as that's not good scheduling
-		for _, name := range lateDocsToday {
-			if lower := strings.ToLower(wholeWorkWeek[dayCol][fluoroJH+rowOffset]); strings.Contains(lower, name) {
-				ctfmt.Printf(ct.Cyan, true, " %s is late on %s, but is on fluoro JH\n", strcase.UpperCamelCase(name), DayNames[dayCol])
-				msg := fmt.Sprintf(" %s is late on %s, but is on fluoro JH", strcase.UpperCamelCase(name), DayNames[dayCol])
-				messages = append(messages, msg)
-			}
-			if lower := strings.ToLower(wholeWorkWeek[dayCol][fluoroFH+rowOffset]); strings.Contains(lower, name) {
-				ctfmt.Printf(ct.Cyan, true, " %s is late on %s, but is on fluoro FH\n", strcase.UpperCamelCase(name), DayNames[dayCol])
-				msg := fmt.Sprintf(" %s is late on %s, but is on fluoro FH", strcase.UpperCamelCase(name), DayNames[dayCol])
-				messages = append(messages, msg)
-			}
-		}
-
-		// Determine if the fluoro doc for today is remote
-		remoteNames := whosRemoteToday(wholeWorkWeek, dayCol)
-		for _, name := range remoteNames {
-			if VeryVerboseFlag {
-				fmt.Printf(" Remote doc for today: %s, FluoroJH: %s, FluoroFH: %s\n",
-					name, wholeWorkWeek[dayCol][fluoroJH+rowOffset], wholeWorkWeek[dayCol][fluoroFH+rowOffset])
-			}
-			if lower := strings.ToLower(wholeWorkWeek[dayCol][fluoroJH+rowOffset]); strings.Contains(lower, name) {
-				ctfmt.Printf(ct.Yellow, true, " %s is remote on %s, but is on fluoro JH\n", strcase.UpperCamelCase(name), DayNames[dayCol])
-				msg := fmt.Sprintf(" %s is remote on %s, but is on fluoro JH", strcase.UpperCamelCase(name), DayNames[dayCol])
-				messages = append(messages, msg)
-			}
-			if lower := strings.ToLower(wholeWorkWeek[dayCol][fluoroFH+rowOffset]); strings.Contains(lower, name) {
-				ctfmt.Printf(ct.Yellow, true, " %s is remote on %s, but is on fluoro FH\n", strcase.UpperCamelCase(name), DayNames[dayCol])
-				msg := fmt.Sprintf(" %s is remote on %s, but is on fluoro FH", strcase.UpperCamelCase(name), DayNames[dayCol])
-				messages = append(messages, msg)
-			}
-		}
-
 		if VeryVerboseFlag {
+			fmt.Printf(" Remote docs on day %d are %#v\n", dayCol, whosRemoteToday(wholeWorkWeek, dayCol))
 			if pause() {
 				return nil, errors.New("exit from pause")
 			}
 		}
 	}
-	return messages, nil
+
+	// The checks that used to be here are now rules, in rules.go.
+	rules := Rules
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	return append(CheckRules(wholeWorkWeek, rules), CheckUnresolved(wholeWorkWeek)...), nil
 }
 
 // CheckRowNames -- true means there's a mismatch, i.e., an unexpected result.
@@ -0,0 +1,35 @@
+package lint
+
+import (
+	"io"
+	"os"
+	"slices"
+	"testing"
+)
+
+// TestFindAndReadConfIniOneLine -- a config file of only the off line is valid, and nothing is written to stdout, as that's where the json or csv goes.
+func TestFindAndReadConfIniOneLine(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir) // FindConfig looks in the current directory first.
+	if err := os.WriteFile(conf, []byte("off smith, kim\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	names, startDir, err := FindAndReadConfIni()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	if err != nil || !slices.Equal(names, []string{"kim", "smith"}) || startDir != "" {
+		t.Errorf("FindAndReadConfIni() = %v, %q, %v, want [kim smith], blank and no error", names, startDir, err)
+	}
+	if len(out) > 0 {
+		t.Errorf("FindAndReadConfIni wrote %q to stdout, want nothing", out)
+	}
+}
@@ -0,0 +1,68 @@
+// Package lint: output.go
+package lint
+
+/*
+  18 Oct 26 -- Writes the violations as text, json or csv, so lint can be run by another pgm or a script.  The text format is the same messages as ScanXLSfile.
+                The json is an array of Violation objects.  The csv has a header line, and the rows are separated by semicolons in one column.
+*/
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// WriteViolations writes the violations to w in the format, which is FormatText, FormatJSON or FormatCSV.
+func WriteViolations(w io.Writer, violations []Violation, format string) error {
+	switch strings.ToLower(format) {
+	case FormatText:
+		for _, violation := range violations {
+			if _, err := fmt.Fprintln(w, violation.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatJSON:
+		if violations == nil {
+			violations = []Violation{} // so it's [], not null
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(violations)
+
+	case FormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write([]string{"rule", "severity", "date", "day", "person", "rows", "message"}); err != nil {
+			return err
+		}
+		for _, v := range violations {
+			record := []string{v.Rule, v.Severity, v.Date, v.Day, v.Person, strings.Join(v.Rows, ";"), v.Msg}
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return fmt.Errorf("format must be text, json or csv, not %q", format)
+}
+
+// HasErrors is true if any violation has error severity, as opposed to only warnings.
+func HasErrors(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Severity != SeverityWarning {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var testViolations = []Violation{
+	{Rule: "off-but-assigned", Severity: SeverityError, Date: "Monday Oct 12 2026", Day: "Monday", Person: "kim", Rows: []string{"MD out of office", "Neuro"},
+		Msg: "Kim is off on Monday, but is on Neuro"},
+	{Rule: "late-limit", Severity: SeverityWarning, Date: "Monday Oct 12 2026", Person: "smith", Rows: []string{"late MD"},
+		Msg: "Smith is late 3 days this week, more than 2"},
+}
+
+func TestWriteViolationsText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteViolations(&buf, testViolations, "TEXT"); err != nil {
+		t.Fatal(err)
+	}
+	want := " Kim is off on Monday, but is on Neuro (rule off-but-assigned)\n" +
+		" Smith is late 3 days this week, more than 2 (rule late-limit, warning)\n"
+	if buf.String() != want {
+		t.Errorf("text is\n%q, want\n%q", buf.String(), want)
+	}
+}
+
+func TestWriteViolationsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteViolations(&buf, testViolations, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v in %s", err, buf.String())
+	}
+	if len(got) != 2 || got[0]["rule"] != "off-but-assigned" || got[0]["message"] != "Kim is off on Monday, but is on Neuro" ||
+		got[1]["severity"] != SeverityWarning || got[1]["day"] != "" || len(got[0]["rows"].([]any)) != 2 {
+		t.Errorf("json is %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteViolations(&buf, nil, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("json of no violations is %q, want []", buf.String())
+	}
+}
+
+func TestWriteViolationsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteViolations(&buf, testViolations, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	want := "rule,severity,date,day,person,rows,message\n" +
+		"off-but-assigned,error,Monday Oct 12 2026,Monday,kim,MD out of office;Neuro,\"Kim is off on Monday, but is on Neuro\"\n" +
+		"late-limit,warning,Monday Oct 12 2026,,smith,late MD,\"Smith is late 3 days this week, more than 2\"\n"
+	if buf.String() != want {
+		t.Errorf("csv is\n%s, want\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteViolationsBadFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteViolations(&buf, testViolations, "xml"); err == nil {
+		t.Error("xml should be an error")
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors(nil) || HasErrors(testViolations[1:]) || !HasErrors(testViolations) {
+		t.Error("HasErrors should be true only when there's a violation that isn't a warning")
+	}
+}
@@ -0,0 +1,254 @@
+// Package lint: roster.go
+package lint
+
+/*
+  18 Oct 26 -- The rules used to match a name by strings.Contains on the lower cased cell, so kim matched kimberly, and a misspelled name escaped every rule.
+                Now each cell is split into words, and each word is matched against the Roster, which gives the canonical ID that the rules and the fairness report use.
+                A word that isn't an alias is matched to the closest doc if it's within 1 edit, or within 2 edits w/ the same Soundex code, and no other doc is as close.
+                Short words need the same Soundex code, and initials have to match exactly.
+                The roster is the doc lines of the config file, after the off and startdirectory lines.  The 1st word is the ID, and the rest are aliases:
+				doc kim       kimberly kimmy kk
+				doc vandyke   van_dyke vd        -- an underscore is 2 words in a row in the cell
+				ignore xray in/out               -- words that aren't names, in addition to the ones in excludeMe
+                If there are no doc lines, each name from GetDocNames is its own ID, so nothing is unresolved.  Otherwise, a word that doesn't resolve is a warning.
+*/
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/umahmood/soundex"
+)
+
+const unresolvedRule = "unresolved-name"
+
+// RosterEntry is one doc; the canonical ID, and the other ways the name is written in the schedule.
+type RosterEntry struct {
+	ID      string
+	Aliases []string // nicknames, initials and other spellings.  2 words are joined w/ an underscore, as in van_dyke.
+}
+
+type resolvedType struct {
+	ids        []string
+	unresolved []string
+}
+
+// RosterType matches the words of a cell to the canonical IDs.  Its zero value has no docs.
+type RosterType struct {
+	Entries    []RosterEntry
+	FromConfig bool              // false means the entries are the names from the schedule.
+	alias      map[string]string // every alias and ID, to the ID
+	ignore     map[string]bool
+	cache      map[string]resolvedType // by cell
+}
+
+// Roster is used by the rules and the fairness report.  If it's not from the config file, CheckXLSfile and BuildFairnessReport make it from Names.
+var Roster RosterType
+
+// ParseRosterEntry parses a doc line of the config file, w/o the word doc.
+func ParseRosterEntry(line string) (RosterEntry, error) {
+	fields := strings.Fields(strings.ToLower(line))
+	if len(fields) == 0 {
+		return RosterEntry{}, fmt.Errorf("doc line needs an ID")
+	}
+	return RosterEntry{ID: fields[0], Aliases: fields[1:]}, nil
+}
+
+// FindAndReadRoster reads the doc and ignore lines from the same config file as FindAndReadRules.
+func FindAndReadRoster() ([]RosterEntry, []string, error) {
+	var entries []RosterEntry
+	fullFile, err := readConfigLines("doc", func(docLine string) error {
+		entry, err := ParseRosterEntry(docLine)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var ignore []string
+	_, err = readConfigLines("ignore", func(ignoreLine string) error {
+		ignore = append(ignore, strings.Fields(strings.ToLower(ignoreLine))...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if VerboseFlag {
+		fmt.Printf(" Read %d docs and %d ignored words from %s: %+v\n", len(entries), len(ignore), fullFile, entries)
+	}
+	return entries, ignore, nil
+}
+
+// NewRoster returns the roster from the config file.  An alias can't belong to 2 docs.
+func NewRoster(entries []RosterEntry, ignore []string) (RosterType, error) {
+	roster := RosterType{Entries: entries, FromConfig: len(entries) > 0, alias: make(map[string]string), ignore: make(map[string]bool),
+		cache: make(map[string]resolvedType)}
+	for _, word := range ignore {
+		roster.ignore[word] = true
+	}
+	for _, entry := range entries {
+		for _, alias := range append([]string{entry.ID}, entry.Aliases...) {
+			if id, ok := roster.alias[alias]; ok && id != entry.ID {
+				return RosterType{}, fmt.Errorf("%s is an alias of both %s and %s", alias, id, entry.ID)
+			}
+			roster.alias[alias] = entry.ID
+		}
+	}
+	return roster, nil
+}
+
+// useNamesIfNoRoster makes the Roster from Names, unless it's from the config file.  Names has to be set for the schedule being checked.
+func useNamesIfNoRoster() {
+	if Roster.FromConfig {
+		return
+	}
+	ignore := make([]string, 0, len(Roster.ignore))
+	for word := range Roster.ignore {
+		ignore = append(ignore, word)
+	}
+	entries := make([]RosterEntry, 0, len(Names))
+	for _, name := range Names {
+		entries = append(entries, RosterEntry{ID: name})
+	}
+	Roster, _ = NewRoster(entries, ignore) // each name is its own ID, so there can't be a conflict.
+	Roster.FromConfig = false
+}
+
+// Canonical returns the ID for a name or alias, or the name itself if it's not in the roster.  Used for the person of an only rule.
+func (roster *RosterType) Canonical(name string) string {
+	if id, ok := roster.Match(name); ok {
+		return id
+	}
+	return name
+}
+
+// Match returns the ID for one word, or 2 words joined w/ an underscore.  An alias is an exact match; otherwise it's the closest doc by Soundex and edit distance.
+func (roster *RosterType) Match(word string) (string, bool) {
+	word = strings.ToLower(word)
+	if id, ok := roster.alias[word]; ok {
+		return id, true
+	}
+	if len(word) < 3 { // initials have to be an alias
+		return "", false
+	}
+	code := soundex.Code(word)
+	bestID, bestDist := "", 3
+	ambiguous := false
+	for alias, id := range roster.alias {
+		if len(alias) < 3 {
+			continue
+		}
+		dist := editDistance(word, alias)
+		if dist > 2 {
+			continue
+		}
+		sameCode := soundex.Code(alias) == code
+		if (dist == 1 && len(word) < 4 && !sameCode) || (dist == 2 && (len(word) < 5 || !sameCode)) {
+			continue // short words need the same Soundex code, and 2 edits need a longer word too.
+		}
+		if dist < bestDist {
+			bestID, bestDist, ambiguous = id, dist, false
+		} else if dist == bestDist && id != bestID {
+			ambiguous = true
+		}
+	}
+	if bestID == "" || ambiguous {
+		return "", false
+	}
+	if VerboseFlag {
+		fmt.Printf(" roster matched %q to %s, %d edit(s) away\n", word, bestID, bestDist)
+	}
+	return bestID, true
+}
+
+// cellWords splits a cell into the lower cased words that could be names, the same way as GetDocNames.
+func (roster *RosterType) cellWords(cell string) []string {
+	s := strings.ToLower(cell)
+	s = strings.ReplaceAll(s, "(*r)", " ") // the remote marker is often right after the name.
+	s = strings.ReplaceAll(s, ",", " ")
+	s = strings.ReplaceAll(s, ".", " ")
+	words := make([]string, 0, 8)
+	for _, field := range strings.Fields(s) {
+		if excludeMe(field) || roster.ignore[field] {
+			continue
+		}
+		words = append(words, field)
+	}
+	return words
+}
+
+// Resolve returns the IDs of the docs in the cell, sorted w/o duplicates, and the words that didn't match anyone.
+func (roster *RosterType) Resolve(cell string) ([]string, []string) {
+	if r, ok := roster.cache[cell]; ok {
+		return r.ids, r.unresolved
+	}
+	var r resolvedType
+	words := roster.cellWords(cell)
+	for i := 0; i < len(words); i++ {
+		if i+1 < len(words) {
+			if id, ok := roster.alias[words[i]+"_"+words[i+1]]; ok {
+				r.ids = append(r.ids, id)
+				i++
+				continue
+			}
+		}
+		if id, ok := roster.Match(words[i]); ok {
+			r.ids = append(r.ids, id)
+		} else {
+			r.unresolved = append(r.unresolved, words[i])
+		}
+	}
+	slices.Sort(r.ids)
+	r.ids = slices.Compact(r.ids)
+	if roster.cache == nil {
+		roster.cache = make(map[string]resolvedType)
+	}
+	roster.cache[cell] = r
+	return r.ids, r.unresolved
+}
+
+// CheckUnresolved returns a warning for each word in the assignment and off rows that isn't in the roster.  Only a roster from the config file can have these.
+func CheckUnresolved(week WorkWeekType) []Violation {
+	if !Roster.FromConfig {
+		return nil
+	}
+	rule := RuleType{Name: unresolvedRule, Severity: SeverityWarning}
+	var violations []Violation
+	rows := append(slices.Clone(RowKeywords["work"]), mdOff)
+	for dayCol := monday; dayCol < saturday; dayCol++ {
+		for _, row := range rows {
+			_, unresolved := Roster.Resolve(week[dayCol][row+rowOffset])
+			for _, word := range unresolved {
+				violations = append(violations, newViolation(week, rule, dayCol, word, []int{row},
+					fmt.Sprintf("%q on %s in %s isn't in the roster", word, DayNames[dayCol], rowName(row))))
+			}
+		}
+	}
+	return violations
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	left, right := []rune(a), []rune(b)
+	previous := make([]int, len(right)+1)
+	for i := range previous {
+		previous[i] = i
+	}
+	for i, l := range left {
+		current := make([]int, len(right)+1)
+		current[0] = i + 1
+		for j, r := range right {
+			cost := 0
+			if l != r {
+				cost = 1
+			}
+			current[j+1] = min(current[j]+1, previous[j+1]+1, previous[j]+cost)
+		}
+		previous = current
+	}
+	return previous[len(right)]
+}
@@ -0,0 +1,117 @@
+package lint
+
+import (
+	"slices"
+	"testing"
+)
+
+func testRoster(t *testing.T) RosterType {
+	t.Helper()
+	roster, err := NewRoster([]RosterEntry{
+		{ID: "kim", Aliases: []string{"kimberly", "kk"}},
+		{ID: "vandyke", Aliases: []string{"van_dyke", "vd"}},
+		{ID: "johnson"},
+		{ID: "smith"},
+		{ID: "mendelsohn"},
+	}, []string{"xray"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return roster
+}
+
+func TestRosterMatch(t *testing.T) {
+	roster := testRoster(t)
+	tests := []struct {
+		name   string
+		word   string
+		wantID string
+		wantOK bool
+	}{
+		{"exact ID", "kim", "kim", true},
+		{"exact ID upper case", "Smith", "smith", true},
+		{"alias", "kimberly", "kim", true},
+		{"initials alias", "KK", "kim", true},
+		{"2 word alias", "van_dyke", "vandyke", true},
+		{"initials not an alias", "js", "", false},
+		{"distance 1", "smyth", "smith", true},
+		{"distance 1 short w/ same soundex", "kym", "kim", true},
+		{"distance 1 short w/ other soundex", "jim", "", false},
+		{"distance 2 w/ same soundex", "jonsen", "johnson", true},
+		{"distance 2 w/ other soundex", "swath", "", false},
+		{"distance 2 too short", "kymm", "", false},
+		{"distance 3", "mendel", "", false},
+		{"unresolved", "zebra", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			id, ok := roster.Match(tc.word)
+			if id != tc.wantID || ok != tc.wantOK {
+				t.Errorf("Match(%q) = %q, %t, want %q, %t", tc.word, id, ok, tc.wantID, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestRosterMatchAmbiguous(t *testing.T) {
+	roster, err := NewRoster([]RosterEntry{{ID: "mann"}, {ID: "mans"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := roster.Match("manx"); ok {
+		t.Errorf("Match(manx) = %q, but it's 1 edit from both mann and mans", id)
+	}
+}
+
+func TestNewRosterConflict(t *testing.T) {
+	_, err := NewRoster([]RosterEntry{{ID: "kim", Aliases: []string{"k"}}, {ID: "kelly", Aliases: []string{"k"}}}, nil)
+	if err == nil {
+		t.Error("NewRoster should fail when an alias belongs to 2 docs")
+	}
+}
+
+func TestRosterResolve(t *testing.T) {
+	roster := testRoster(t)
+	tests := []struct {
+		name           string
+		cell           string
+		wantIDs        []string
+		wantUnresolved []string
+	}{
+		{"aliases and a typo", "Dr. Kimberly, Smyth (*R)", []string{"kim", "smith"}, nil},
+		{"2 words", "Van Dyke", []string{"vandyke"}, nil},
+		{"duplicates", "kim kk kimberly", []string{"kim"}, nil},
+		{"ignore list", "xray kim", []string{"kim"}, nil},
+		{"excludeMe words", "plain film jh", nil, nil},
+		{"unresolved", "johnson zebra", []string{"johnson"}, []string{"zebra"}},
+		{"empty", "", nil, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, unresolved := roster.Resolve(tc.cell)
+			if !slices.Equal(ids, tc.wantIDs) || !slices.Equal(unresolved, tc.wantUnresolved) {
+				t.Errorf("Resolve(%q) = %v, %v, want %v, %v", tc.cell, ids, unresolved, tc.wantIDs, tc.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"smith", "smith", 0},
+		{"smith", "smyth", 1},
+		{"johnson", "jonsen", 2},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+	}
+	for _, tc := range tests {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
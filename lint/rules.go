@@ -0,0 +1,372 @@
+// Package lint: rules.go
+package lint
+
+/*
+  18 Oct 26 -- The checks used to be hard coded in ScanXLSfile; off but assigned, late on fluoro, and remote on fluoro.  Now they're rules, and more can be
+                added to lint.conf w/o changing the code.  The rule lines come after the off and startdirectory lines, and look like this:
+				rule off-but-assigned   notboth    off     work             -- can't be in a row of the 1st list and a row of the 2nd list on the same day
+				rule late-on-fluoro     notboth    late    fluorojh,fluorofh
+				rule remote-on-fluoro   notboth    remote  fluoro
+				rule two-on-late        count      late    2 2              -- the row must have between min and max people every day
+				rule smith-only-body    only       smith   body,msk         -- this person, or alias in the roster, can only be on these rows
+				rule late-limit         maxperweek late    2                -- no one can be in the row more than this many days a week
+				The 2nd field is the rule name, used in the messages.  Row lists are separated by commas, and are the names in RowKeywords.
+				remote isn't a row; it's whoever has the (*R) marker that day.  work is all the assignment rows, from neuro thru on-call radiologist.
+				If there are no rule lines, DefaultRules are used, which are the 3 checks lint always did.
+  18 Oct 26 -- A Violation is now a structured value w/ the rule, severity, date, day, person and row names, so it can be written as json or csv in output.go.
+                A rule line can end w/ a severity, error or warning, as in rule late-limit maxperweek late 2 warning.  The default is error.
+  18 Oct 26 -- The rules now work on the roster IDs from roster.go, instead of strings.Contains on the cell.  The person of an only rule can be an alias.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"src/misc"
+	"src/whichexec"
+	"strconv"
+	"strings"
+
+	"github.com/stoewer/go-strcase"
+)
+
+const remoteRow = -1 // remote is not a row of the schedule, so it gets a row number that can't be one.
+
+// RowKeywords are the row names that can be used in rules.  Some are a group of rows.
+var RowKeywords = map[string][]int{
+	"neuro":             {neuro},
+	"body":              {body},
+	"er":                {erXrays},
+	"stats":             {erXrays},
+	"ir":                {ir},
+	"nuclear":           {nuclear},
+	"us":                {us},
+	"peds":              {peds},
+	"fluorojh":          {fluoroJH},
+	"fluorofh":          {fluoroFH},
+	"fluoro":            {fluoroJH, fluoroFH},
+	"msk":               {msk},
+	"mammo":             {mammo},
+	"density":           {boneDensity},
+	"late":              {late},
+	"oncallir":          {oncallIR},
+	"oncallradiologist": {oncallradiologist},
+	"oncall":            {oncallIR, oncallradiologist},
+	"off":               {mdOff},
+	"remote":            {remoteRow},
+	"work":              {neuro, body, erXrays, ir, nuclear, us, peds, fluoroJH, fluoroFH, msk, mammo, boneDensity, late, oncallIR, oncallradiologist},
+}
+
+// Rule kinds
+const (
+	NotBoth    = "notboth"
+	Count      = "count"
+	Only       = "only"
+	MaxPerWeek = "maxperweek"
+)
+
+// Severities.  A rule is an error unless its line in the config file ends w/ warning.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+type RuleType struct {
+	Name     string
+	Kind     string // NotBoth, Count, Only or MaxPerWeek
+	Severity string // SeverityError or SeverityWarning
+	Rows     []int  // the rows for Count, Only and MaxPerWeek, and the 1st list for NotBoth
+	Rows2    []int  // 2nd list for NotBoth
+	Person   string // for Only
+	Min, Max int    // Min and Max for Count, and Max for MaxPerWeek
+}
+
+// Violation is a rule that's broken.  For a MaxPerWeek rule, Day is empty and Date is Monday's.
+type Violation struct {
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Date     string   `json:"date"`   // the date line of the schedule for that day
+	Day      string   `json:"day"`    // Monday thru Friday
+	Person   string   `json:"person"` // empty for a Count rule
+	Rows     []string `json:"rows"`
+	Msg      string   `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Severity == SeverityWarning {
+		return fmt.Sprintf(" %s (rule %s, warning)", v.Msg, v.Rule)
+	}
+	return fmt.Sprintf(" %s (rule %s)", v.Msg, v.Rule)
+}
+
+// newViolation fills in the date, day, severity and row names.  dayCol is 0 for a MaxPerWeek rule.
+func newViolation(week WorkWeekType, rule RuleType, dayCol int, person string, rows []int, msg string) Violation {
+	v := Violation{Rule: rule.Name, Severity: rule.Severity, Person: person, Msg: msg}
+	if v.Severity == "" {
+		v.Severity = SeverityError
+	}
+	dateCol := dayCol
+	if dayCol == 0 {
+		dateCol = monday
+	} else {
+		v.Day = DayNames[dayCol]
+	}
+	v.Date = strings.Join(strings.Fields(week[dateCol][dateLine+rowOffset]), " ")
+	for _, row := range rows {
+		v.Rows = append(v.Rows, rowName(row))
+	}
+	return v
+}
+
+// Rules are used by ScanXLSfile.  If there are none, it uses DefaultRules.
+var Rules []RuleType
+
+var DefaultRules = []RuleType{
+	{Name: "off-but-assigned", Kind: NotBoth, Severity: SeverityError, Rows: []int{mdOff}, Rows2: RowKeywords["work"]},
+	{Name: "late-on-fluoro", Kind: NotBoth, Severity: SeverityError, Rows: []int{late}, Rows2: []int{fluoroJH, fluoroFH}},
+	{Name: "remote-on-fluoro", Kind: NotBoth, Severity: SeverityError, Rows: []int{remoteRow}, Rows2: []int{fluoroJH, fluoroFH}},
+}
+
+func parseRows(s string) ([]int, error) {
+	var rows []int
+	for _, keyword := range strings.Split(strings.ToLower(s), ",") {
+		r, ok := RowKeywords[strings.TrimSpace(keyword)]
+		if !ok {
+			return nil, fmt.Errorf("unknown row %q", keyword)
+		}
+		rows = append(rows, r...)
+	}
+	slices.Sort(rows)
+	return slices.Compact(rows), nil
+}
+
+// ParseRule parses a rule line of the config file, w/o the word rule.
+func ParseRule(line string) (RuleType, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return RuleType{}, fmt.Errorf("rule %q needs a name, a kind and rows", line)
+	}
+	rule := RuleType{Name: fields[0], Kind: strings.ToLower(fields[1]), Severity: SeverityError}
+	args := fields[2:]
+	if last := strings.ToLower(args[len(args)-1]); last == SeverityError || last == SeverityWarning {
+		rule.Severity = last
+		args = args[:len(args)-1]
+	}
+	wantArgs := map[string]int{NotBoth: 2, Count: 3, Only: 2, MaxPerWeek: 2}
+	n, ok := wantArgs[rule.Kind]
+	if !ok {
+		return RuleType{}, fmt.Errorf("rule %s: kind must be notboth, count, only or maxperweek, not %q", rule.Name, fields[1])
+	}
+	if len(args) != n {
+		return RuleType{}, fmt.Errorf("rule %s: %s takes %d fields after it, and then an optional severity, not %d", rule.Name, rule.Kind, n, len(args))
+	}
+
+	var err error
+	switch rule.Kind {
+	case NotBoth:
+		if rule.Rows, err = parseRows(args[0]); err == nil {
+			rule.Rows2, err = parseRows(args[1])
+		}
+	case Count:
+		if rule.Rows, err = parseRows(args[0]); err == nil {
+			if rule.Min, err = strconv.Atoi(args[1]); err == nil {
+				rule.Max, err = strconv.Atoi(args[2])
+			}
+		}
+		if err == nil && rule.Min > rule.Max {
+			err = fmt.Errorf("min %d is more than max %d", rule.Min, rule.Max)
+		}
+	case Only:
+		rule.Person = strings.ToLower(args[0])
+		rule.Rows, err = parseRows(args[1])
+	case MaxPerWeek:
+		if rule.Rows, err = parseRows(args[0]); err == nil {
+			rule.Max, err = strconv.Atoi(args[1])
+		}
+	}
+	if err != nil {
+		return RuleType{}, fmt.Errorf("rule %s: %w", rule.Name, err)
+	}
+	return rule, nil
+}
+
+// FindAndReadRules reads the rule lines from the same config file as FindAndReadConfIni.  No config file, or no rules in it, returns nil.
+func FindAndReadRules() ([]RuleType, error) {
+	var rules []RuleType
+	fullFile, err := readConfigLines("rule", func(ruleLine string) error {
+		rule, err := ParseRule(ruleLine)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if VerboseFlag {
+		fmt.Printf(" Read %d rules from %s: %+v\n", len(rules), fullFile, rules)
+	}
+	return rules, nil
+}
+
+// readConfigLines calls parse for each line of the config file that begins w/ the keyword, passing the rest of the line w/o a trailing -- comment.
+// It returns the full name of the config file, which is empty if there isn't one.
+func readConfigLines(keyword string, parse func(line string) error) (string, error) {
+	fullFile, found := whichexec.FindConfig(conf)
+	if !found {
+		fullFile, found = whichexec.FindConfig(ini)
+		if !found {
+			return "", nil
+		}
+	}
+	fileByteSlice, err := os.ReadFile(fullFile)
+	if err != nil {
+		return "", err
+	}
+	bytesReader := bytes.NewReader(fileByteSlice)
+	for lineNum := 1; ; lineNum++ {
+		inputLine, err := misc.ReadLine(bytesReader)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		line, ok := strings.CutPrefix(strings.TrimSpace(inputLine), keyword+" ")
+		if !ok {
+			continue // off, startdirectory, the other keywords, comments and blank lines.
+		}
+		if i := strings.Index(line, "--"); i >= 0 { // allow a comment after the line
+			line = line[:i]
+		}
+		if err = parse(line); err != nil {
+			return "", fmt.Errorf("%s line %d: %w", fullFile, lineNum, err)
+		}
+	}
+	return fullFile, nil
+}
+
+// namesInRow returns the roster IDs in a row of the schedule for that day.  The remote row is whoever is marked (*R).
+func namesInRow(week WorkWeekType, dayCol int, row int) []string {
+	if row == remoteRow {
+		return whosRemoteToday(week, dayCol)
+	}
+	ids, _ := Roster.Resolve(week[dayCol][row+rowOffset])
+	return ids
+}
+
+// inRow is true if the roster ID is in the row that day.  The ID doesn't have to be on the schedule, as a rule can name a person who's not on the schedule this week.
+func inRow(week WorkWeekType, dayCol int, row int, id string) bool {
+	return slices.Contains(namesInRow(week, dayCol, row), id)
+}
+
+func rowName(row int) string {
+	if row == remoteRow {
+		return "remote"
+	}
+	return CategoryNamesList[row]
+}
+
+// rowDescription is how a row is described in a message, as in Kim is late on Monday.
+func rowDescription(row int) string {
+	switch row {
+	case mdOff:
+		return "off"
+	case late:
+		return "late"
+	case remoteRow:
+		return "remote"
+	}
+	return "on " + rowName(row)
+}
+
+// CheckRules checks the week against the rules.  The violations are in the order of the rules, and then by day.
+func CheckRules(week WorkWeekType, rules []RuleType) []Violation {
+	var violations []Violation
+	for _, rule := range rules {
+		switch rule.Kind {
+		case NotBoth:
+			violations = append(violations, checkNotBoth(week, rule)...)
+		case Count:
+			violations = append(violations, checkCount(week, rule)...)
+		case Only:
+			violations = append(violations, checkOnly(week, rule)...)
+		case MaxPerWeek:
+			violations = append(violations, checkMaxPerWeek(week, rule)...)
+		}
+	}
+	return violations
+}
+
+func checkNotBoth(week WorkWeekType, rule RuleType) []Violation {
+	var violations []Violation
+	for dayCol := monday; dayCol < saturday; dayCol++ {
+		for _, rowA := range rule.Rows {
+			for _, name := range namesInRow(week, dayCol, rowA) {
+				for _, rowB := range rule.Rows2 {
+					if rowB == rowA || !inRow(week, dayCol, rowB, name) {
+						continue
+					}
+					violations = append(violations, newViolation(week, rule, dayCol, name, []int{rowA, rowB},
+						fmt.Sprintf("%s is %s on %s, but is %s", strcase.UpperCamelCase(name), rowDescription(rowA), DayNames[dayCol], rowDescription(rowB))))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+func checkCount(week WorkWeekType, rule RuleType) []Violation {
+	var violations []Violation
+	for dayCol := monday; dayCol < saturday; dayCol++ {
+		for _, row := range rule.Rows {
+			n := len(namesInRow(week, dayCol, row))
+			if n < rule.Min || n > rule.Max {
+				violations = append(violations, newViolation(week, rule, dayCol, "", []int{row},
+					fmt.Sprintf("%s has %d on %s, but should have %d to %d", rowName(row), n, DayNames[dayCol], rule.Min, rule.Max)))
+			}
+		}
+	}
+	return violations
+}
+
+func checkOnly(week WorkWeekType, rule RuleType) []Violation {
+	var violations []Violation
+	person := Roster.Canonical(rule.Person) // the rule can use an alias.
+	for dayCol := monday; dayCol < saturday; dayCol++ {
+		for _, row := range RowKeywords["work"] {
+			if slices.Contains(rule.Rows, row) || !inRow(week, dayCol, row, person) {
+				continue
+			}
+			violations = append(violations, newViolation(week, rule, dayCol, person, []int{row},
+				fmt.Sprintf("%s is %s on %s, but may not cover it", strcase.UpperCamelCase(person), rowDescription(row), DayNames[dayCol])))
+		}
+	}
+	return violations
+}
+
+func checkMaxPerWeek(week WorkWeekType, rule RuleType) []Violation {
+	var violations []Violation
+	for _, row := range rule.Rows {
+		days := make(map[string]int)
+		var order []string
+		for dayCol := monday; dayCol < saturday; dayCol++ {
+			for _, name := range namesInRow(week, dayCol, row) {
+				if days[name] == 0 {
+					order = append(order, name)
+				}
+				days[name]++
+			}
+		}
+		for _, name := range order {
+			if days[name] > rule.Max {
+				violations = append(violations, newViolation(week, rule, 0, name, []int{row},
+					fmt.Sprintf("%s is %s %d days this week, more than %d", strcase.UpperCamelCase(name), rowDescription(row), days[name], rule.Max)))
+			}
+		}
+	}
+	return violations
+}
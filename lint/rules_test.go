@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useTestRoster sets the Roster and rowOffset for a test, and puts them back after.
+func useTestRoster(t *testing.T) {
+	t.Helper()
+	savedRoster, savedOffset := Roster, rowOffset
+	t.Cleanup(func() { Roster, rowOffset = savedRoster, savedOffset })
+	Roster = testRoster(t)
+	rowOffset = 0
+}
+
+// testWeek makes a week from the cells of each day, by row.  The date line is filled in.
+func testWeek(days map[int]map[int]string) WorkWeekType {
+	var week WorkWeekType
+	for dayCol := monday; dayCol < saturday; dayCol++ {
+		week[dayCol][dateLine] = fmt.Sprintf("%s  Oct %d 2026", DayNames[dayCol], 11+dayCol)
+		for row, cell := range days[dayCol] {
+			week[dayCol][row] = cell
+		}
+	}
+	return week
+}
+
+// brief is a violation as rule/day/person/rows, so the test tables are short.
+func brief(v Violation) string {
+	return strings.Join([]string{v.Rule, v.Day, v.Person, strings.Join(v.Rows, ",")}, "/")
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    RuleType
+		wantErr bool
+	}{
+		{line: "off-but-assigned notboth off work", want: RuleType{Name: "off-but-assigned", Kind: NotBoth, Severity: SeverityError, Rows: []int{mdOff}, Rows2: RowKeywords["work"]}},
+		{line: "two-on-late count late 2 2", want: RuleType{Name: "two-on-late", Kind: Count, Severity: SeverityError, Rows: []int{late}, Min: 2, Max: 2}},
+		{line: "smith-only only Smith msk,body", want: RuleType{Name: "smith-only", Kind: Only, Severity: SeverityError, Person: "smith", Rows: []int{body, msk}}},
+		{line: "late-limit maxperweek late 2 warning", want: RuleType{Name: "late-limit", Kind: MaxPerWeek, Severity: SeverityWarning, Rows: []int{late}, Max: 2}},
+		{line: "fluoro-limit MaxPerWeek fluoro 1 Error", want: RuleType{Name: "fluoro-limit", Kind: MaxPerWeek, Severity: SeverityError, Rows: []int{fluoroJH, fluoroFH}, Max: 1}},
+		{line: "too-few count late", wantErr: true},
+		{line: "missing-max count late 2", wantErr: true},
+		{line: "min-over-max count late 3 2", wantErr: true},
+		{line: "not-a-number maxperweek late two", wantErr: true},
+		{line: "unknown-kind sometimes late 2", wantErr: true},
+		{line: "unknown-row only smith nowhere", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.line, func(t *testing.T) {
+			got, err := ParseRule(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseRule(%q) = %+v, want an error", tc.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRule(%q): %v", tc.line, err)
+			}
+			if got.Name != tc.want.Name || got.Kind != tc.want.Kind || got.Severity != tc.want.Severity || got.Person != tc.want.Person ||
+				got.Min != tc.want.Min || got.Max != tc.want.Max || !slices.Equal(got.Rows, tc.want.Rows) || !slices.Equal(got.Rows2, tc.want.Rows2) {
+				t.Errorf("ParseRule(%q) = %+v, want %+v", tc.line, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	useTestRoster(t)
+	week := testWeek(map[int]map[int]string{
+		monday:    {neuro: "Van Dyke", body: "Kim", fluoroJH: "Smith", late: "Johnson, Kim", mdOff: "vd"},
+		tuesday:   {body: "Smyth", msk: "Johnson", fluoroFH: "Kimberly", late: "Kim Johnson"},
+		wednesday: {neuro: "Johnson (*R)", fluoroJH: "johnson", late: "Kim"},
+		thursday:  {body: "Smith", late: "Johnson Kim"},
+		friday:    {msk: "Smith", late: "Vandyke Smith"},
+	})
+
+	tests := []struct {
+		name string
+		rule string
+		want []string
+	}{
+		{"notboth off", "off-but-assigned notboth off work", []string{"off-but-assigned/Monday/vandyke/MD out of office,Neuro"}},
+		{"notboth late", "late-on-fluoro notboth late fluoro", []string{"late-on-fluoro/Tuesday/kim/late MD,Fluoro FH"}},
+		{"notboth remote", "remote-on-fluoro notboth remote fluoro", []string{"remote-on-fluoro/Wednesday/johnson/remote,Fluoro JH"}},
+		{"count", "two-on-late count late 2 2", []string{"two-on-late/Wednesday//late MD"}},
+		{"count w/ no one", "one-on-nuclear count nuclear 1 1", []string{"one-on-nuclear/Monday//Nuclear Medicine", "one-on-nuclear/Tuesday//Nuclear Medicine",
+			"one-on-nuclear/Wednesday//Nuclear Medicine", "one-on-nuclear/Thursday//Nuclear Medicine", "one-on-nuclear/Friday//Nuclear Medicine"}},
+		{"only", "smith-only only smith body,msk", []string{"smith-only/Monday/smith/Fluoro JH", "smith-only/Friday/smith/late MD"}},
+		{"only w/ an alias", "kim-only only kimberly body,late", []string{"kim-only/Tuesday/kim/Fluoro FH"}},
+		{"maxperweek", "late-limit maxperweek late 3", []string{"late-limit//kim/late MD"}},
+		{"maxperweek under the max", "late-limit maxperweek late 4", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range CheckRules(week, []RuleType{rule}) {
+				got = append(got, brief(v))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("CheckRules(%s) = %q, want %q", tc.rule, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckRulesDateAndSeverity(t *testing.T) {
+	useTestRoster(t)
+	week := testWeek(map[int]map[int]string{tuesday: {late: "Kim Johnson Smith"}})
+	rule, err := ParseRule("late-limit count late 0 2 warning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := CheckRules(week, []RuleType{rule})
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1: %v", len(violations), violations)
+	}
+	v := violations[0]
+	if v.Date != "Tuesday Oct 13 2026" || v.Severity != SeverityWarning || v.String() != " late MD has 3 on Tuesday, but should have 0 to 2 (rule late-limit, warning)" {
+		t.Errorf("got %+v, %q", v, v.String())
+	}
+}
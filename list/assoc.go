@@ -0,0 +1,265 @@
+package list
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+/*
+  REVISION HISTORY
+  -------- -------
+  18 Oct 26 -- File associations for runx, runlst and runlist, instead of the if else chain in each of them that mapped xl, w, p, a and l to a program and a glob.
+                 An association has keys, a command, its args and a default glob.  The keys are letters like xl, extensions like .pdf, or mime types like application/pdf
+                 or image/*.  The key * is for a file that nothing else matches.  The args can have {files}, which is replaced by all the files, or {file}, which runs
+                 the command once for each file.  W/o either, the files go at the end.  A blank command means the shell, for executable extensions on Windows.
+                 The built in table is what the if else chains did, and the user's runassoc.txt in the home directory adds to it, or replaces an entry w/ the same key.
+                 A file w/o an extension gets its mime type sniffed from its first 512 bytes by http.DetectContentType, and then that type, its major type w/ a *,
+                 and then the extensions the mime package knows for it are looked up.  That's what lets runx w/ no letter pick the program for each file.
+                 The file format is one association per line, as in
+                   # keys | command | args | glob
+                   [windows]
+                   xl, x, .xls, .xlsx | excel | | *.xls*
+                   [linux]
+                   .pdf, application/pdf | evince | {file} | *.pdf
+                 Lines before any section are for every OS, and a section named for an OS is only read on that OS.  Args are split on spaces, and there's no quoting.
+*/
+
+// AssocFilename is the name of the user's association file in the home directory.
+const AssocFilename = "runassoc.txt"
+
+// Assoc is a file association.  See the comments at the top of assoc.go.
+type Assoc struct {
+	Keys    []string
+	Command string
+	Args    []string
+	Glob    string
+}
+
+// AssocGroup is files that are run by the same association.
+type AssocGroup struct {
+	Assoc Assoc
+	Files []string
+}
+
+// AssocTable looks up associations by key.  A later entry w/ the same key wins.
+type AssocTable struct {
+	entries []Assoc
+	byKey   map[string]int
+}
+
+const builtinAssocs = `
+# keys | command | args | glob
+[windows]
+xl, x, .xls, .xlsx, .xlsm  | excel       |         | *.xls*
+w, .doc, .docx, .rtf       | winword     |         | *.doc*
+p, .ppt, .pptx             | powerpnt    |         | *.ppt*
+a, .mdb, .accdb            | msaccess    |         | *.mdb
+l, .odt, .ods, .odp        | libreoffice |         | *
+*                          |             |         | *
+
+[linux]
+xl, x, .xls, .xlsx, .xlsm, .ods  | libreoffice | --calc {files}    | *.xls*
+w, .doc, .docx, .rtf, .odt       | libreoffice | --writer {files}  | *.doc*
+p, .ppt, .pptx, .odp             | libreoffice | --impress {files} | *.ppt*
+a, .mdb, .accdb, .odb            | libreoffice | --base {files}    | *.mdb
+l                                | libreoffice |                   | *
+*                                | xdg-open    | {file}            | *
+`
+
+// ------------------------------------------------ ReadAssocs ---------------------------------------------------------
+
+// ReadAssocs reads the associations for goos in the format at the top of assoc.go.
+func ReadAssocs(r io.Reader, goos string) ([]Assoc, error) {
+	var assocs []Assoc
+	section := ""
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		if section != "" && section != "all" && section != goos {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, fmt.Errorf("line %d: %q is not keys | command | args | glob", lineNum, line)
+		}
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		var a Assoc
+		for _, key := range strings.Split(fields[0], ",") {
+			if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+				a.Keys = append(a.Keys, key)
+			}
+		}
+		if len(a.Keys) == 0 {
+			return nil, fmt.Errorf("line %d: %q has no keys", lineNum, line)
+		}
+		a.Command = strings.TrimSpace(fields[1])
+		a.Args = strings.Fields(fields[2])
+		a.Glob = strings.TrimSpace(fields[3])
+		assocs = append(assocs, a)
+	}
+	return assocs, scanner.Err()
+} // ReadAssocs
+
+// ------------------------------------------------ LoadAssocTable -----------------------------------------------------
+
+// NewAssocTable returns a table of assocs, where a later one w/ the same key as an earlier one replaces it for that key.
+func NewAssocTable(assocs []Assoc) *AssocTable {
+	t := &AssocTable{byKey: make(map[string]int)}
+	for _, a := range assocs {
+		t.entries = append(t.entries, a)
+		for _, key := range a.Keys {
+			t.byKey[key] = len(t.entries) - 1
+		}
+	}
+	return t
+} // NewAssocTable
+
+// LoadAssocTable returns the built in associations for this OS, w/ those of ~/runassoc.txt added.  If that file can't be read, the error is returned
+// along w/ the built in table, so the caller can report it and go on.
+func LoadAssocTable() (*AssocTable, error) {
+	assocs, err := ReadAssocs(strings.NewReader(builtinAssocs), runtime.GOOS)
+	if err != nil {
+		return nil, err // only if the built in table has a typo.
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return NewAssocTable(assocs), nil
+	}
+	f, err := os.Open(filepath.Join(homeDir, AssocFilename))
+	if err != nil {
+		return NewAssocTable(assocs), nil // not having one is fine.
+	}
+	defer f.Close()
+	userAssocs, err := ReadAssocs(f, runtime.GOOS)
+	if err != nil {
+		return NewAssocTable(assocs), fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return NewAssocTable(append(assocs, userAssocs...)), nil
+} // LoadAssocTable
+
+// ------------------------------------------------ Lookup -------------------------------------------------------------
+
+// Lookup returns the association for a key, which is a letter, an extension w/ its dot, or a mime type.
+func (t *AssocTable) Lookup(key string) (Assoc, bool) {
+	i, ok := t.byKey[strings.ToLower(key)]
+	if !ok {
+		return Assoc{}, false
+	}
+	return t.entries[i], true
+}
+
+// ForFile returns the association for a file by its extension.  A file w/o an extension is sniffed for its mime type.  If neither finds one, it's the * entry.
+func (t *AssocTable) ForFile(filename string) (Assoc, bool) {
+	if ext := filepath.Ext(filename); ext != "" {
+		if a, ok := t.Lookup(ext); ok {
+			return a, true
+		}
+	} else if mimeType := SniffMIME(filename); mimeType != "" {
+		keys := []string{mimeType}
+		if major, _, ok := strings.Cut(mimeType, "/"); ok {
+			keys = append(keys, major+"/*")
+		}
+		exts, _ := mime.ExtensionsByType(mimeType)
+		keys = append(keys, exts...)
+		for _, key := range keys {
+			if a, ok := t.Lookup(key); ok {
+				return a, true
+			}
+		}
+	}
+	return t.Lookup("*")
+} // ForFile
+
+// SniffMIME returns the mime type of a file from its first 512 bytes, w/o the params like charset.  It's blank if the file can't be read.
+func SniffMIME(filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if n == 0 && err != nil {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+} // SniffMIME
+
+// Group puts the files into groups by their association, in the order the files come in.  The files that have no association are returned separately.
+func (t *AssocTable) Group(files []string) ([]AssocGroup, []string) {
+	var groups []AssocGroup
+	var unmatched []string
+	groupOf := make(map[string]int) // by the command and args, as the same association can be found by different keys.
+	for _, f := range files {
+		a, ok := t.ForFile(f)
+		if !ok {
+			unmatched = append(unmatched, f)
+			continue
+		}
+		id := a.Command + "\x00" + strings.Join(a.Args, "\x00")
+		i, ok := groupOf[id]
+		if !ok {
+			i = len(groups)
+			groupOf[id] = i
+			groups = append(groups, AssocGroup{Assoc: a})
+		}
+		groups[i].Files = append(groups[i].Files, f)
+	}
+	return groups, unmatched
+} // Group
+
+// ------------------------------------------------ Commands -----------------------------------------------------------
+
+// Commands returns the args for each run of the command on files.  It's one run for all the files, unless the args have {file}.
+func (a Assoc) Commands(files []string) [][]string {
+	perFile, hasFiles := false, false
+	for _, arg := range a.Args {
+		perFile = perFile || arg == "{file}"
+		hasFiles = hasFiles || arg == "{files}"
+	}
+	expand := func(fs []string) []string {
+		args := make([]string, 0, len(a.Args)+len(fs))
+		for _, arg := range a.Args {
+			if arg == "{files}" || arg == "{file}" {
+				args = append(args, fs...)
+			} else {
+				args = append(args, arg)
+			}
+		}
+		if !perFile && !hasFiles {
+			args = append(args, fs...)
+		}
+		return args
+	}
+	if !perFile {
+		return [][]string{expand(files)}
+	}
+	runs := make([][]string, 0, len(files))
+	for _, f := range files {
+		runs = append(runs, expand([]string{f}))
+	}
+	return runs
+} // Commands
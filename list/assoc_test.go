@@ -0,0 +1,117 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAssocs = `
+# keys | command | args | glob
+.txt, text/plain | less |  | *.txt
+[linux]
+xl, x, .xlsx | libreoffice | --calc {files} | *.xls*
+.pdf, application/pdf | evince | {file} | *.pdf
+image/* | feh | | *
+* | xdg-open | {file} | *
+[windows]
+xl | excel | | *.xls*
+[linux]
+.xlsx | gnumeric
+`
+
+func TestReadAssocs(t *testing.T) {
+	assocs, err := ReadAssocs(strings.NewReader(testAssocs), "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assocs) != 6 {
+		t.Fatalf("there should be 6 associations for linux, but there are %d: %v", len(assocs), assocs)
+	}
+	want := Assoc{Keys: []string{"xl", "x", ".xlsx"}, Command: "libreoffice", Args: []string{"--calc", "{files}"}, Glob: "*.xls*"}
+	if !reflect.DeepEqual(assocs[1], want) {
+		t.Errorf("the 2nd association should be %v, but it's %v", want, assocs[1])
+	}
+
+	tbl := NewAssocTable(assocs)
+	if a, ok := tbl.Lookup("XL"); !ok || a.Command != "libreoffice" {
+		t.Errorf("XL should be libreoffice, but it's %v, %t", a, ok)
+	}
+	if a, ok := tbl.Lookup(".xlsx"); !ok || a.Command != "gnumeric" {
+		t.Errorf(".xlsx should be gnumeric from the later line, but it's %v, %t", a, ok)
+	}
+	if _, ok := tbl.Lookup("w"); ok {
+		t.Errorf("w is not in the table, so it should not be found")
+	}
+
+	windows, err := ReadAssocs(strings.NewReader(testAssocs), "windows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[1].Command != "excel" {
+		t.Errorf("windows should have the .txt and xl associations, but it has %v", windows)
+	}
+
+	for _, bad := range []string{"xl excel", " | excel", "a | b | c | d | e"} {
+		if _, err := ReadAssocs(strings.NewReader(bad), "linux"); err == nil {
+			t.Errorf("%q should be an error", bad)
+		}
+	}
+}
+
+func TestForFile(t *testing.T) {
+	assocs, err := ReadAssocs(strings.NewReader(testAssocs), "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := NewAssocTable(assocs)
+	dir := t.TempDir()
+	files := map[string]string{
+		"report.PDF": "",
+		"notes":      "just some text\n",
+		"scan":       "%PDF-1.4\n",
+		"picture":    "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"unknown":    "\x00\x01\x02\x03",
+		"data.xyz":   "",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wants := map[string]string{"report.PDF": "evince", "notes": "less", "scan": "evince", "picture": "feh", "unknown": "xdg-open", "data.xyz": "xdg-open"}
+	for name, want := range wants {
+		if a, ok := tbl.ForFile(filepath.Join(dir, name)); !ok || a.Command != want {
+			t.Errorf("%s should be run by %s, but it's %v, %t", name, want, a, ok)
+		}
+	}
+
+	paths := []string{filepath.Join(dir, "scan"), filepath.Join(dir, "notes"), filepath.Join(dir, "report.PDF")}
+	groups, unmatched := tbl.Group(paths)
+	if len(groups) != 2 || len(unmatched) != 0 {
+		t.Fatalf("there should be 2 groups and nothing unmatched, but it's %v and %v", groups, unmatched)
+	}
+	if groups[0].Assoc.Command != "evince" || !reflect.DeepEqual(groups[0].Files, []string{paths[0], paths[2]}) {
+		t.Errorf("the first group should be evince w/ scan and report.PDF, but it's %v", groups[0])
+	}
+}
+
+func TestCommands(t *testing.T) {
+	files := []string{"a", "b"}
+	tests := []struct {
+		args []string
+		want [][]string
+	}{
+		{nil, [][]string{{"a", "b"}}},
+		{[]string{"--calc"}, [][]string{{"--calc", "a", "b"}}},
+		{[]string{"--calc", "{files}", "--norestore"}, [][]string{{"--calc", "a", "b", "--norestore"}}},
+		{[]string{"-f", "{file}"}, [][]string{{"-f", "a"}, {"-f", "b"}}},
+	}
+	for _, tc := range tests {
+		if got := (Assoc{Args: tc.args}).Commands(files); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Commands w/ args %v is %v, but it should be %v", tc.args, got, tc.want)
+		}
+	}
+}
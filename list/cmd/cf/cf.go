@@ -97,9 +97,10 @@ import (
    7 Jun 26 -- Changed CopyAFile to that written and debugged in cf4, to handle symlinks correcctly.  And added the sym flag.
 				Changed completion message to match that of cf3 and cf4.
   13 Jun 26 -- Changed some of the flags so I could use single dash abbrev single character flags.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "13 June 2026" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -198,6 +199,8 @@ func main() {
 
 	symFlag := flag.Bool("sym", false, "Copy symlinks only, ie, skip regular files.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if flag.NArg() < 2 {
@@ -1,6 +1,8 @@
 package main // cf2, from cf, for copy fanout.  This one is truly a fanout pattern.
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	//"flag" removed 6/2/26
 	"fmt"
 	"io"
@@ -11,7 +13,6 @@ import (
 	"src/few"
 	"src/list"
 	"strings"
-	"sync"
 	"time"
 
 	ct "github.com/daviddengcn/go-colortext"
@@ -104,9 +105,18 @@ import (
    2 Jun 26 -- Added pflag to replace flag, imported as flag.
    7 Jun 26 -- Changed CopyAFile to that written and debugged in cf4, to handle symlinks correcctly.  And added the sym flag.
 				Changed completion message to match that of cf3 and cf4.
+   18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
+   18 Oct 26 -- Added the recurse, maxdepth, dirinclude and direxclude flags.  The directory tree is recreated under the destination.
+   18 Oct 26 -- Each run writes a manifest of what it copied, to the manifest flag's file or one in the user's cache directory.  See list/manifest.go.
+				Added the resume flag, which copies again what a manifest says is not verified.  The not newer check is skipped only for a destination
+				the run created or wrote to, so a partial file is replaced, but a file that was newer before the run is kept.
+				And the rollback flag, which removes what a manifest says was created.  Both take a manifest filename instead of the src and dest params.
+				Moved the copying part of main to copyFiles, so resume can use it too.  copyAFile computes the sha256 of the source as it copies.
+   18 Oct 26 -- The manifest code that was here and in cf4 is now in list/copyrun.go.  copyAFile returns its result to list.CopyFiles instead of sending it on msgChan.
+				Resume no longer forces the copy of a file whose destination was there and newer before the run; that lost the newer file.
 */
 
-const LastAltered = "7 June 2026"
+const LastAltered = "18 Oct 2026"
 
 const defaultHeight = 40
 const minWidth = 90
@@ -122,6 +132,7 @@ type msgType struct {
 	elapsed     time.Duration
 	success     bool
 	verified    bool
+	skipped     bool // the destination is not older, so it wasn't copied.
 	bytesCopied int64
 }
 
@@ -131,14 +142,10 @@ var onWin = runtime.GOOS == "windows"
 //	Week of Feb 2024, Miki Tebeka gave an ultimate Go class.  In it he says that I/O bound work is not limited by runtime.NumCPU(), only cpu bound work is.
 //
 // var ErrNotNew error the race detector flagged this as a data race w/ multiple goroutines writing to this one variable.  It should not have ever been global.
-var msgChan chan msgType
-var wg sync.WaitGroup
-var succeeded, failed int64
 var verifyFlag, verFlag bool
 var multiplier int
 
 func main() {
-	var totalBytesCopied int64
 	t1 := time.Now()
 	execName, err := os.Executable()
 	if err != nil {
@@ -199,9 +206,20 @@ func main() {
 
 	symFlag := flag.Bool("sym", false, "Copy symlinks only, ie, skip regular files.")
 
+	flag.BoolVar(&list.RecurseFlag, "recurse", false, "Walk the subdirectories too, and recreate them under the destination.")
+	flag.IntVar(&list.MaxDepth, "maxdepth", 0, "How many levels of subdirectories to walk w/ recurse.  0 is no limit.")
+	var dirIncludePattern, dirExcludePattern string
+	flag.StringVar(&dirIncludePattern, "dirinclude", "", "w/ recurse, only copy from the directories whose subpath matches this regex.")
+	flag.StringVar(&dirExcludePattern, "direxclude", "", "w/ recurse, skip the directories whose subpath matches this regex, and everything under them.")
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+	var manifestName, resumeName, rollbackName string
+	flag.StringVar(&manifestName, "manifest", "", "filename of the manifest of this run.  Default is a new one in the user's cache directory.")
+	flag.StringVar(&resumeName, "resume", "", "manifest of an interrupted run.  Copies again what isn't verified, so no src or dest params.")
+	flag.StringVar(&rollbackName, "rollback", "", "manifest of a run whose created files are to be removed, so no src or dest params.")
+
 	flag.Parse()
 
-	if flag.NArg() < 2 {
+	if flag.NArg() < 2 && resumeName == "" && rollbackName == "" {
 		ctfmt.Printf(ct.Red, true, " Not enough params on command line.  Two needed, but found %d\n", flag.NArg())
 		return
 	}
@@ -213,6 +231,21 @@ func main() {
 
 	verifyFlag = verifyFlag || verFlag
 
+	if rollbackName != "" {
+		if err := list.RollbackManifest(rollbackName); err != nil {
+			ctfmt.Printf(ct.Red, true, " %s.  Exiting\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if resumeName != "" {
+		if err := list.ResumeManifest(resumeName, fanoutMax, copyAFile); err != nil {
+			ctfmt.Printf(ct.Red, true, " %s.  Exiting\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if verboseFlag {
 		execName, _ := os.Executable()
 		ExecFI, _ := os.Stat(execName)
@@ -245,6 +278,10 @@ func main() {
 	list.ExcludeRex = excludeRegex
 	list.SizeFlag = sizeFlag
 	list.SymFlag = *symFlag
+	if err = list.SetDirRegexps(dirIncludePattern, dirExcludePattern); err != nil {
+		ctfmt.Printf(ct.Red, true, " %s.  Exiting\n", err)
+		os.Exit(1)
+	}
 
 	ctfmt.Printf(ct.Green, onWin, "%50s Set up time took %s \n", " ", time.Since(t1))
 
@@ -335,71 +372,26 @@ func main() {
 		fileList = fileList[0:fanoutMax]
 	}
 
-	// Start the message channel
-	msgChan = make(chan msgType, numOfFiles)
-	go func() {
-		for msg := range msgChan {
-			if msg.success {
-				ctfmt.Printf(msg.color, onWin, " %s\n", msg.s)
-				succeeded++                         // there is only 1 go routine doing this read-modify-write, so this is not a data race
-				totalBytesCopied += msg.bytesCopied // there is only 1 go routine doing this read-modify-write, so this is not a data race
-			} else {
-				ctfmt.Printf(msg.color, onWin, " %s\n", msg.e)
-				failed++ // there is only 1 go routine, so this is not a data race
-			}
-			wg.Done()
-		}
-	}()
-
-	// time to copy the files
-
-	start := time.Now()
-	wg.Add(numOfFiles)
+	manifest := list.NewManifest(manifestName, "cf2", destDir)
+	jobs := make([]list.CopyJob, 0, len(fileList))
 	for _, f := range fileList {
-		go copyAFile(f.AbsPath, destDir) // this is the line for the true fanout pattern.
-	}
-	goRtnsNum := runtime.NumGoroutine()
-
-	wg.Wait()
-	close(msgChan)
-	fmt.Printf(" \n %s:", os.Args[0])
-
-	if succeeded > 0 {
-		magnitudeString, magnitudeColor := list.GetMagnitudeString(totalBytesCopied)
-		ctfmt.Printf(ct.Green, onWin, " Total files copied is %d, ", succeeded)
-		ctfmt.Printf(ct.Magenta, false, "and approx total of bytes copied is ")
-		ctfmt.Printf(magnitudeColor, true, "%s,", magnitudeString)
-	}
-	if failed > 0 {
-		ctfmt.Printf(ct.Red, onWin, " Total files NOT copied is %d, ", failed)
-	}
-	ctfmt.Printf(ct.Cyan, onWin, " total elapsed time is %s using %d go routines for %s.\n", time.Since(start).Round(time.Millisecond), goRtnsNum, os.Args[0])
-
-	if onWin { // because tcc can get confused about its color scheme sometimes.  Probably a bug.  But I'm glad that tcmd+tcc finally works w/ vim.
-		ctfmt.Printf(ct.White, onWin, " Total files processed is %d\n", succeeded+failed)
+		newDirs := list.MissingDirs(filepath.Join(destDir, f.SubDir))
+		dest, err := list.DestDirFor(f, destDir) // the same as destDir unless recursing.
+		if err != nil {
+			err = fmt.Errorf("making the directory for %s: %w", f.AbsPath, err)
+		} else {
+			manifest.AddDirs(newDirs...)
+		}
+		jobs = append(jobs, list.CopyJob{SrcFile: f.AbsPath, DestDir: dest, Err: err})
 	}
-	//if succeeded > 0 {
-	//	magnitudeString, magnitudeColor := list.GetMagnitudeString(totalBytesCopied)
-	//	ctfmt.Printf(ct.Green, onWin, " Total files copied is %d, ", succeeded)
-	//	ctfmt.Printf(ct.Magenta, false, "and approx total of bytes copied is ")
-	//	ctfmt.Printf(magnitudeColor, true, "%s,", magnitudeString)
-	//}
-	//if failed > 0 {
-	//	ctfmt.Printf(ct.Red, onWin, " Total files NOT copied is %d, ", failed)
-	//}
-	//
-	//ctfmt.Printf(ct.Cyan, onWin, " total elapsed time is %s using %d go routines for %s.\n", time.Since(start), goRtnsNum, os.Args[0])
-	//
-	//if onWin { // because tcc can get confused about its color scheme sometimes.  Probably a bug.  But I'm glad that tcmd+tcc finally works w/ vim.
-	//	ctfmt.Printf(ct.White, onWin, " Total files processed is %d\n", succeeded+failed)
-	//}
+	list.CopyFiles(jobs, manifest, copyAFile)
 } // end main
 
 //	------------------------------------ CopyAFile ----------------------------------------------
 //
 // CopyAFile(srcFile, destDir string) where src is a regular full filename.  destDir is a directory
 
-func copyAFile(srcFile, destDir string) {
+func copyAFile(job list.CopyJob) (result list.CopyResult) {
 	// I have to open the file and write it to copy it.
 	// Here, src is a regular file, and dest is a directory.  I have to construct the dest filename using the src filename.
 	// This routine adds the time fudge factor to the copied file, because I discovered on linux that if I don't do this, the routine will not detect the copy timestamp is the same as the source timestamp.
@@ -409,7 +401,13 @@ func copyAFile(srcFile, destDir string) {
 	//
 	// 25 May 2026 -- Adding detection of copying a symlink so to avoid the error described in the top comments in cf4.
 
+	srcFile, destDir, force := job.SrcFile, job.DestDir, job.Force
 	origName := srcFile
+	var srcHash string
+	send := func(msg msgType) { // result is returned to list.CopyFiles, which records it in the manifest.
+		result = list.CopyResult{SrcFile: origName, Msg: msg.s, Err: msg.e, Color: msg.color, Success: msg.success, Verified: msg.verified,
+			Skipped: msg.skipped, BytesCopied: msg.bytesCopied, Hash: srcHash}
+	}
 	t0 := time.Now()
 	inFI, err := os.Lstat(srcFile)
 	if err != nil {
@@ -419,7 +417,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	if inFI.Mode()&os.ModeSymlink != 0 {
@@ -431,7 +429,7 @@ func copyAFile(srcFile, destDir string) {
 				color:   ct.Red,
 				success: false,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 		srcFile = resolved
@@ -445,7 +443,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	defer in.Close()
@@ -458,7 +456,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	if !destFI.IsDir() {
@@ -468,7 +466,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
@@ -478,7 +476,7 @@ func copyAFile(srcFile, destDir string) {
 	//inFI, _ := in.Stat() old way of doing it, before I needed to check for symlinks.  Now inFI is defined above.
 	inFIsec := inFI.ModTime().Unix()
 	outFI, err := os.Stat(outName)
-	if err == nil { // this means that the file exists.  I have to handle a possible collision now.
+	if err == nil && !force { // this means that the file exists.  I have to handle a possible collision now.
 		outFIsec := outFI.ModTime().Unix()
 		if outFIsec >= inFIsec { // this condition is true if the current file in the destDir is the same or newer than the file to be copied here, within 1 sec.  So don't copy the file.
 			ErrNotNew := fmt.Errorf("elapsed %s: %s is not newer than in %s", time.Since(t0).Round(time.Millisecond), baseFile, destDir) // now this is not a data race.
@@ -488,8 +486,9 @@ func copyAFile(srcFile, destDir string) {
 				elapsed: time.Since(t0).Round(time.Millisecond),
 				color:   ct.Red,
 				success: false,
+				skipped: true,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 	}
@@ -501,14 +500,15 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	defer out.Close()
 
 	t0 = time.Now() // redefine t0 now that it's going to try to copy the file.
 	var n int64
-	n, err = io.Copy(out, in)
+	h := sha256.New()
+	n, err = io.Copy(out, io.TeeReader(in, h)) // the hash is for the manifest.
 
 	if err != nil {
 		var msg msgType
@@ -522,7 +522,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 		er := os.Remove(outName)
@@ -535,7 +535,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		} else {
 			msg = msgType{
 				s: "",
@@ -545,11 +545,13 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		}
 		return
 	} // end if err != nil
 
+	srcHash = hex.EncodeToString(h.Sum(nil))
+
 	err = out.Sync()
 	if err != nil {
 		var msg msgType
@@ -566,7 +568,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		} else {
 			msg = msgType{
 				s: "",
@@ -577,7 +579,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		}
 		return
 	}
@@ -591,7 +593,7 @@ func copyAFile(srcFile, destDir string) {
 			elapsed: time.Since(t0),
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
@@ -606,12 +608,12 @@ func copyAFile(srcFile, destDir string) {
 			elapsed: time.Since(t0),
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
 	if verifyFlag {
-		result, err := few.Feq32withNames(srcFile, outName)
+		same, err := few.Feq32withNames(srcFile, outName)
 		if err != nil {
 			msg := msgType{
 				s:        "",
@@ -621,10 +623,10 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
-		if result {
+		if same {
 			msg := msgType{
 				s:           fmt.Sprintf("elapsed %s: %s copied to %s and is VERIFIED", time.Since(t0).Round(time.Millisecond), srcFile, destDir),
 				e:           nil,
@@ -634,7 +636,7 @@ func copyAFile(srcFile, destDir string) {
 				verified:    true,
 				bytesCopied: n,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 		msg := msgType{ // essentially an else clause of the above if result statement.  Flagged by GoLand.
@@ -645,7 +647,7 @@ func copyAFile(srcFile, destDir string) {
 			success:  false,
 			verified: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
@@ -659,7 +661,8 @@ func copyAFile(srcFile, destDir string) {
 		verified:    verifyFlag, // I already know that this flag is false if get here.
 		bytesCopied: n,
 	}
-	msgChan <- msg
+	send(msg)
+	return
 } // end CopyAFile
 
 //func copyAFile(srcFile, destDir string) {
@@ -128,9 +128,10 @@ import (
   25 May 26 -- Getting error of "path cannot be traversed because it contains untrusted mount points."  Perplexity said this is a security feature in windows.  A solution
                 is to check for a symlink and copy the primary file using code provided by Perplexity.
    6 Jun 26 -- Added detection of copying a symlink so to avoid the error described in the top comments.  I did this by copying CopyAFile from cf4 to here.  It was debugged in cf4.
+   18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "6 June 2026" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -229,6 +230,8 @@ func main() {
 
 	symFlag := pflag.Bool("sym", false, "Copy symlinks only, ie, skip regular files.")
 
+	pflag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	pflag.Parse()
 
 	if pflag.NArg() < 2 {
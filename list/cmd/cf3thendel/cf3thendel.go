@@ -128,9 +128,10 @@ import (
 ------------------------------------------------------------------------------------------------------------------------------------------------------
    3 May 26 -- Now called cf3thendel, which will start like cf3, and then will delete the files after successful operations.  Either the file is already in the destination
 				directory or it has to be copied.
+   18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.  And the y flag to skip the delete confirmation.
 */
 
-const LastAltered = "3 May 2026" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -229,6 +230,9 @@ func main() {
 
 	symFlag := pflag.Bool("sym", false, "Copy symlinks only, ie, skip regular files.")
 
+	yesFlag := pflag.Bool("y", false, "Yes to deleting the files after copying, so a script w/ select doesn't need to answer it.")
+	pflag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	pflag.Parse()
 
 	if pflag.NArg() < 2 {
@@ -453,11 +457,13 @@ func main() {
 	}
 
 	// Ask if ok to delete, and then delete these
-	fmt.Printf("\n\n ok to delete these files? (y/N)")
-	var answer string
-	n, err := fmt.Scanln(&answer)
-	if err != nil || n != 1 {
-		return
+	answer := "y"
+	if !*yesFlag {
+		fmt.Printf("\n\n ok to delete these files? (y/N)")
+		n, err := fmt.Scanln(&answer)
+		if err != nil || n != 1 {
+			return
+		}
 	}
 	answer = strings.ToLower(answer)
 	if strings.Contains(answer, "y") {
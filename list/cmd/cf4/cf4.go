@@ -1,6 +1,8 @@
 package main // cf4 from cf3, from cf2, from cf, for copy fanout.  This one is truly a fanout pattern, adds viper and properly handles symlinks.
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -10,7 +12,6 @@ import (
 	"src/few"
 	"src/list"
 	"strings"
-	"sync"
 	"time"
 
 	ct "github.com/daviddengcn/go-colortext"
@@ -129,9 +130,17 @@ import (
                 is to check for a symlink and copy the primary file using code provided by Perplexity.
 ------------------------------------------------------------------------------------------------------------------------------------------------------
   25 May 26 -- Now called cf4.  I intend this to debug the code needed to deal with symlinks without errors, then port that back to cf3.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
+  18 Oct 26 -- Each run writes a manifest of what it copied, to the manifest flag's file or one in the user's cache directory.  See list/manifest.go.
+                Added the resume flag, which copies again what a manifest says is not verified.  The not newer check is skipped only for a destination
+                the run created or wrote to, so a partial file is replaced, but a file that was newer before the run is kept.
+                And the rollback flag, which removes what a manifest says was created.  Both take a manifest filename instead of the src and dest params.
+                Same as cf2.  The manifest flags are not bound to viper, so a manifest filename in the config file can't be used by every run.
+  18 Oct 26 -- The manifest code that was here and in cf2 is now in list/copyrun.go.  copyAFile returns its result to list.CopyFiles instead of sending it on msgChan.
+                Resume no longer forces the copy of a file whose destination was there and newer before the run; that lost the newer file.
 */
 
-const LastAltered = "25 May 2026" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -148,6 +157,7 @@ type msgType struct {
 	elapsed     time.Duration
 	success     bool
 	verified    bool
+	skipped     bool // the destination is not older, so it wasn't copied.
 	bytesCopied int64
 }
 
@@ -155,14 +165,10 @@ var autoWidth, autoHeight int
 var onWin = runtime.GOOS == "windows"
 
 // Week of Feb 2024, Miki Tebeka gave an ultimate Go class.  In it he says that I/O bound work is not limited by runtime.NumCPU(), only cpu bound work is.
-var msgChan chan msgType
-var wg sync.WaitGroup
-var succeeded, failed int64
 var verifyFlag, verFlag bool
 var multiplier int
 
 func main() {
-	var totalBytesCopied int64
 	t1 := time.Now()
 	winflag := runtime.GOOS == "windows" // this is needed because I use it in the color statements, so the colors are bolded only on Windows.
 	execName, err := os.Executable()
@@ -230,9 +236,15 @@ func main() {
 
 	symFlag := pflag.Bool("sym", false, "Copy symlinks only, ie, skip regular files.")
 
+	pflag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+	var manifestName, resumeName, rollbackName string
+	pflag.StringVar(&manifestName, "manifest", "", "filename of the manifest of this run.  Default is a new one in the user's cache directory.")
+	pflag.StringVar(&resumeName, "resume", "", "manifest of an interrupted run.  Copies again what isn't verified, so no src or dest params.")
+	pflag.StringVar(&rollbackName, "rollback", "", "manifest of a run whose created files are to be removed, so no src or dest params.")
+
 	pflag.Parse()
 
-	if pflag.NArg() < 2 {
+	if pflag.NArg() < 2 && resumeName == "" && rollbackName == "" {
 		ctfmt.Printf(ct.Red, true, " Not enough params on command line.  Two needed, but found %d\n", pflag.NArg())
 		return
 	}
@@ -285,6 +297,21 @@ func main() {
 
 	multiplier = viper.GetInt("multiplier")
 
+	if rollbackName != "" {
+		if err := list.RollbackManifest(rollbackName); err != nil {
+			ctfmt.Printf(ct.Red, true, " %s.  Exiting\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if resumeName != "" {
+		if err := list.ResumeManifest(resumeName, fanoutMax, copyAFile); err != nil {
+			ctfmt.Printf(ct.Red, true, " %s.  Exiting\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if verboseFlag {
 		execName, _ = os.Executable()
 		ExecFI, _ := os.Stat(execName)
@@ -408,55 +435,18 @@ func main() {
 		fileList = fileList[0:fanoutMax]
 	}
 
-	// Start the message channel
-	msgChan = make(chan msgType, numOfFiles)
-	go func() {
-		for msg := range msgChan {
-			if msg.success {
-				ctfmt.Printf(msg.color, onWin, " %s\n", msg.s)
-				succeeded++                         // there is only 1 go routine doing this read-modify-write, so this is not a data race
-				totalBytesCopied += msg.bytesCopied // there is only 1 go routine doing this read-modify-write, so this is not a data race
-			} else {
-				ctfmt.Printf(msg.color, onWin, " %s\n", msg.e)
-				failed++ // there is only 1 go routine, so this is not a data race
-			}
-			wg.Done()
-		}
-
-	}()
-
-	// time to copy the files
-
-	start := time.Now()
-	wg.Add(numOfFiles)
+	manifest := list.NewManifest(manifestName, "cf4", destDir)
+	jobs := make([]list.CopyJob, 0, len(fileList))
 	for _, f := range fileList {
-		go copyAFile(f.AbsPath, destDir) // this is the line for the true fanout pattern.
-	}
-	goRtnsNum := runtime.NumGoroutine()
-
-	wg.Wait()
-	close(msgChan)
-	fmt.Printf(" \n %s:", os.Args[0])
-	if succeeded > 0 {
-		magnitudeString, magnitudeColor := list.GetMagnitudeString(totalBytesCopied)
-		ctfmt.Printf(ct.Green, onWin, " Total files copied is %d, ", succeeded)
-		ctfmt.Printf(ct.Magenta, false, "and approx total of bytes copied is ")
-		ctfmt.Printf(magnitudeColor, true, "%s,", magnitudeString)
-	}
-	if failed > 0 {
-		ctfmt.Printf(ct.Red, onWin, " Total files NOT copied is %d, ", failed)
-	}
-	ctfmt.Printf(ct.Cyan, onWin, " total elapsed time is %s using %d go routines for %s.\n", time.Since(start).Round(time.Millisecond), goRtnsNum, os.Args[0])
-
-	if onWin { // because tcc can get confused about its color scheme sometimes.  Probably a bug.  But I'm glad that tcmd+tcc finally works w/ vim.
-		ctfmt.Printf(ct.White, onWin, " Total files processed is %d\n", succeeded+failed)
+		jobs = append(jobs, list.CopyJob{SrcFile: f.AbsPath, DestDir: destDir})
 	}
+	list.CopyFiles(jobs, manifest, copyAFile)
 } // end main
 
 //	------------------------------------ CopyAFile ----------------------------------------------
 //
 // CopyAFile(srcFile, destDir string) where src is a regular, full filename.  destDir is a directory
-func copyAFile(srcFile, destDir string) {
+func copyAFile(job list.CopyJob) (result list.CopyResult) {
 	// I have to open the file and write it to copy it.
 	// Here, src is a regular file, and dest is a directory.  I have to construct the dest filename using the src filename.
 	// This routine adds the time fudge factor to the copied file, because I discovered on linux that if I don't do this, the routine will not detect the copy timestamp is the same as the source timestamp.
@@ -466,7 +456,13 @@ func copyAFile(srcFile, destDir string) {
 	//
 	// 25 May 2026 -- Adding detection of copying a symlink so to avoid the error described in the top comments.
 
+	srcFile, destDir, force := job.SrcFile, job.DestDir, job.Force
 	origName := srcFile
+	var srcHash string
+	send := func(msg msgType) { // result is returned to list.CopyFiles, which records it in the manifest.
+		result = list.CopyResult{SrcFile: origName, Msg: msg.s, Err: msg.e, Color: msg.color, Success: msg.success, Verified: msg.verified,
+			Skipped: msg.skipped, BytesCopied: msg.bytesCopied, Hash: srcHash}
+	}
 	t0 := time.Now()
 	inFI, err := os.Lstat(srcFile)
 	if err != nil {
@@ -476,7 +472,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	if inFI.Mode()&os.ModeSymlink != 0 {
@@ -488,7 +484,7 @@ func copyAFile(srcFile, destDir string) {
 				color:   ct.Red,
 				success: false,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 		srcFile = resolved
@@ -502,7 +498,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	defer in.Close()
@@ -515,7 +511,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	if !destFI.IsDir() {
@@ -525,7 +521,7 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
@@ -535,7 +531,7 @@ func copyAFile(srcFile, destDir string) {
 	//inFI, _ := in.Stat() old way of doing it, before I needed to check for symlinks.  Now inFI is defined above.
 	inFIsec := inFI.ModTime().Unix()
 	outFI, err := os.Stat(outName)
-	if err == nil { // this means that the file exists.  I have to handle a possible collision now.
+	if err == nil && !force { // this means that the file exists.  I have to handle a possible collision now.
 		outFIsec := outFI.ModTime().Unix()
 		if outFIsec >= inFIsec { // this condition is true if the current file in the destDir is the same or newer than the file to be copied here, within 1 sec.  So don't copy the file.
 			ErrNotNew := fmt.Errorf("elapsed %s: %s is not newer than in %s", time.Since(t0).Round(time.Millisecond), baseFile, destDir) // now this is not a data race.
@@ -545,8 +541,9 @@ func copyAFile(srcFile, destDir string) {
 				elapsed: time.Since(t0).Round(time.Millisecond),
 				color:   ct.Red,
 				success: false,
+				skipped: true,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 	}
@@ -558,14 +555,15 @@ func copyAFile(srcFile, destDir string) {
 			color:   ct.Red,
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 	defer out.Close()
 
 	t0 = time.Now() // redefine t0 now that it's going to try to copy the file.
 	var n int64
-	n, err = io.Copy(out, in)
+	h := sha256.New()
+	n, err = io.Copy(out, io.TeeReader(in, h)) // the hash is for the manifest.
 
 	if err != nil {
 		var msg msgType
@@ -579,7 +577,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 		er := os.Remove(outName)
@@ -592,7 +590,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		} else {
 			msg = msgType{
 				s: "",
@@ -602,11 +600,13 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		}
 		return
 	} // end if err != nil
 
+	srcHash = hex.EncodeToString(h.Sum(nil))
+
 	err = out.Sync()
 	if err != nil {
 		var msg msgType
@@ -623,7 +623,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		} else {
 			msg = msgType{
 				s: "",
@@ -634,7 +634,7 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 		}
 		return
 	}
@@ -648,7 +648,7 @@ func copyAFile(srcFile, destDir string) {
 			elapsed: time.Since(t0),
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
@@ -663,12 +663,12 @@ func copyAFile(srcFile, destDir string) {
 			elapsed: time.Since(t0),
 			success: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
 	if verifyFlag {
-		result, err := few.Feq32withNames(srcFile, outName)
+		same, err := few.Feq32withNames(srcFile, outName)
 		if err != nil {
 			msg := msgType{
 				s:        "",
@@ -678,10 +678,10 @@ func copyAFile(srcFile, destDir string) {
 				success:  false,
 				verified: false,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
-		if result {
+		if same {
 			msg := msgType{
 				s:           fmt.Sprintf("elapsed %s: %s copied to %s and is VERIFIED", time.Since(t0).Round(time.Millisecond), srcFile, destDir),
 				e:           nil,
@@ -691,7 +691,7 @@ func copyAFile(srcFile, destDir string) {
 				verified:    true,
 				bytesCopied: n,
 			}
-			msgChan <- msg
+			send(msg)
 			return
 		}
 		msg := msgType{ // essentially an else clause of the above if result statement.  Flagged by GoLand.
@@ -702,7 +702,7 @@ func copyAFile(srcFile, destDir string) {
 			success:  false,
 			verified: false,
 		}
-		msgChan <- msg
+		send(msg)
 		return
 	}
 
@@ -716,5 +716,6 @@ func copyAFile(srcFile, destDir string) {
 		verified:    verifyFlag, // I already know that this flag is false if get here.
 		bytesCopied: n,
 	}
-	msgChan <- msg
+	send(msg)
+	return
 } // end CopyAFile
@@ -88,9 +88,11 @@ import (
   15 Jun 24 -- Changed completion message.
   28 Jul 24 -- Fixed a data race by not making ErrNotNew global.  Doesn't really matter because I now always use cf2, and a little cf.
   22 Sep 25 -- I was able to sort out why the fudgefactor was needed, by using my fstat tool w/ cf3.  Now I can remove it.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
+  18 Oct 26 -- Added the recurse, maxdepth, dirinclude and direxclude flags.  The directory tree is recreated under the destination.
 */
 
-const LastAltered = "22 Sep 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -188,6 +190,13 @@ func main() {
 
 	flag.IntVar(&multiplier, "m", 1, "Multiplier for worker pool.  Default is 1.")
 
+	flag.BoolVar(&list.RecurseFlag, "recurse", false, "Walk the subdirectories too, and recreate them under the destination.")
+	flag.IntVar(&list.MaxDepth, "maxdepth", 0, "How many levels of subdirectories to walk w/ recurse.  0 is no limit.")
+	var dirIncludePattern, dirExcludePattern string
+	flag.StringVar(&dirIncludePattern, "dirinclude", "", "w/ recurse, only copy from the directories whose subpath matches this regex.")
+	flag.StringVar(&dirExcludePattern, "direxclude", "", "w/ recurse, skip the directories whose subpath matches this regex, and everything under them.")
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -229,6 +238,10 @@ func main() {
 	list.GlobFlag = globFlag
 	list.ExcludeRex = excludeRegex
 	list.SizeFlag = sizeFlag
+	if err = list.SetDirRegexps(dirIncludePattern, dirExcludePattern); err != nil {
+		ctfmt.Printf(ct.Red, true, " %s.  Exiting\n", err)
+		os.Exit(1)
+	}
 
 	//fileList, err := list.New(excludeRegex, sizeFlag, Reverse) // fileList used to be []string, but now it's []FileInfoExType.
 	fileList, err := list.New() // fileList used to be []string, but now it's []FileInfoExType.
@@ -340,9 +353,14 @@ func main() {
 	start := time.Now()
 	wg.Add(len(fileList))
 	for _, f := range fileList {
+		dest, err := list.DestDirFor(f, destDir) // the same as destDir unless recursing.
+		if err != nil {
+			msgChan <- msgType{e: fmt.Errorf("making the directory for %s: %w", f.RelPath, err), color: ct.Red}
+			continue
+		}
 		cf := cfType{
 			srcFile: f.RelPath,
-			destDir: destDir,
+			destDir: dest,
 		}
 		cfChan <- cf
 	}
@@ -72,9 +72,10 @@ import (
   31 Mar 23 -- StaticCheck found a few issues.
    5 Apr 23 -- Fixed issue w/ GetDirectoryAliases, also found by staticCheck.
    8 Apr 23 -- Changed list.New signature.
+   18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "8 Apr 2023" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -150,6 +151,8 @@ func main() {
 	flag.BoolVar(&verifyFlag, "verify", false, "Verify copy operation")
 	flag.BoolVar(&verFlag, "ver", false, "Verify copy operation")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
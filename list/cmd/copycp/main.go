@@ -62,9 +62,10 @@ import (
    5 Apr 23 -- Fixed list.CheckDest.
    6 Apr 23 -- Will wait for the shell to finish, so I can time it and be clearer when this routine is finished.
    8 Apr 23 -- Changed list.New signature.
+   18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "8 Apr 2023" //
+const LastAltered = "18 Oct 2026" //
 
 const sepString = string(filepath.Separator)
 
@@ -117,6 +118,8 @@ func main() {
 	var globFlag bool
 	flag.BoolVar(&globFlag, "g", false, "glob flag to use globbing on file matching.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
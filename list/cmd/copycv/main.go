@@ -79,9 +79,10 @@ import (
   28 Apr 23 -- It didn't make maintenance easier.  I made a change there to see if I can delete a copied file that threw an error.
    8 Apr 24 -- Added help text to remind me that this is separate because it defaults to verify on.
   28 Jul 24 -- Corrected data race detected in cf2.  I ErrNotNew is no longer global.  It never should have been, anyway.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "28 July 2024" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -179,6 +180,8 @@ func main() {
 	flag.BoolVar(&verFlag, "ver", false, "Verify copy operation")
 	flag.BoolVar(&noVerifyFlag, "no", false, "Turn off default of verify on.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -45,9 +45,10 @@ import (
    8 Apr 23 -- Changed list.New signature.
    6 Jul 25 -- Will also show total of bytes copied, and made timeFudgeFactor 1 ms, as in the other routines.
   22 Sep 25 -- I was able to sort out why the fudgefactor was needed, by using my fstat tool w/ cf3.  Now I can remove it.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "22 Sep 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -112,6 +113,8 @@ func main() {
 
 	flag.BoolVar(&verifyFlag, "verify", false, "Verify copy operation")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
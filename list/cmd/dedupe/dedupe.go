@@ -0,0 +1,241 @@
+package main // dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"src/list"
+	"strings"
+
+	ct "github.com/daviddengcn/go-colortext"
+	ctfmt "github.com/daviddengcn/go-colortext/fmt"
+	flag "github.com/spf13/pflag"
+)
+
+/*
+18 Oct 26 -- First version.  Finds the duplicate files under the directories on the command line, or the current directory, using list.FindDuplicates.
+               The subdirectories are walked unless recurse=false.  Files are grouped by size, then by the sha256 of their first 64 KB, then by the sha256 of all of it.
+               W/o the action flag, it only reports the duplicate sets.  The action flag is delete, hardlink or symlink, and is done to every file in a set but the one
+               the keep flag picks.  The dry-run flag reports what would be done w/o doing it.  The json flag writes the report as json, for scripts.
+               W/ the json flag, the confirmation and the other messages go to stderr, so stdout is only the json.
+*/
+
+const lastAltered = "18 Oct 2026"
+
+type reportDup struct {
+	Path  string `json:"path"`
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+type reportSet struct {
+	Size       int64       `json:"size"`
+	Hash       string      `json:"sha256"`
+	Keep       string      `json:"keep"`
+	Duplicates []reportDup `json:"duplicates"`
+}
+
+type reportType struct {
+	Action   string      `json:"action,omitempty"`
+	DryRun   bool        `json:"dryrun"`
+	Keep     string      `json:"keep"`
+	Files    int         `json:"files"`
+	Wasted   int64       `json:"wasted"`
+	Resolved int         `json:"resolved"`
+	Failed   int         `json:"failed"`
+	Sets     []reportSet `json:"sets"`
+}
+
+var onWin = runtime.GOOS == "windows"
+
+func main() {
+	flag.Usage = func() {
+		fmt.Printf(" %s last altered %s, and compiled with %s. \n", os.Args[0], lastAltered, runtime.Version())
+		fmt.Printf(" Usage information: %s [flags] [directory ...]\n", os.Args[0])
+		fmt.Printf(" Finds files w/ the same contents, and can delete them or replace them w/ hard links or symlinks to the one that's kept.\n")
+		flag.PrintDefaults()
+	}
+
+	var verboseFlag, veryVerboseFlag bool
+	flag.BoolVarP(&verboseFlag, "verbose", "v", false, "verbose mode, which is same as test mode.")
+	flag.BoolVar(&veryVerboseFlag, "vv", false, "Very verbose debugging option.")
+
+	var excludeRegexPattern string
+	flag.StringVarP(&excludeRegexPattern, "exclude", "x", "", "regex to be excluded from output.")
+
+	flag.BoolVar(&list.RecurseFlag, "recurse", true, "Walk the subdirectories too.")
+	flag.IntVar(&list.MaxDepth, "maxdepth", 0, "How many levels of subdirectories to walk.  0 is no limit.")
+	var dirExcludePattern string
+	flag.StringVar(&dirExcludePattern, "direxclude", "", "skip the directories whose subpath matches this regex, and everything under them.")
+	flag.StringVar(&list.SelectStr, "select", "", "only look at the files selected, as in size=1m.., mtime=7d or rex=jpg$.")
+
+	var minSize int64
+	flag.Int64Var(&minSize, "minsize", 1, "files smaller than this are skipped.  The default skips the empty files.")
+
+	var action, keep string
+	flag.StringVarP(&action, "action", "a", "", "what to do w/ the duplicates: delete, hardlink or symlink.  Default is to only report them.")
+	flag.StringVarP(&keep, "keep", "k", "oldest", "which file of a set to keep: oldest, newest or shortest, which is the shortest path.")
+
+	var dryRunFlag, jsonFlag, yesFlag bool
+	flag.BoolVarP(&dryRunFlag, "dry-run", "n", false, "report what the action would do, w/o doing it.")
+	flag.BoolVar(&jsonFlag, "json", false, "write the report as json.")
+	flag.BoolVarP(&yesFlag, "yes", "y", false, "Yes to the confirmation, so a script doesn't need to answer it.")
+
+	flag.Parse()
+
+	if !jsonFlag {
+		fmt.Printf("%s is compiled w/ %s, last altered %s\n", os.Args[0], runtime.Version(), lastAltered)
+	}
+
+	msgOut := os.Stdout
+	if jsonFlag {
+		msgOut = os.Stderr // so stdout is only the json, even when the confirmation is asked.
+	}
+
+	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
+		verboseFlag = true
+	}
+	list.VerboseFlag = verboseFlag
+	list.VeryVerboseFlag = veryVerboseFlag
+
+	action = strings.ToLower(action)
+	if action != "" && action != list.DedupeDelete && action != list.DedupeHardlink && action != list.DedupeSymlink {
+		fmt.Fprintf(os.Stderr, " action must be %s, %s or %s, not %q.  Exiting.\n", list.DedupeDelete, list.DedupeHardlink, list.DedupeSymlink, action)
+		os.Exit(1)
+	}
+
+	var excludeRegex *regexp.Regexp
+	if excludeRegexPattern != "" {
+		var err error
+		excludeRegex, err = regexp.Compile(strings.ToLower(excludeRegexPattern))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " exclude regex: %s.  Exiting.\n", err)
+			os.Exit(1)
+		}
+	}
+	list.ExcludeRex = excludeRegex
+	if err := list.SetDirRegexps("", dirExcludePattern); err != nil {
+		fmt.Fprintf(os.Stderr, " %s.  Exiting.\n", err)
+		os.Exit(1)
+	}
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var fileList []list.FileInfoExType
+	for _, dir := range dirs {
+		fi, err := os.Stat(dir)
+		if err != nil || !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, " %s is not a directory, err = %v.  Exiting.\n", dir, err)
+			os.Exit(1)
+		}
+		lst, err := list.MyReadDir(dir, excludeRegex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error from list.MyReadDir(%s) is %s.  Exiting.\n", dir, err)
+			os.Exit(1)
+		}
+		fileList = append(fileList, lst...)
+	}
+	if list.SelectStr != "" {
+		var err error
+		fileList, err = list.Select(fileList, list.SelectStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error from list.Select is %s.  Exiting.\n", err)
+			os.Exit(1)
+		}
+	}
+	if verboseFlag {
+		fmt.Fprintf(msgOut, " There are %d files to compare.\n", len(fileList))
+	}
+
+	dups := list.FindDuplicates(fileList, minSize)
+	report := reportType{Action: action, DryRun: dryRunFlag || action == "", Keep: keep, Files: len(fileList), Sets: make([]reportSet, 0, len(dups))}
+	var numDups int
+	for _, d := range dups {
+		if err := list.SortDupSet(d, keep); err != nil {
+			fmt.Fprintf(os.Stderr, " %s.  Exiting.\n", err)
+			os.Exit(1)
+		}
+		rs := reportSet{Size: d.Size, Hash: d.Hash, Keep: d.Files[0].FullPath}
+		for _, f := range d.Files[1:] {
+			rs.Duplicates = append(rs.Duplicates, reportDup{Path: f.FullPath})
+		}
+		report.Sets = append(report.Sets, rs)
+		report.Wasted += d.Wasted()
+		numDups += len(d.Files) - 1
+	}
+
+	if action != "" && !dryRunFlag && numDups > 0 && !yesFlag {
+		fmt.Fprintf(msgOut, " %s %d duplicate files in %d sets (y/N)? ", action, numDups, len(dups))
+		var ans string
+		n, err := fmt.Scanln(&ans)
+		if n == 0 || err != nil || !strings.HasPrefix(strings.ToLower(ans), "y") {
+			fmt.Fprintf(msgOut, "\n ans = %q, which does not begin with y.  Nothing was done.\n", ans)
+			report.DryRun = true
+		}
+	}
+
+	if !report.DryRun {
+		for i := range report.Sets {
+			rs := &report.Sets[i]
+			for j := range rs.Duplicates {
+				dup := &rs.Duplicates[j]
+				if err := list.DedupeFile(rs.Keep, dup.Path, action); err != nil {
+					dup.Error = err.Error()
+					report.Failed++
+					continue
+				}
+				dup.Done = true
+				report.Resolved++
+			}
+		}
+	}
+
+	if jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, " Error writing the json report is %s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printReport(report, numDups)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+} // end main
+
+// printReport -- the text report, one set after another w/ the file that's kept first.
+func printReport(report reportType, numDups int) {
+	verb := map[string]string{list.DedupeDelete: "delete", list.DedupeHardlink: "hardlink", list.DedupeSymlink: "symlink", "": "dup"}[report.Action]
+	for i, rs := range report.Sets {
+		magnitudeString, _ := list.GetMagnitudeString(rs.Size)
+		fmt.Printf("\n Set %d: %d files of %s, sha256 %s\n", i+1, len(rs.Duplicates)+1, strings.TrimSpace(magnitudeString), rs.Hash[:16])
+		ctfmt.Printf(ct.Green, onWin, "   keep     %s\n", rs.Keep)
+		for _, dup := range rs.Duplicates {
+			switch {
+			case dup.Error != "":
+				ctfmt.Printf(ct.Red, onWin, "   %-8s %s: %s\n", verb, dup.Path, dup.Error)
+			case dup.Done:
+				ctfmt.Printf(ct.Yellow, onWin, "   %-8s %s, done\n", verb, dup.Path)
+			case report.Action != "":
+				fmt.Printf("   %-8s %s, would be done\n", verb, dup.Path)
+			default:
+				fmt.Printf("   %-8s %s\n", verb, dup.Path)
+			}
+		}
+	}
+
+	magnitudeString, magnitudeColor := list.GetMagnitudeString(report.Wasted)
+	fmt.Printf("\n %d files compared, %d duplicates in %d sets, wasting ", report.Files, numDups, len(report.Sets))
+	ctfmt.Printf(magnitudeColor, onWin, "%s.\n", strings.TrimSpace(magnitudeString))
+	if !report.DryRun {
+		fmt.Printf(" %d resolved by %s, and %d failed.\n", report.Resolved, report.Action, report.Failed)
+	} else if report.Action != "" {
+		fmt.Printf(" Dry run, so nothing was done.\n")
+	}
+} // printReport
@@ -35,9 +35,10 @@ import (
    8 Apr 23 -- Changed list.New signature.
   22 Apr 23 -- This doesn't work on linux.  I'm chasing this down.  I found the bug.  I needed flag.NArg() but had flag.NFlags() instead.  I hate when that happens.
   24 Aug 24 -- Fixed an error in the message that is seen if I say "n" at the end.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.  And the y flag to skip the delete confirmation.
 */
 
-const LastAltered = "24 Aug 2024" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -90,6 +91,10 @@ func main() {
 	//flag.StringVar(&inputStr, "i", "", "Input source directory which can be a symlink.")
 	//flag.StringVar(&rexStr, "rex", "", "Regular expression inclusion pattern for input files")
 
+	var yesFlag bool
+	flag.BoolVar(&yesFlag, "y", false, "Yes to the delete confirmation, so a script w/ select doesn't need to answer it.")
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -184,17 +189,19 @@ func main() {
 	}
 	fmt.Println()
 	fmt.Printf(" There are %d files in the file list.\n", len(fileList))
-	fmt.Print(" Continue (y/N)? ")
-	var ans string
-	n, err := fmt.Scanln(&ans)
-	if n == 0 || err != nil {
-		fmt.Printf("\n n = %d, err = %s.  Aborting.\n", n, err)
-		os.Exit(1)
-	}
-	ans = strings.ToLower(ans)
-	if !strings.HasPrefix(ans, "y") { // ans doesn't begin w/ y, so abort
-		fmt.Printf("\n ans = %s, which does not begin with y.  Aborting.\n", ans)
-		os.Exit(1)
+	if !yesFlag {
+		fmt.Print(" Continue (y/N)? ")
+		var ans string
+		n, err := fmt.Scanln(&ans)
+		if n == 0 || err != nil {
+			fmt.Printf("\n n = %d, err = %s.  Aborting.\n", n, err)
+			os.Exit(1)
+		}
+		ans = strings.ToLower(ans)
+		if !strings.HasPrefix(ans, "y") { // ans doesn't begin w/ y, so abort
+			fmt.Printf("\n ans = %s, which does not begin with y.  Aborting.\n", ans)
+			os.Exit(1)
+		}
 	}
 
 	// time to delete the files
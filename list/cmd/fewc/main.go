@@ -51,9 +51,10 @@ import (
    8 Apr 23 -- Changed list.New signature.
   30 Jul 24 -- Added a multiplier, based on comments by Miki Tebeka, and implemented in the other routines like cf and cf2.
                  I could make a true fanout version, but I don't think I need it, as I rarely use this routine anyway.  Not like cf2, which I use very often.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "30 July 2024" //
+const LastAltered = "18 Oct 2026" //
 
 const sepString = string(filepath.Separator)
 
@@ -107,6 +108,8 @@ func main() {
 
 	flag.IntVar(&multiplier, "mult", 10, "Multiplier for goroutines, default currently is 10.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
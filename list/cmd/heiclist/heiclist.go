@@ -17,9 +17,10 @@ import (
 /*
 28 July 25 -- Now that heic.go works to convert a single heic -> jpg, I'll write this as a list converter
 29 July 25 -- Now uses os.Create, instead of os.OpenFile.
+18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const lastAltered = "29 July 2025"
+const lastAltered = "18 Oct 2026"
 const jpgExt = ".jpg"
 
 func writeHeicToJpg(heic, jpg string, quality int) error {
@@ -90,6 +91,8 @@ func main() {
 	var quality int
 	flag.IntVarP(&quality, "quality", "q", 100, "quality of the jpg file")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
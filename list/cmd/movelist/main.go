@@ -67,9 +67,10 @@ import (
                  And I fixed part where dest dir is tested.
   27 Oct 25 -- Changing the final message and including more to be displayed under verbose mode.
   28 Oct 25 -- Noticed that I overwrote a slice assignment for variadic param.  I fixed that code.  Probably doesn't matter, though.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "28 Oct 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const sepString = string(filepath.Separator)
 
@@ -122,6 +123,8 @@ func main() {
 	var globFlag bool
 	flag.BoolVar(&globFlag, "g", false, "glob flag to use globbing on file matching.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -63,9 +63,13 @@ import (
    8 Jun 24 -- Updated the help message, because I forgot how this works.
   14 Jun 25 -- Adding output of run start or execcmd.Run or execcmd.Start.
   15 Jun 25 -- Added option to force execcmd.Start.  I didn't do this for runlst or runx yet.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
+  18 Oct 26 -- The letters are now looked up in list.LoadAssocTable, which is the built in associations and those of ~/runassoc.txt, instead of the if else chain.
+                 W/o a letter, or w/ a dot, each file is run by the program associated w/ its extension, or w/ its sniffed mime type if it has no extension.
+                 That's my own executable extensions, and it works the same on linux as on Windows.  The office pgms are looked up by findexec if not on the path.
 */
 
-const LastAltered = "14 June 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -86,7 +90,8 @@ func main() {
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), " %s last altered %s, and compiled with %s. \n", os.Args[0], LastAltered, runtime.Version())
-		fmt.Fprintf(flag.CommandLine.Output(), " Usage information: [ x|w|p|a|l ].  Glob pattern is now set by the -g flag, so only 1 param is allowed.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " Usage information: [ x|w|p|a|l|. ].  Glob pattern is now set by the -g flag, so only 1 param is allowed.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " The letters, and what each file is run by when there's no letter or a dot, are set in ~/%s.\n", list.AssocFilename)
 		fmt.Fprintf(flag.CommandLine.Output(), " In this way, this program works the same on both Windows and Linux.\n")
 		fmt.Fprintf(flag.CommandLine.Output(), " AutoHeight = %d and autoWidth = %d.\n", autoHeight, autoWidth)
 		flag.PrintDefaults()
@@ -124,6 +129,8 @@ func main() {
 	var startFlag bool
 	flag.BoolVar(&startFlag, "start", false, "Use execCmd.Start().")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -161,77 +168,45 @@ func main() {
 	list.SizeFlag = sizeFlag
 	list.GlobFlag = globFlag
 
-	// Need to get the cmdStr.  cmd.exe behaves differently than tcc.exe
+	assocs, err := list.LoadAssocTable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error from list.LoadAssocTable is %s.\n", err)
+		if assocs == nil {
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, " Only the built in associations will be used.\n")
+	}
 
 	var cmdStr, globStr string
+	var assoc *list.Assoc // nil means each file is run by the program associated w/ it, like executable extensions on Windows.
 	var fileList []list.FileInfoExType
-	var err error
 
-	if flag.NArg() == 0 {
-		if runtime.GOOS == "windows" {
-			cmdStr = ""
-		} else {
-			cmdStr = "libreoffice"
-		}
-		globStr = "*"
-		if globString != "" {
-			globStr = globString
-		}
-		fileList, err = list.NewFromGlob(globStr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " Error from list.NewListGlob is %s\n", err)
-			os.Exit(1)
-		}
-	} else if flag.NArg() == 1 { // use default glob string.  Or, I forgot to enter the first param letter.  I'll check.
-		cmdStr = flag.Arg(0) // this means the first param on the command line, if present.  If not present, that's ok and will mean the empty command, like an executable extension on Windows.
-		if cmdStr == "." {
-			cmdStr = "" // this is for windows and executable extensions.
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "xl" || strings.ToLower(cmdStr) == "x" { // These only apply to MS-Office on Windows.
-			cmdStr = "excel"
-			globStr = "*.xls*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "w" {
-			cmdStr = "winword"
-			globStr = "*.doc*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "p" {
-			cmdStr = "powerpnt"
-			globStr = "*.ppt*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "a" {
-			cmdStr = "msaccess"
-			globStr = "*.mdb"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "l" || runtime.GOOS == "linux" {
-			cmdStr = "libreoffice"
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else {
-			fmt.Printf(" First param is not .|xl|x|w|p|a|l.  Glob pattern is now set by -g flag.  Try again.\n")
+	if flag.NArg() > 1 { // Since I'm not allowing globbing on the command line, bash won't screw me up.  This is why I'm not allowing globbing on the command line.
+		fmt.Printf(" First param is not .|xl|x|w|p|a|l.  Glob pattern is now set by -g flag.  Only 1 param is allowed.\n")
+		fmt.Printf(" This is so this pgm works the same on both Windows and Linux.")
+		os.Exit(1)
+	}
+
+	cmdStr = flag.Arg(0) // blank if not present, which means the same as a dot.
+	globStr = "*"
+	if cmdStr != "" && cmdStr != "." {
+		a, ok := assocs.Lookup(cmdStr)
+		if !ok {
+			fmt.Printf(" First param %q is not a dot, or a letter like xl|x|w|p|a|l, or a key in ~/%s.  Glob pattern is now set by -g flag.  Try again.\n", cmdStr, list.AssocFilename)
 			os.Exit(1)
 		}
-		fileList, err = list.NewFromGlob(globStr)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " Error from list.GetFileInfoXFromCommandLine or SkipFirstNewList is %s\n", err)
-			os.Exit(1)
+		assoc = &a
+		cmdStr = a.Command
+		if a.Glob != "" {
+			globStr = a.Glob
 		}
-	} else { // Since I'm not allowing globbing on the command line, bash won't screw me up.  This is why I'm not allowing globbing on the command line.
-		fmt.Printf(" First param is not .|xl|x|w|p|a|l.  Glob pattern is now set by -g flag.  Only 1 param is allowed.\n")
-		fmt.Printf(" This is so this pgm works the same on both Windows and Linux.")
+	}
+	if globString != "" {
+		globStr = globString
+	}
+	fileList, err = list.NewFromGlob(globStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error from list.NewFromGlob is %s\n", err)
 		os.Exit(1)
 	}
 
@@ -276,19 +251,37 @@ func main() {
 		fileNameStr = append(fileNameStr, f.FI.Name())
 	}
 
-	// Time to run the cmd.
+	// Time to run the cmd, once for each association when there's no letter on the command line.
 
-	var cmdPath string
-	var execCmd *exec.Cmd
-	var cmdExe bool
-	variadicParam := make([]string, 0, len(fileNameStr))
+	var groups []list.AssocGroup
+	if assoc != nil {
+		groups = []list.AssocGroup{{Assoc: *assoc, Files: fileNameStr}}
+	} else {
+		var unmatched []string
+		groups, unmatched = assocs.Group(fileNameStr)
+		if len(unmatched) > 0 {
+			fmt.Printf(" No program is associated w/ %v, so they're skipped.\n", unmatched)
+		}
+	}
+	for _, g := range groups {
+		for _, args := range g.Assoc.Commands(g.Files) {
+			if err = runCommand(g.Assoc.Command, args, verboseFlag, startFlag); err != nil {
+				fmt.Printf(" Error returned by running %q %s is %v\n", g.Assoc.Command, args, err)
+			}
+		}
+	}
+} // end main
 
-	//variadicParam := []string{"-C", "vlc"} // This isn't really needed anymore.  I'll leave it here anyway, as a model in case I ever need to do this again.
+// runCommand -- runs cmdStr w/ the args.  A blank cmdStr means the shell, so each file is run by what it's associated with, like executable extensions on Windows.
+// cmd.exe behaves differently than tcc.exe.  tcc uses the -C flag and uses .Start(), while cmd does not use the -C flag and uses .Run(), unless startFlag is set.
+func runCommand(cmdStr string, args []string, verboseFlag, startFlag bool) error {
+	var cmdExe bool
+	variadicParam := make([]string, 0, len(args)+1)
 
 	// For me to be able to pass a variadic param here, I must match the definition of the function, not pass some and then try the variadic syntax.
 	// I got this answer from stack overflow.
 
-	cmdPath = cmdStr
+	cmdPath := cmdStr
 	fmt.Printf(" cmdStr = %q, cmdPath = %q\n", cmdStr, cmdPath)
 	if cmdPath == "" {
 		if runtime.GOOS == "linux" {
@@ -301,27 +294,24 @@ func main() {
 				cmdExe = true // and variadicParam won't have the -C flag
 			}
 		}
-	}
-	variadicParam = append(variadicParam, fileNameStr...)
-	if startFlag {
-		cmdExe = false
-	}
-
-	if cmdStr == "excel" || cmdStr == "winword" || cmdStr == "powerpnt" || cmdStr == "msaccess" {
+	} else if _, e := exec.LookPath(cmdStr); e != nil && runtime.GOOS == "windows" { // the office pgms are not on the path.
 		searchPath := officePath + os.Getenv("PATH")
 		execStr := findexec.Find(cmdStr, searchPath)
 		if execStr == "" {
-			ctfmt.Printf(ct.Red, true, " execStr is blank because could not find %s.  \nsearchPath = %s \n Exiting.\n", cmdStr, searchPath)
-			os.Exit(1)
+			ctfmt.Printf(ct.Red, true, " execStr is blank because could not find %s.  \nsearchPath = %s \n", cmdStr, searchPath)
+			return fmt.Errorf("could not find %s", cmdStr)
 		}
 		cmdPath = execStr
 	}
+	variadicParam = append(variadicParam, args...)
+	if startFlag {
+		cmdExe = false
+	}
 
-	execCmd = exec.Command(cmdPath, variadicParam...)
+	execCmd := exec.Command(cmdPath, variadicParam...)
 
 	if verboseFlag {
-		fmt.Printf(" cmdStr = %s, cmdPath = %s, len of fileNameStr = %d, and filenames in fileNameStr are %v\n",
-			cmdStr, cmdPath, len(fileNameStr), fileNameStr)
+		fmt.Printf(" cmdStr = %s, cmdPath = %s, len of args = %d, and args are %v\n", cmdStr, cmdPath, len(args), args)
 		fmt.Printf(" Len(variadiacParam) = %d, variadiacParam = %#v\n", len(variadicParam), variadicParam)
 	}
 
@@ -336,11 +326,7 @@ func main() {
 		}
 	}
 	if cmdExe {
-		err = execCmd.Run() // will see if this works better when running cmd.exe, likely at work.
-	} else {
-		err = execCmd.Start()
+		return execCmd.Run() // will see if this works better when running cmd.exe, likely at work.
 	}
-	if err != nil {
-		fmt.Printf(" Error returned by running %s %s is %v\n", cmdStr, fileNameStr, err)
-	}
-} // end main
+	return execCmd.Start()
+} // runCommand
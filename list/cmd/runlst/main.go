@@ -65,9 +65,13 @@ import (
   11 Jun 24 -- I'm going to give writing a closure function a shot.  Hey, it looks like it's working.
   14 Jun 25 -- Added output of execCmd if verboseFlag is set.
   21 Jun 25 -- Made a minor change in how params are processed.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
+  18 Oct 26 -- The letters are now looked up in list.LoadAssocTable, which is the built in associations and those of ~/runassoc.txt, instead of the if else chain.
+                 W/o a letter, w/ a dot, or w/ a regexp, each file is run by the program associated w/ its extension, or w/ its sniffed mime type if it has no extension.
+                 That's my own executable extensions, and it works the same on linux as on Windows.  The office pgms are looked up by whichexec if not on the path.
 */
 
-const LastAltered = "21 June 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -89,7 +93,8 @@ func main() {
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), " %s last altered %s, and compiled with %s. \n", os.Args[0], LastAltered, runtime.Version())
-		fmt.Fprintf(flag.CommandLine.Output(), " Usage information: [ x|w|p|a|l ].  Regexp is on the command line, and will supercede the default globbing patterns.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " Usage information: [ x|w|p|a|l|. ].  Regexp is on the command line, and will supercede the default globbing patterns.\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " The letters, and what each file is run by when there's no letter, are set in ~/%s.\n", list.AssocFilename)
 		fmt.Fprintf(flag.CommandLine.Output(), " This program works the same on both Windows and Linux.\n")
 		fmt.Fprintf(flag.CommandLine.Output(), " AutoHeight = %d and autoWidth = %d.\n", autoHeight, autoWidth)
 		flag.PrintDefaults()
@@ -124,6 +129,8 @@ func main() {
 	flag.BoolVar(&globFlag, "G", false, "glob flag to use globbing on file matching.") // essentially ignored.
 	flag.StringVar(&globString, "g", "", "Use this glob string pattern instead of the defaults.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -161,69 +168,45 @@ func main() {
 	list.SizeFlag = sizeFlag
 	list.GlobFlag = globFlag
 
-	// Need to get the cmdStr.  cmd.exe behaves differently than tcc.exe
+	assocs, err := list.LoadAssocTable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error from list.LoadAssocTable is %s.\n", err)
+		if assocs == nil {
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, " Only the built in associations will be used.\n")
+	}
 
 	var cmdStr, globStr string
+	var assoc *list.Assoc // nil means each file is run by the program associated w/ it, like executable extensions on Windows.
 	var fileList []list.FileInfoExType
-	var err error
 
 	processingFunction := func() {
+		globStr = "*"
+		if globString != "" {
+			globStr = globString
+		}
 		if cmdStr == "." {
-			cmdStr = "" // this is for windows and executable extensions.
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if cmdStr == "xl" || cmdStr == "x" { // These only apply to MS-Office on Windows.
-			cmdStr = "excel"
-			globStr = "*.xls*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if cmdStr == "w" {
-			cmdStr = "winword"
-			globStr = "*.doc*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if cmdStr == "p" {
-			cmdStr = "powerpnt"
-			globStr = "*.ppt*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if cmdStr == "a" {
-			cmdStr = "msaccess"
-			globStr = "*.mdb"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if cmdStr == "l" {
-			cmdStr = "libreoffice"
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else { // must be a regexp
-			regex, err = regexp.Compile(cmdStr)
-			if err != nil {
-				fmt.Printf(" Error from regexp.Compile is %s\n", err)
-				return
-			}
-			if runtime.GOOS == "windows" {
-				cmdStr = "" // need this to be empty for executable extensions on Windows.
-			} else {
-				cmdStr = "libreoffice"
+			cmdStr = "" // each file is run by its association.
+			return
+		}
+		if a, ok := assocs.Lookup(cmdStr); ok {
+			assoc = &a
+			cmdStr = a.Command
+			if a.Glob != "" && globString == "" {
+				globStr = a.Glob
 			}
+			return
+		}
+		// must be a regexp, and each file is run by its association.
+		regex, err = regexp.Compile(cmdStr)
+		if err != nil {
+			fmt.Printf(" Error from regexp.Compile is %s\n", err)
 		}
+		cmdStr = ""
 	} // end processingFunction
 
 	if flag.NArg() == 0 {
-		if runtime.GOOS == "windows" {
-			cmdStr = ""
-		} else {
-			cmdStr = "libreoffice"
-		}
 		globStr = "*"
 		if globString != "" {
 			globStr = globString
@@ -309,14 +292,37 @@ func main() {
 		fileNameStr = append(fileNameStr, f.FI.Name())
 	}
 
-	// Time to run the cmd.
+	// Time to run the cmd, once for each association when there's no letter on the command line.
+
+	var groups []list.AssocGroup
+	if assoc != nil {
+		groups = []list.AssocGroup{{Assoc: *assoc, Files: fileNameStr}}
+	} else {
+		var unmatched []string
+		groups, unmatched = assocs.Group(fileNameStr)
+		if len(unmatched) > 0 {
+			fmt.Printf(" No program is associated w/ %v, so they're skipped.\n", unmatched)
+		}
+	}
+	for _, g := range groups {
+		for _, args := range g.Assoc.Commands(g.Files) {
+			if err = runCommand(g.Assoc.Command, args, verboseFlag); err != nil {
+				fmt.Printf(" Error returned by running %q %s is %v\n", g.Assoc.Command, args, err)
+			}
+		}
+	}
+} // end main
 
-	var cmdPath string
-	var execCmd *exec.Cmd
+// runCommand -- runs cmdStr w/ the args.  A blank cmdStr means the shell, so each file is run by what it's associated with, like executable extensions on Windows.
+// cmd.exe behaves differently than tcc.exe.  tcc uses the -C flag and uses .Start(), while cmd does not use the -C flag and uses .Run()
+func runCommand(cmdStr string, args []string, verboseFlag bool) error {
 	var cmd bool
-	variadicParam := make([]string, 0, len(fileNameStr))
+	variadicParam := make([]string, 0, len(args)+1)
+
+	// For me to be able to pass a variadic param here, I must match the definition of the function, not pass some and then try the variadic syntax.
+	// I got this answer from stack overflow.
 
-	cmdPath = cmdStr
+	cmdPath := cmdStr
 	fmt.Printf(" cmdStr = %q, cmdPath = %q\n", cmdStr, cmdPath)
 	if cmdPath == "" {
 		if runtime.GOOS == "linux" {
@@ -324,28 +330,25 @@ func main() {
 		} else { // must be on Windows.
 			cmdPath = strings.ToLower(os.Getenv("COMSPEC"))
 			if strings.Contains(cmdPath, "tcc") {
-				variadicParam = append(variadicParam, "-C") // this is the first string in the variadicParam
+				variadicParam = append(variadicParam, "-C")
 			} else { // running cmd.exe, and likely at work.
 				cmd = true // and variadicParam won't have the -C flag
 			}
 		}
-	}
-	variadicParam = append(variadicParam, fileNameStr...)
-
-	if cmdStr == "excel" || cmdStr == "winword" || cmdStr == "powerpnt" || cmdStr == "msaccess" {
+	} else if _, e := exec.LookPath(cmdStr); e != nil && runtime.GOOS == "windows" { // the office pgms are not on the path.
 		execStr := whichexec.Find(cmdStr, officePath)
 		if execStr == "" {
-			ctfmt.Printf(ct.Red, true, " execStr is blank: could not find %s.  \nofficePath = %s \n Exiting.\n", cmdStr, officePath)
-			os.Exit(1)
+			ctfmt.Printf(ct.Red, true, " execStr is blank: could not find %s.  \nofficePath = %s \n", cmdStr, officePath)
+			return fmt.Errorf("could not find %s", cmdStr)
 		}
 		cmdPath = execStr
 	}
+	variadicParam = append(variadicParam, args...)
 
-	execCmd = exec.Command(cmdPath, variadicParam...)
+	execCmd := exec.Command(cmdPath, variadicParam...)
 
 	if verboseFlag {
-		fmt.Printf(" cmdStr = %s, cmdPath = %s, len of fileNameStr = %d, and filenames in fileNameStr are %v\n",
-			cmdStr, cmdPath, len(fileNameStr), fileNameStr)
+		fmt.Printf(" cmdStr = %s, cmdPath = %s, len of args = %d, and args are %v\n", cmdStr, cmdPath, len(args), args)
 		fmt.Printf(" Len(variadiacParam) = %d, variadiacParam = %#v\n", len(variadicParam), variadicParam)
 	}
 
@@ -360,11 +363,7 @@ func main() {
 		}
 	}
 	if cmd {
-		err = execCmd.Run() // will see if this works better when running cmd.exe, likely at work.
-	} else {
-		err = execCmd.Start()
-	}
-	if err != nil {
-		fmt.Printf(" Error returned by running %s %s is %v\n", cmdStr, fileNameStr, err)
+		return execCmd.Run() // will see if this works better when running cmd.exe, likely at work.
 	}
-} // end main
+	return execCmd.Start()
+} // runCommand
@@ -68,9 +68,10 @@ import (
 ------------------------------------------------------------------------------------------------------------------------------------------------------
    8 Jul 25 -- Now called runrex to make it clear it uses a regular expression as its command line param.
    9 Jun 25 -- Added option to force execcmd.Start.
+   18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const LastAltered = "8 July 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -130,6 +131,8 @@ func main() {
 	var startFlag bool
 	flag.BoolVar(&startFlag, "start", false, "Use execCmd.Start() regardless of tcc or cmd.exe.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -63,9 +63,13 @@ import (
    8 Jun 24 -- Fine-tuned the error message.  I forgot how this worked.
   14 Jun 25 -- Added output of execCmd to verboseFlag.
   21 Jun 25 -- Now using pflag as flag.
+  18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
+  18 Oct 26 -- The letters are now looked up in list.LoadAssocTable, which is the built in associations and those of ~/runassoc.txt, instead of the if else chain.
+                 W/o a letter, or w/ a dot, each file is run by the program associated w/ its extension, or w/ its sniffed mime type if it has no extension.
+                 That's my own executable extensions, and it works the same on linux as on Windows.  The office pgms are looked up by findexec if not on the path.
 */
 
-const LastAltered = "21 June 2025" //
+const LastAltered = "18 Oct 2026" //
 
 const defaultHeight = 40
 const minWidth = 90
@@ -88,7 +92,8 @@ func main() {
 
 	flag.Usage = func() {
 		fmt.Printf(" %s last altered %s, and compiled with %s.  Now uses pflag package. \n", os.Args[0], LastAltered, runtime.Version())
-		fmt.Printf(" Usage information: [ x|w|p|a|l ].  Glob pattern is set by the -g flag, and -rex is implemented.\n")
+		fmt.Printf(" Usage information: [ x|w|p|a|l|. ].  Glob pattern is set by the -g flag, and -rex is implemented.\n")
+		fmt.Printf(" The letters, and what each file is run by when there's no letter or a dot, are set in ~/%s.\n", list.AssocFilename)
 		fmt.Printf(" If have both -rex and -g, -rex is followed and -g is ignored, as is the x|w|p|a pattern.\n")
 		fmt.Printf(" In this way, this program works the same on both Windows and Linux.\n")
 		fmt.Printf(" AutoHeight = %d and autoWidth = %d.\n", autoHeight, autoWidth)
@@ -127,6 +132,8 @@ func main() {
 	var regexStr string
 	flag.StringVar(&regexStr, "rex", "", "Regexp to use for creating the list.")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
@@ -171,102 +178,56 @@ func main() {
 	list.SizeFlag = sizeFlag
 	list.GlobFlag = globFlag
 
-	// Need to get the cmdStr.  cmd.exe behaves differently than tcc.exe
+	assocs, err := list.LoadAssocTable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error from list.LoadAssocTable is %s.\n", err)
+		if assocs == nil {
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, " Only the built in associations will be used.\n")
+	}
 
 	var cmdStr, globStr string
+	var assoc *list.Assoc // nil means each file is run by the program associated w/ it, like executable extensions on Windows.
 	var fileList []list.FileInfoExType
-	var err error
 
-	if flag.NArg() == 0 {
-		if runtime.GOOS == "windows" {
-			cmdStr = ""
-		} else {
-			cmdStr = "libreoffice"
-		}
+	if flag.NArg() > 1 { // Since I'm not allowing globbing on the command line, bash won't screw me up.  This is why I'm not allowing globbing on the command line.
+		fmt.Printf(" Only 1 param is allowed, a letter like xl|x|w|p|a|l, or a dot.  Glob pattern is now set by -g flag, and regex set by -rex.  Try again.\n")
+		fmt.Printf(" globString = %q, regexStr = %q, NArg = %d", globString, regexStr, flag.NArg())
 		if regex != nil {
-			fileList, err = list.NewFromRegexp(regex)
-		} else {
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-			fileList, err = list.NewFromGlob(globStr)
-		}
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " Error from either list.NewFromRegexp or list.NewFromGlob is %s.  Exiting\n", err)
-			os.Exit(1)
-		}
-	} else if flag.NArg() == 1 { // use default glob string.  Or, I forgot to enter the first param letter.  I'll check.
-		cmdStr = flag.Arg(0) // this means the first param on the command line, if present.  If not present, that's ok and will mean the empty command, like an executable extension on Windows.
-		if cmdStr == "." {
-			cmdStr = "" // this is for windows and executable extensions.
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "xl" || strings.ToLower(cmdStr) == "x" { // These only apply to MS-Office on Windows.
-			cmdStr = "excel"
-			globStr = "*.xls*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "w" {
-			cmdStr = "winword"
-			globStr = "*.doc*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "p" {
-			cmdStr = "powerpnt"
-			globStr = "*.ppt*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "a" {
-			cmdStr = "msaccess"
-			globStr = "*.mdb"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if strings.ToLower(cmdStr) == "l" || runtime.GOOS == "linux" {
-			cmdStr = "libreoffice"
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else if cmdStr == "" {
-			globStr = "*"
-			if globString != "" {
-				globStr = globString
-			}
-		} else { // Since I'm not allowing globbing on the command line, bash won't screw me up.  This is why I'm not allowing globbing on the command line.
-			fmt.Printf(" First param is not .|xl|x|w|p|a|l.  Remember that glob pattern is now set by -g flag or regexp set by -rex flag.  And only 1 param is allowed.\n")
-			fmt.Printf(" This is so this pgm works the same on both Windows and Linux.")
-			fmt.Printf(" cmdStr = %q, globStr = %q, globString = %q, regexStr = %q, NArg = %d", cmdStr, globStr, globString, regexStr, flag.NArg())
-			if regex != nil {
-				fmt.Printf(" regex = %s\n", regex.String())
-			}
-			fmt.Println()
-			os.Exit(1)
+			fmt.Printf(" regex = %s\n", regex.String())
 		}
+		fmt.Printf(" Remember that strings may have to be quoted to be recognized as params.\n")
+		fmt.Println()
+		os.Exit(1)
+	}
 
-		if regex != nil {
-			fileList, err = list.NewFromRegexp(regex)
-		} else {
-			fileList, err = list.NewFromGlob(globStr)
-		}
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " Error from list.GetFileInfoXFromCommandLine or SkipFirstNewList is %s\n", err)
+	cmdStr = flag.Arg(0) // blank if not present, which means the same as a dot.
+	globStr = "*"
+	if cmdStr != "" && cmdStr != "." {
+		a, ok := assocs.Lookup(cmdStr)
+		if !ok {
+			fmt.Printf(" First param %q is not a dot, or a letter like xl|x|w|p|a|l, or a key in ~/%s.\n", cmdStr, list.AssocFilename)
+			fmt.Printf(" Remember that glob pattern is now set by -g flag or regexp set by -rex flag.  This is so this pgm works the same on both Windows and Linux.\n")
 			os.Exit(1)
 		}
-	} else { // Since I'm not allowing globbing on the command line, bash won't screw me up.  This is why I'm not allowing globbing on the command line.
-		fmt.Printf(" First param is not .|xl|x|w|p|a|l.  Glob pattern is now set by -g flag, and regex set by -rex.  Try again.\n")
-		fmt.Printf(" cmdStr = %q, globStr = %q, globString = %q, regexStr = %q, NArg = %d", cmdStr, globStr, globString, regexStr, flag.NArg())
-		if regex != nil {
-			fmt.Printf(" regex = %s\n", regex.String())
+		assoc = &a
+		cmdStr = a.Command
+		if a.Glob != "" {
+			globStr = a.Glob
 		}
-		fmt.Printf(" Remember that strings may have to be quoted to be recognized as params.\n")
-		fmt.Println()
+	}
+	if globString != "" {
+		globStr = globString
+	}
+
+	if regex != nil {
+		fileList, err = list.NewFromRegexp(regex)
+	} else {
+		fileList, err = list.NewFromGlob(globStr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " Error from either list.NewFromRegexp or list.NewFromGlob is %s.  Exiting\n", err)
 		os.Exit(1)
 	}
 
@@ -316,19 +277,37 @@ func main() {
 		fileNameStr = append(fileNameStr, f.FI.Name())
 	}
 
-	// Time to run the cmd.
+	// Time to run the cmd, once for each association when there's no letter on the command line.
 
-	var cmdPath string
-	var execCmd *exec.Cmd
-	var cmd bool
-	variadicParam := make([]string, 0, len(fileNameStr))
+	var groups []list.AssocGroup
+	if assoc != nil {
+		groups = []list.AssocGroup{{Assoc: *assoc, Files: fileNameStr}}
+	} else {
+		var unmatched []string
+		groups, unmatched = assocs.Group(fileNameStr)
+		if len(unmatched) > 0 {
+			fmt.Printf(" No program is associated w/ %v, so they're skipped.\n", unmatched)
+		}
+	}
+	for _, g := range groups {
+		for _, args := range g.Assoc.Commands(g.Files) {
+			if err = runCommand(g.Assoc.Command, args, verboseFlag); err != nil {
+				fmt.Printf(" Error returned by running %q %s is %v\n", g.Assoc.Command, args, err)
+			}
+		}
+	}
+} // end main
 
-	//variadicParam := []string{"-C", "vlc"} // This isn't really needed anymore.  I'll leave it here anyway, as a model in case I ever need to do this again.
+// runCommand -- runs cmdStr w/ the args.  A blank cmdStr means the shell, so each file is run by what it's associated with, like executable extensions on Windows.
+// cmd.exe behaves differently than tcc.exe.  tcc uses the -C flag and uses .Start(), while cmd does not use the -C flag and uses .Run()
+func runCommand(cmdStr string, args []string, verboseFlag bool) error {
+	var cmd bool
+	variadicParam := make([]string, 0, len(args)+1)
 
 	// For me to be able to pass a variadic param here, I must match the definition of the function, not pass some and then try the variadic syntax.
 	// I got this answer from stack overflow.
 
-	cmdPath = cmdStr
+	cmdPath := cmdStr
 	fmt.Printf(" cmdStr = %q, cmdPath = %q\n", cmdStr, cmdPath)
 	if cmdPath == "" {
 		if runtime.GOOS == "linux" {
@@ -341,24 +320,21 @@ func main() {
 				cmd = true // and variadicParam won't have the -C flag
 			}
 		}
-	}
-	variadicParam = append(variadicParam, fileNameStr...)
-
-	if cmdStr == "excel" || cmdStr == "winword" || cmdStr == "powerpnt" || cmdStr == "msaccess" {
+	} else if _, e := exec.LookPath(cmdStr); e != nil && runtime.GOOS == "windows" { // the office pgms are not on the path.
 		searchPath := officePath + os.Getenv("PATH")
 		execStr := findexec.Find(cmdStr, searchPath)
 		if execStr == "" {
-			ctfmt.Printf(ct.Red, true, " execStr is blank because could not find %s.  \nsearchPath = %s \n Exiting.\n", cmdStr, searchPath)
-			os.Exit(1)
+			ctfmt.Printf(ct.Red, true, " execStr is blank because could not find %s.  \nsearchPath = %s \n", cmdStr, searchPath)
+			return fmt.Errorf("could not find %s", cmdStr)
 		}
 		cmdPath = execStr
 	}
+	variadicParam = append(variadicParam, args...)
 
-	execCmd = exec.Command(cmdPath, variadicParam...)
+	execCmd := exec.Command(cmdPath, variadicParam...)
 
 	if verboseFlag {
-		fmt.Printf(" cmdStr = %s, cmdPath = %s, len of fileNameStr = %d, and filenames in fileNameStr are %v\n",
-			cmdStr, cmdPath, len(fileNameStr), fileNameStr)
+		fmt.Printf(" cmdStr = %s, cmdPath = %s, len of args = %d, and args are %v\n", cmdStr, cmdPath, len(args), args)
 		fmt.Printf(" Len(variadiacParam) = %d, variadiacParam = %#v\n", len(variadicParam), variadicParam)
 	}
 
@@ -373,11 +349,7 @@ func main() {
 		}
 	}
 	if cmd {
-		err = execCmd.Run() // will see if this works better when running cmd.exe, likely at work.
-	} else {
-		err = execCmd.Start()
+		return execCmd.Run() // will see if this works better when running cmd.exe, likely at work.
 	}
-	if err != nil {
-		fmt.Printf(" Error returned by running %s %s is %v\n", cmdStr, fileNameStr, err)
-	}
-} // end main
+	return execCmd.Start()
+} // runCommand
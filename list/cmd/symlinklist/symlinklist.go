@@ -17,9 +17,10 @@ import (
 29 July 25 -- Now uses os.Create, instead of os.OpenFile.
 31 Aug 25  -- Now called linklist.go based on heiclist code, and copylist.   It will make symlinks in the destination directory.
  2 Sep 25 -- Added check for directory, and if it's a directory, then skip it.
+ 18 Oct 26 -- Added the select flag, so the file selection can be done by a script w/o a prompt.
 */
 
-const lastAltered = "2 Sep 2025"
+const lastAltered = "18 Oct 2026"
 
 func main() {
 	fmt.Printf("%s is compiled w/ %s, last altered %s\n", os.Args[0], runtime.Version(), lastAltered)
@@ -55,6 +56,8 @@ func main() {
 	var quality int
 	flag.IntVarP(&quality, "quality", "q", 100, "quality of the jpg file")
 
+	flag.StringVar(&list.SelectStr, "select", "", "select files w/o a prompt, as in newest=5, size=1m.., mtime=7d, rex=jpg$ or 1-5,8.  For scripts.")
+
 	flag.Parse()
 
 	if veryVerboseFlag { // setting veryVerboseFlag also sets verbose flag, ie, verboseFlag
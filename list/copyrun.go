@@ -0,0 +1,236 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	ct "github.com/daviddengcn/go-colortext"
+	ctfmt "github.com/daviddengcn/go-colortext/fmt"
+)
+
+/*
+  REVISION HISTORY
+  -------- -------
+  18 Oct 26 -- Moved here from cf2 and cf4, which had the same 200 lines each.  CopyFiles is the fanout that copies the files and records them in the manifest,
+                 ResumeManifest copies again what a manifest says isn't verified, and RollbackManifest removes what it says was created.  The cmd still does the
+                 copying itself, in the CopyFunc it passes in.
+                 Resume used to force every pending and failed entry, which skipped the not newer check, so a destination that was newer than the source before
+                 the run was overwritten by the older source.  Now it only forces when the run created the destination, or wrote to it after the run started.
+*/
+
+// CopyJob is one file to copy into DestDir.  Err is from making DestDir, so the file isn't copied and the error is reported instead.
+type CopyJob struct {
+	SrcFile string
+	DestDir string
+	Force   bool // copy even if the destination is not older, for resume.
+	Err     error
+}
+
+// CopyResult is what a CopyFunc reports back for its job.  Msg is shown when it succeeds, and Err when it doesn't.
+type CopyResult struct {
+	SrcFile     string // blank if the file isn't in the manifest.
+	Msg         string
+	Err         error
+	Color       ct.Color
+	Success     bool
+	Verified    bool
+	Skipped     bool // the destination is not older, so it wasn't copied.
+	BytesCopied int64
+	Hash        string // sha256 of the source as it was copied.
+}
+
+// CopyFunc copies 1 file.  It's called in its own goroutine.
+type CopyFunc func(job CopyJob) CopyResult
+
+var onWin = runtime.GOOS == "windows"
+
+//	------------------------------------ CopyFiles ----------------------------------------------
+
+// CopyFiles -- copies the files of the jobs at the same time, and records each result in the manifest.  It returns how many were copied and how many weren't.
+func CopyFiles(jobs []CopyJob, manifest *Manifest, copyFn CopyFunc) (int64, int64) {
+	var succeeded, failed, totalBytesCopied int64
+	numOfFiles := len(jobs)
+
+	// Every file is in the manifest as pending before any are copied, so an interrupted run has a record of what didn't finish.
+	for _, job := range jobs {
+		if job.Err != nil {
+			continue
+		}
+		entry := ManifestEntry{Source: job.SrcFile, Dest: filepath.Join(job.DestDir, filepath.Base(job.SrcFile)), Status: StatusPending}
+		if fi, err := os.Stat(job.SrcFile); err == nil {
+			entry.Size = fi.Size()
+		}
+		if fi, err := os.Lstat(job.SrcFile); err == nil {
+			entry.ModTime = fi.ModTime() // copyAFile gives the copy the timestamp of a symlink, not of what it points to.
+		}
+		_, err := os.Lstat(entry.Dest)
+		entry.Created = errors.Is(err, fs.ErrNotExist)
+		manifest.Add(entry)
+	}
+	if err := manifest.Save(); err != nil {
+		ctfmt.Printf(ct.Red, true, " %s.  Copying anyway.\n", err)
+	}
+
+	// time to copy the files
+
+	start := time.Now()
+	resultChan := make(chan CopyResult, numOfFiles)
+	for _, job := range jobs {
+		if job.Err != nil {
+			resultChan <- CopyResult{Err: job.Err, Color: ct.Red}
+			continue
+		}
+		go func(job CopyJob) { // this is the line for the true fanout pattern.
+			resultChan <- copyFn(job)
+		}(job)
+	}
+	goRtnsNum := runtime.NumGoroutine()
+
+	for range numOfFiles { // only this goroutine reads the results, so the counts are not a data race.
+		result := <-resultChan
+		if result.Success {
+			ctfmt.Printf(result.Color, onWin, " %s\n", result.Msg)
+			succeeded++
+			totalBytesCopied += result.BytesCopied
+		} else {
+			if result.Err != nil {
+				ctfmt.Printf(result.Color, onWin, " %s\n", result.Err)
+			} else {
+				ctfmt.Printf(result.Color, onWin, " %s\n", result.Msg) // failed verification has only a message.
+			}
+			failed++
+		}
+		if result.SrcFile != "" {
+			if err := manifest.Record(result); err != nil {
+				ctfmt.Printf(ct.Red, onWin, " %s\n", err)
+			}
+		}
+	}
+	if err := manifest.Save(); err != nil {
+		ctfmt.Printf(ct.Red, true, " %s\n", err)
+	}
+	fmt.Printf(" \n %s:", os.Args[0])
+
+	if succeeded > 0 {
+		magnitudeString, magnitudeColor := GetMagnitudeString(totalBytesCopied)
+		ctfmt.Printf(ct.Green, onWin, " Total files copied is %d, ", succeeded)
+		ctfmt.Printf(ct.Magenta, false, "and approx total of bytes copied is ")
+		ctfmt.Printf(magnitudeColor, true, "%s,", magnitudeString)
+	}
+	if failed > 0 {
+		ctfmt.Printf(ct.Red, onWin, " Total files NOT copied is %d, ", failed)
+	}
+	ctfmt.Printf(ct.Cyan, onWin, " total elapsed time is %s using %d go routines for %s.\n", time.Since(start).Round(time.Millisecond), goRtnsNum, os.Args[0])
+
+	if onWin { // because tcc can get confused about its color scheme sometimes.  Probably a bug.  But I'm glad that tcmd+tcc finally works w/ vim.
+		ctfmt.Printf(ct.White, onWin, " Total files processed is %d\n", succeeded+failed)
+	}
+	fmt.Printf(" The manifest is %s\n", manifest.Filename)
+	return succeeded, failed
+} // CopyFiles
+
+// Record updates the entry for the result's source file.
+func (m *Manifest) Record(result CopyResult) error {
+	return m.Update(result.SrcFile, func(e *ManifestEntry) {
+		switch {
+		case result.Success && result.Verified:
+			e.Status = StatusVerified
+		case result.Success:
+			e.Status = StatusCopied
+		case result.Skipped:
+			e.Status = StatusSkipped
+		default:
+			e.Status = StatusFailed
+		}
+		if result.Hash != "" {
+			e.Hash = result.Hash
+		}
+		e.Error = ""
+		if result.Err != nil {
+			e.Error = result.Err.Error()
+		} else if !result.Success {
+			e.Error = result.Msg
+		}
+	})
+} // Record
+
+//	------------------------------------ ResumeJobs ----------------------------------------------
+
+// ResumeJobs returns the jobs to copy again the entries that aren't verified, and how many are.  A copied entry is checked against its hash first, so it
+// isn't copied again if it's fine.  A job is forced, ie, w/o the not newer check, only if the run created the destination or wrote to it after it started,
+// as then the destination is a partial copy.  Otherwise, a destination that was newer than the source before the run would be overwritten.
+// It makes the destination directories, and records the ones it makes.
+func (m *Manifest) ResumeJobs() ([]CopyJob, int) {
+	jobs := make([]CopyJob, 0, len(m.Entries))
+	var done int
+	for _, e := range m.Entries {
+		if e.Status == StatusVerified {
+			done++
+			continue
+		}
+		if e.Status == StatusCopied && e.Verify() == nil {
+			m.Update(e.Source, func(e *ManifestEntry) { e.Status = StatusVerified })
+			done++
+			continue
+		}
+		force := e.Created
+		if fi, err := os.Lstat(e.Dest); err == nil && !fi.ModTime().Before(m.Started.Truncate(time.Second)) { // some file systems only keep seconds.
+			force = true
+		}
+		dest := filepath.Dir(e.Dest)
+		newDirs := MissingDirs(dest)
+		err := os.MkdirAll(dest, 0755)
+		if err != nil {
+			err = fmt.Errorf("making the directory for %s: %w", e.Source, err)
+		} else {
+			m.AddDirs(newDirs...)
+		}
+		jobs = append(jobs, CopyJob{SrcFile: e.Source, DestDir: dest, Force: force, Err: err})
+	}
+	return jobs, done
+} // ResumeJobs
+
+//	------------------------------------ ResumeManifest ----------------------------------------------
+
+// ResumeManifest -- copies again the files of an interrupted run that aren't verified, at most maxJobs of them.
+func ResumeManifest(filename string, maxJobs int, copyFn CopyFunc) error {
+	manifest, err := LoadManifest(filename)
+	if err != nil {
+		return err
+	}
+	jobs, done := manifest.ResumeJobs()
+	if len(jobs) > maxJobs {
+		fmt.Printf(" There are %d files to be copied, which exceeds the max of %d.  Resume again for the rest.\n", len(jobs), maxJobs)
+		jobs = jobs[:maxJobs]
+	}
+	ctfmt.Printf(ct.Cyan, onWin, " %d of the %d files in %s are verified, so %d to copy again.\n", done, len(manifest.Entries), filename, len(jobs))
+	if len(jobs) == 0 {
+		return manifest.Save()
+	}
+	CopyFiles(jobs, manifest, copyFn)
+	return nil
+} // ResumeManifest
+
+//	------------------------------------ RollbackManifest ----------------------------------------------
+
+// RollbackManifest -- removes the files and directories a run created.  The error is nil only if everything it should remove was removed.
+func RollbackManifest(filename string) error {
+	manifest, err := LoadManifest(filename)
+	if err != nil {
+		return err
+	}
+	removed, errs := manifest.Rollback()
+	for _, err := range errs {
+		ctfmt.Printf(ct.Red, onWin, " %s\n", err)
+	}
+	ctfmt.Printf(ct.Green, onWin, " Removed %d files created by the run in %s, started %s.\n", removed, filename, manifest.Started.Format("Mon Jan 2 2006 15:04:05"))
+	if len(errs) > 0 {
+		return fmt.Errorf("%d files or directories were not removed", len(errs))
+	}
+	return nil
+} // RollbackManifest
@@ -0,0 +1,78 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResumeJobsForce(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	newer := time.Now().Add(-24 * time.Hour)
+	write := func(name, data string, mtime time.Time) string {
+		full := filepath.Join(dir, name)
+		if err := os.WriteFile(full, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(full, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return full
+	}
+
+	m := NewManifest(filepath.Join(dir, "manifest.json"), "test", dir)
+	// the destination was there and newer before the run, so resume has to keep the not newer check.
+	m.Add(ManifestEntry{Source: write("a.src", "OLD-SOURCE", old), Dest: write("a.dst", "NEWER-DEST", newer), Status: StatusPending})
+	// the run created the destination, so it's a partial copy.
+	m.Add(ManifestEntry{Source: write("b.src", "source", old), Dest: write("b.dst", "part", old), Status: StatusFailed, Created: true})
+	// the destination was there before, but the run wrote to it.
+	m.Add(ManifestEntry{Source: write("c.src", "source", old), Dest: write("c.dst", "part", time.Now()), Status: StatusPending})
+	// a skipped file keeps the not newer check.
+	m.Add(ManifestEntry{Source: write("d.src", "source", old), Dest: write("d.dst", "newer", newer), Status: StatusSkipped})
+
+	jobs, done := m.ResumeJobs()
+	if done != 0 || len(jobs) != 4 {
+		t.Fatalf("ResumeJobs returned %d jobs and %d done, but want 4 and 0", len(jobs), done)
+	}
+	want := map[string]bool{"a.src": false, "b.src": true, "c.src": true, "d.src": false}
+	for _, job := range jobs {
+		if w := want[filepath.Base(job.SrcFile)]; job.Force != w {
+			t.Errorf("%s: Force is %t, but want %t", filepath.Base(job.SrcFile), job.Force, w)
+		}
+		if job.DestDir != dir {
+			t.Errorf("%s: DestDir is %s, but want %s", job.SrcFile, job.DestDir, dir)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	m := NewManifest(filepath.Join(t.TempDir(), "manifest.json"), "test", "")
+	tests := []struct {
+		result CopyResult
+		status string
+	}{
+		{CopyResult{Success: true, Verified: true, Hash: "abc"}, StatusVerified},
+		{CopyResult{Success: true}, StatusCopied},
+		{CopyResult{Skipped: true, Msg: "not newer"}, StatusSkipped},
+		{CopyResult{Msg: "failed VERIFICATION"}, StatusFailed},
+	}
+	for i, tt := range tests {
+		tt.result.SrcFile = string(rune('a' + i))
+		m.Add(ManifestEntry{Source: tt.result.SrcFile, Status: StatusPending})
+		if err := m.Record(tt.result); err != nil {
+			t.Fatal(err)
+		}
+		e := m.Entries[i]
+		if e.Status != tt.status {
+			t.Errorf("%d: status is %s, but want %s", i, e.Status, tt.status)
+		}
+		if !tt.result.Success && e.Error != tt.result.Msg {
+			t.Errorf("%d: error is %q, but want %q", i, e.Error, tt.result.Msg)
+		}
+	}
+	if m.Entries[0].Hash != "abc" {
+		t.Errorf("hash is %q, but want abc", m.Entries[0].Hash)
+	}
+}
@@ -0,0 +1,242 @@
+package list
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+  REVISION HISTORY
+  -------- -------
+  18 Oct 26 -- Finding duplicate files, for the dedupe command.  Only regular files are compared, so symlinks are skipped.  The files are grouped by size first,
+                 which costs nothing as it's in the FileInfo.  Then the files of a size that has more than one are grouped by the sha256 of their first PartialHashSize
+                 bytes, and only those that still have company are hashed all the way through.  That's the same sha256 as fsha and multisha use.
+                 Files that are already hard links to each other are the same file, so only the first of them is kept in a set.
+                 The hashing is concurrent, w/ numWorkers files being read at once, like the directory reading routines here.
+                 The first file of a duplicate set is the one to keep, by the order from SortDupSet.  DedupeFile does the delete, hardlink or symlink for the others.
+*/
+
+// PartialHashSize is how much of a file is hashed to weed out files that are the same size but not the same.
+const PartialHashSize = 64 * 1024
+
+// These are the actions of DedupeFile.
+const (
+	DedupeDelete   = "delete"
+	DedupeHardlink = "hardlink"
+	DedupeSymlink  = "symlink"
+)
+
+// DupSet is a group of files w/ the same contents.  Hash is the sha256 of the contents.
+type DupSet struct {
+	Size  int64
+	Hash  string
+	Files []FileInfoExType
+}
+
+// Wasted is the space that's freed by keeping only one of the set.
+func (d DupSet) Wasted() int64 {
+	return d.Size * int64(len(d.Files)-1)
+}
+
+// ------------------------------------------------ FindDuplicates -----------------------------------------------------
+
+// FindDuplicates returns the sets of files in fileList that have the same contents, largest wasted space first.  Files smaller than minSize are skipped,
+// so a minSize of 1 skips the empty files, which are all the same as each other.
+func FindDuplicates(fileList []FileInfoExType, minSize int64) []DupSet {
+	bySize := make(map[int64][]FileInfoExType)
+	for _, f := range fileList {
+		if !f.FI.Mode().IsRegular() || f.FI.Size() < minSize {
+			continue
+		}
+		bySize[f.FI.Size()] = append(bySize[f.FI.Size()], f)
+	}
+
+	var candidates []hashGroup
+	for _, group := range bySize {
+		group = withoutHardlinks(group)
+		if len(group) > 1 {
+			candidates = append(candidates, hashGroup{files: group})
+		}
+	}
+	if VerboseFlag {
+		fmt.Printf(" FindDuplicates: %d files, %d sizes w/ more than one file.\n", len(fileList), len(candidates))
+	}
+
+	// partial hash, then full hash.  A file smaller than PartialHashSize is already fully hashed by the first pass.
+	for _, partial := range []bool{true, false} {
+		var next []hashGroup
+		for _, group := range candidates {
+			if !partial && group.files[0].FI.Size() <= PartialHashSize {
+				next = append(next, group)
+				continue
+			}
+			next = append(next, groupByHash(group.files, partial)...)
+		}
+		candidates = next
+	}
+
+	dups := make([]DupSet, 0, len(candidates))
+	for _, group := range candidates {
+		dups = append(dups, DupSet{Size: group.files[0].FI.Size(), Hash: group.hash, Files: group.files})
+	}
+	sort.SliceStable(dups, func(i, j int) bool {
+		if dups[i].Wasted() == dups[j].Wasted() {
+			return dups[i].Files[0].FullPath < dups[j].Files[0].FullPath
+		}
+		return dups[i].Wasted() > dups[j].Wasted()
+	})
+	return dups
+} // FindDuplicates
+
+type hashGroup struct {
+	hash  string
+	files []FileInfoExType
+}
+
+// withoutHardlinks -- drops the files that are the same file as one already in the group.
+func withoutHardlinks(group []FileInfoExType) []FileInfoExType {
+	out := make([]FileInfoExType, 0, len(group))
+	for _, f := range group {
+		same := false
+		for _, g := range out {
+			if os.SameFile(f.FI, g.FI) {
+				same = true
+				break
+			}
+		}
+		if !same {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// groupByHash -- splits the group by the hash of the files, keeping only the new groups that have more than one file.  A file that can't be read is reported and dropped.
+func groupByHash(group []FileInfoExType, partial bool) []hashGroup {
+	hashes := make([]string, len(group))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, numWorkers)
+	for i, f := range group {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			h, err := hashOf(f.FullPath, partial)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " ERROR hashing %s is %s.  Skipped.\n", f.FullPath, err)
+				return
+			}
+			hashes[i] = h
+		}()
+	}
+	wg.Wait()
+
+	byHash := make(map[string][]FileInfoExType)
+	order := make([]string, 0, len(group)) // so the groups, and the files in them, stay in the order they came in.
+	for i, f := range group {
+		if hashes[i] == "" {
+			continue
+		}
+		if _, ok := byHash[hashes[i]]; !ok {
+			order = append(order, hashes[i])
+		}
+		byHash[hashes[i]] = append(byHash[hashes[i]], f)
+	}
+	var out []hashGroup
+	for _, h := range order {
+		if len(byHash[h]) > 1 {
+			out = append(out, hashGroup{hash: h, files: byHash[h]})
+		}
+	}
+	return out
+}
+
+// hashOf -- the sha256 of the file in hex, or of only its first PartialHashSize bytes if partial is set.
+func hashOf(filename string, partial bool) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	var r io.Reader = f
+	if partial {
+		r = io.LimitReader(f, PartialHashSize)
+	}
+	if _, err = io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ------------------------------------------------ SortDupSet ---------------------------------------------------------
+
+// SortDupSet puts the file to keep first.  keep is oldest, newest or shortest, which is the shortest full path.  Ties go to the name that sorts first.
+func SortDupSet(d DupSet, keep string) error {
+	var less func(a, b FileInfoExType) bool
+	switch strings.ToLower(keep) {
+	case "oldest", "":
+		less = func(a, b FileInfoExType) bool { return a.FI.ModTime().Before(b.FI.ModTime()) }
+	case "newest":
+		less = func(a, b FileInfoExType) bool { return a.FI.ModTime().After(b.FI.ModTime()) }
+	case "shortest":
+		less = func(a, b FileInfoExType) bool { return len(a.FullPath) < len(b.FullPath) }
+	default:
+		return fmt.Errorf("keep must be oldest, newest or shortest, not %q", keep)
+	}
+	sort.SliceStable(d.Files, func(i, j int) bool {
+		a, b := d.Files[i], d.Files[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.FullPath < b.FullPath
+	})
+	return nil
+} // SortDupSet
+
+// ------------------------------------------------ DedupeFile ---------------------------------------------------------
+
+// DedupeFile resolves dup, which has the same contents as keep, by deleting it, or by replacing it w/ a hard link or a symlink to keep.
+// The link is made under a temp name in dup's directory and then renamed over dup, so dup is never missing if the link can't be made,
+// as when a hard link would cross file systems.
+func DedupeFile(keep, dup, action string) error {
+	switch action {
+	case DedupeDelete:
+		return os.Remove(dup)
+	case DedupeHardlink, DedupeSymlink:
+	default:
+		return fmt.Errorf("dedupe action must be %s, %s or %s, not %q", DedupeDelete, DedupeHardlink, DedupeSymlink, action)
+	}
+
+	tmpName := filepath.Join(filepath.Dir(dup), "."+filepath.Base(dup)+".dedupe.tmp")
+	os.Remove(tmpName) // left over from an earlier try.
+	var err error
+	if action == DedupeHardlink {
+		err = os.Link(keep, tmpName)
+	} else {
+		target := keep
+		if abs, e := filepath.Abs(keep); e == nil {
+			target = abs
+		}
+		err = os.Symlink(target, tmpName)
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmpName, dup); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+} // DedupeFile
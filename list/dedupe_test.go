@@ -0,0 +1,137 @@
+package list
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeDupTree -- a1, a2 and sub/a3 are the same, b1 and b2 are the same and bigger than PartialHashSize, c1 differs from b1 only at the end,
+// d1 is the same size as a1 but different, and e1 and e2 are empty.
+func makeDupTree(t *testing.T) (string, []FileInfoExType) {
+	top := t.TempDir()
+	big := bytes.Repeat([]byte("01234567"), PartialHashSize/4)
+	bigger := append([]byte(nil), big...)
+	bigger[len(bigger)-1] = 'x'
+	files := map[string][]byte{
+		"a1": []byte("hello"), "a2": []byte("hello"), "sub/a3": []byte("hello"),
+		"b1": big, "b2": big, "c1": bigger,
+		"d1": []byte("jello"),
+		"e1": nil, "e2": nil,
+	}
+	lst := make([]FileInfoExType, 0, len(files))
+	when := time.Now().Add(-time.Hour)
+	for name, data := range files {
+		path := filepath.Join(top, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name == "a2" { // the oldest of the a's.
+			os.Chtimes(path, when, when)
+		}
+		fi, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lst = append(lst, FileInfoExType{FI: fi, Dir: filepath.Dir(path), RelPath: path, AbsPath: path, FullPath: path})
+	}
+	return top, lst
+}
+
+func dupNames(top string, d DupSet) string {
+	names := make([]string, 0, len(d.Files))
+	for _, f := range d.Files {
+		rel, _ := filepath.Rel(top, f.FullPath)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	return strings.Join(names, " ")
+}
+
+func TestFindDuplicates(t *testing.T) {
+	top, lst := makeDupTree(t)
+
+	dups := FindDuplicates(lst, 1)
+	if len(dups) != 2 {
+		t.Fatalf("there should be 2 duplicate sets, but there are %d", len(dups))
+	}
+	if dups[0].Size != int64(2*PartialHashSize) || len(dups[0].Files) != 2 || dups[0].Wasted() != int64(2*PartialHashSize) {
+		t.Errorf("the first set should be b1 and b2, as they waste the most space, but it's %s", dupNames(top, dups[0]))
+	}
+	if len(dups[0].Hash) != 64 || dups[0].Hash == dups[1].Hash {
+		t.Errorf("the hashes should be different sha256's, but they're %q and %q", dups[0].Hash, dups[1].Hash)
+	}
+	if err := SortDupSet(dups[1], "oldest"); err != nil {
+		t.Fatal(err)
+	}
+	names := strings.Fields(dupNames(top, dups[1]))
+	if names[0] != "a2" {
+		t.Errorf("a2 is the oldest, so it should be first, but the set is %v", names)
+	}
+	sort.Strings(names)
+	if strings.Join(names, " ") != "a1 a2 sub/a3" {
+		t.Errorf("the second set should be a1 a2 sub/a3, but it's %v", names)
+	}
+
+	if dups = FindDuplicates(lst, 0); len(dups) != 3 {
+		t.Errorf("w/ a minSize of 0, the empty files are a set too, so there should be 3 sets, but there are %d", len(dups))
+	}
+	if err := SortDupSet(dups[0], "biggest"); err == nil {
+		t.Errorf("SortDupSet w/ keep=biggest should be an error")
+	}
+}
+
+func TestDedupeFile(t *testing.T) {
+	top, lst := makeDupTree(t)
+	a1, a2, a3 := filepath.Join(top, "a1"), filepath.Join(top, "a2"), filepath.Join(top, "sub", "a3")
+
+	if err := DedupeFile(a1, a2, DedupeHardlink); err != nil {
+		t.Fatal(err)
+	}
+	fi1, _ := os.Stat(a1)
+	fi2, _ := os.Stat(a2)
+	if !os.SameFile(fi1, fi2) {
+		t.Errorf("%s should be a hard link to %s", a2, a1)
+	}
+	if err := DedupeFile(a1, a3, DedupeSymlink); err != nil {
+		t.Logf("no symlink, so it's not tested: %s", err)
+	} else if target, err := os.Readlink(a3); err != nil || target != a1 {
+		t.Errorf("%s should be a symlink to %s, but it's %q, err = %v", a3, a1, target, err)
+	}
+
+	// a1 and a2 are the same file now, so they're not duplicates anymore.
+	relisted := make([]FileInfoExType, 0, len(lst))
+	for _, f := range lst {
+		if fi, err := os.Lstat(f.FullPath); err == nil {
+			f.FI = fi
+			relisted = append(relisted, f)
+		}
+	}
+	for _, d := range FindDuplicates(relisted, 1) {
+		if d.Size == 5 {
+			t.Errorf("a1 and a2 are hard links, and a3 is a symlink, so they should not be a set: %s", dupNames(top, d))
+		}
+	}
+
+	if err := DedupeFile(a1, filepath.Join(top, "b1"), DedupeDelete); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(top, "b1")); !os.IsNotExist(err) {
+		t.Errorf("b1 should have been deleted")
+	}
+	if err := DedupeFile(a1, a2, "move"); err == nil {
+		t.Errorf("DedupeFile w/ an action of move should be an error")
+	}
+	if err := DedupeFile(filepath.Join(top, "not there"), a2, DedupeHardlink); err == nil {
+		t.Errorf("DedupeFile w/ a keep file that doesn't exist should be an error")
+	}
+	if _, err := os.Stat(a2); err != nil {
+		t.Errorf("%s should still be there after a failed DedupeFile: %s", a2, err)
+	}
+}
@@ -85,9 +85,11 @@ import (
    9 Mar 25 -- Suppressed display of error in FileSelection().  I don't need to display errors twice.
   18 May 25 -- Added unique function.
   20 Aug 25 -- I decided to include symlinks to be copied.  And I'll add a flag to only copy symlinks.
+  18 Oct 26 -- FileSelection and FileSelectionString use SelectStr instead of prompting when it's not blank.  The code is in select.go.
+  18 Oct 26 -- Added RecurseFlag, MaxDepth and the directory regexps.  The directory reading routines walk the tree when RecurseFlag is set.  The code is in walk.go.
 */
 
-var LastAltered = "Aug 20, 2025"
+var LastAltered = "Oct 18, 2026"
 
 type DirAliasMapType map[string]string
 
@@ -98,6 +100,7 @@ type FileInfoExType struct {
 	RelPath  string // this is a misnomer, but to not have to propagate the correction thru my code, I'll leave this here.
 	AbsPath  string
 	FullPath string // probably not needed, but I really do want to be complete.
+	SubDir   string // the subpath of Dir under the top directory when RecurseFlag is set, else "".  Added 18 Oct 26.
 }
 
 var filterAmt int64 // not exported.  Don't remember why this is an int64 instead of just an int.
@@ -455,6 +458,9 @@ func NewFromRegexp(rex *regexp.Regexp) ([]FileInfoExType, error) { // remember t
 
 // MyReadDir -- single routine version
 func MyReadDir(dir string, excludeMe *regexp.Regexp) ([]FileInfoExType, error) { // The entire change including use of []DirEntry happens here.  Not concurrent.
+	if RecurseFlag {
+		return myReadDirRecursive(dir, func(fi os.FileInfo) bool { return includeThis(fi, excludeMe) })
+	}
 	dirEntries, err := os.ReadDir(dir) // this function doesn't need to be closed.
 	if err != nil {
 		return nil, err
@@ -640,6 +646,9 @@ func unique(in string) string {
 
 // FileSelection -- This displays the files on screen and creates the list from what's entered by the user.
 func FileSelection(inList []FileInfoExType) ([]FileInfoExType, error) {
+	if SelectStr != "" { // added 18 Oct 26, so scripts don't get a prompt.
+		return Select(inList, SelectStr)
+	}
 	outList := make([]FileInfoExType, 0, len(inList))
 	numOfLines := min(autoHeight, minHeight)
 	numOfLines = min(numOfLines, len(inList))
@@ -719,6 +728,17 @@ outerLoop:
 // -------------------------------------------- FileSelectionString -------------------------------------------------------
 
 func FileSelectionString(inList []FileInfoExType) ([]string, error) {
+	if SelectStr != "" {
+		selected, err := Select(inList, SelectStr)
+		if err != nil {
+			return nil, err
+		}
+		outStrList := make([]string, 0, len(selected))
+		for _, f := range selected {
+			outStrList = append(outStrList, f.AbsPath)
+		}
+		return outStrList, nil
+	}
 	outStrList := make([]string, 0, len(inList))
 	numOfLines := min(autoHeight, minHeight)
 	numOfLines = min(numOfLines, len(inList))
@@ -1035,6 +1055,9 @@ func FileInfoXFromRegexp(rex *regexp.Regexp) ([]FileInfoExType, error) { // Uses
 } // end FileInfoXFromRegexp
 
 func myReadDirConcurrent(dir string) ([]FileInfoExType, error) { // The entire change including use of []DirEntry happens here.  Concurrent code here is what makes this fdsrt.
+	if RecurseFlag {
+		return myReadDirRecursive(dir, includeThisForConcurrent)
+	}
 	// Adding concurrency in returning []os.FileInfo
 
 	var wg sync.WaitGroup
@@ -1149,6 +1172,9 @@ func includeThisForConcurrent(fi os.FileInfo) bool {
 }
 
 func myReadDirConcurrentWithMatch(dir, matchPat string) ([]FileInfoExType, error) { // The entire change including use of []DirEntry happens here, and now concurrent code.
+	if RecurseFlag {
+		return myReadDirRecursive(dir, func(fi os.FileInfo) bool { return includeThisWithMatchForConcurrent(fi, matchPat) })
+	}
 	// Adding concurrency in returning []os.FileInfo
 	// This routine adds a call to filepath.Match
 
@@ -1269,6 +1295,9 @@ func includeThisWithMatchForConcurrent(fi os.FileInfo, matchPat string) bool {
 } // end includeThisWithMatchForConcurrent
 
 func myReadDirConcurrentWithRex(dir string, regx *regexp.Regexp) ([]FileInfoExType, error) { // The entire change including use of []DirEntry happens here, and now concurrent code.
+	if RecurseFlag {
+		return myReadDirRecursive(dir, func(fi os.FileInfo) bool { return includeThisWithRexForConcurrent(fi, regx) })
+	}
 	// Adding concurrency in returning []os.FileInfo
 	// This routine adds a call to filepath.Match
 
@@ -0,0 +1,312 @@
+package list
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+  REVISION HISTORY
+  -------- -------
+  18 Oct 26 -- A copy manifest, so an interrupted copy by cf2 or cf4 leaves a record of what finished.  Each file to be copied is added as pending, and its entry
+                 is updated when its copy goroutine reports back.  The manifest is written to a temp file that's then renamed, so a dropped network drive
+                 or a ^C leaves the last complete version behind.  Update saves it at most once a second, so 900 small files don't mean 900 writes of the whole
+                 manifest.  An update that wasn't saved leaves the entry as it was, which resume copies again, so nothing is lost but time.
+                 Resume means to copy again everything in the manifest that isn't verified.  Rollback removes the files the manifest says were created, and then
+                 the directories it created if they're empty.  A file that was overwritten, instead of created, is not removed, as the old version is gone anyway.
+                 A created file that was changed after the copy is not removed either, because then it's not the file that was copied.
+*/
+
+// These are the values of ManifestEntry.Status.
+const (
+	StatusPending  = "pending"  // not reported back yet.  After an interrupted run, these are the ones that didn't finish.
+	StatusCopied   = "copied"   // copied, but not verified.
+	StatusVerified = "verified" // copied, and the destination was found to be the same as the source.
+	StatusSkipped  = "skipped"  // not copied, because the destination is the same or newer.
+	StatusFailed   = "failed"
+)
+
+// ManifestEntry is one file in a copy manifest.  Size and ModTime are of the source, and Hash is its sha256 as it was copied.
+type ManifestEntry struct {
+	Source  string    `json:"source"`
+	Dest    string    `json:"dest"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Hash    string    `json:"sha256,omitempty"`
+	Status  string    `json:"status"`
+	Created bool      `json:"created"` // the destination didn't exist before the copy, so rollback can remove it.
+	Error   string    `json:"error,omitempty"`
+}
+
+// Manifest is the record of a copy run.  It's safe to use from more than one goroutine.
+type Manifest struct {
+	Filename string          `json:"-"`
+	Program  string          `json:"program"`
+	DestDir  string          `json:"destdir"`
+	Started  time.Time       `json:"started"`
+	Updated  time.Time       `json:"updated"`
+	Dirs     []string        `json:"dirs,omitempty"` // directories created under DestDir, outermost first.
+	Entries  []ManifestEntry `json:"entries"`
+
+	mu    sync.Mutex
+	index map[string]int // source -> position in Entries.
+}
+
+// ------------------------------------------------ NewManifest --------------------------------------------------------
+
+// NewManifest returns an empty manifest that will be saved as filename.  When filename is blank, DefaultManifestName(program) is used.
+func NewManifest(filename, program, destDir string) *Manifest {
+	if filename == "" {
+		filename = DefaultManifestName(program)
+	}
+	now := time.Now()
+	return &Manifest{
+		Filename: filename,
+		Program:  program,
+		DestDir:  destDir,
+		Started:  now,
+		Updated:  now,
+		index:    make(map[string]int),
+	}
+} // NewManifest
+
+// DefaultManifestName -- a new name for each run, in the user's cache directory so it doesn't clutter the destination.
+func DefaultManifestName(program string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, program, "manifest-"+time.Now().Format("20060102-150405")+".json")
+} // DefaultManifestName
+
+// ------------------------------------------------ LoadManifest -------------------------------------------------------
+
+// LoadManifest reads a manifest written by Save, so it can be resumed or rolled back.  Saving it again writes back to the same file.
+func LoadManifest(filename string) (*Manifest, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err = json.Unmarshal(buf, m); err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", filename, err)
+	}
+	m.Filename = filename
+	m.index = make(map[string]int, len(m.Entries))
+	for i, e := range m.Entries {
+		m.index[e.Source] = i
+	}
+	return m, nil
+} // LoadManifest
+
+// ------------------------------------------------ Save ---------------------------------------------------------------
+
+// Save writes the manifest to a temp file in the same directory, then renames it, so the manifest on disk is always complete.
+func (m *Manifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.save()
+} // Save
+
+func (m *Manifest) save() error {
+	m.Updated = time.Now()
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(m.Filename), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(m.Filename), filepath.Base(m.Filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	_, err = tmp.Write(buf)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmpName, m.Filename)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("saving manifest %s: %w", m.Filename, err)
+	}
+	return nil
+}
+
+// ------------------------------------------------ Add, Update and AddDirs --------------------------------------------
+
+// Add records e, replacing an entry for the same source.  If the destination was created by an earlier try, it stays marked as created.
+// It doesn't save the manifest, so call Save after adding the entries.
+func (m *Manifest) Add(e ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.index == nil {
+		m.index = make(map[string]int)
+	}
+	if i, ok := m.index[e.Source]; ok {
+		e.Created = e.Created || m.Entries[i].Created
+		m.Entries[i] = e
+	} else {
+		m.index[e.Source] = len(m.Entries)
+		m.Entries = append(m.Entries, e)
+	}
+} // Add
+
+// Update calls fn w/ the entry for source, and then saves the manifest if it wasn't saved in the last second.  Call Save when done.
+func (m *Manifest) Update(source string, fn func(e *ManifestEntry)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, ok := m.index[source]
+	if !ok {
+		return fmt.Errorf("%s is not in manifest %s", source, m.Filename)
+	}
+	created := m.Entries[i].Created
+	fn(&m.Entries[i])
+	m.Entries[i].Created = m.Entries[i].Created || created
+	if time.Since(m.Updated) < time.Second {
+		return nil
+	}
+	return m.save()
+} // Update
+
+// AddDirs records directories that were created for the copy, so rollback can remove them.  Like Add, it doesn't save the manifest.
+func (m *Manifest) AddDirs(dirs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range dirs {
+		found := false
+		for _, have := range m.Dirs {
+			if have == d {
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.Dirs = append(m.Dirs, d)
+		}
+	}
+} // AddDirs
+
+// MissingDirs returns dir and those of its parents that don't exist yet, outermost first.  Call it before DestDirFor to know which directories that creates.
+func MissingDirs(dir string) []string {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		missing = append([]string{d}, missing...)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return missing
+} // MissingDirs
+
+// ------------------------------------------------ Verify -------------------------------------------------------------
+
+// Verify returns nil if the destination of e is still what was copied, ie, it has the size and sha256 in the entry.
+func (e ManifestEntry) Verify() error {
+	if e.Hash == "" {
+		return fmt.Errorf("%s has no hash in the manifest", e.Dest)
+	}
+	fi, err := os.Stat(e.Dest)
+	if err != nil {
+		return err
+	}
+	if fi.Size() != e.Size {
+		return fmt.Errorf("%s is %d bytes, but %s was %d bytes", e.Dest, fi.Size(), e.Source, e.Size)
+	}
+	hash, err := HashFile(e.Dest)
+	if err != nil {
+		return err
+	}
+	if hash != e.Hash {
+		return fmt.Errorf("%s is not the same as %s was when it was copied", e.Dest, e.Source)
+	}
+	return nil
+} // Verify
+
+// HashFile returns the sha256 of a file in hex, which is what's in the manifest.
+func HashFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+} // HashFile
+
+// ------------------------------------------------ Rollback -----------------------------------------------------------
+
+// Rollback removes the files the manifest created, and then the directories it created that are now empty.  It returns how many files it removed
+// and the reasons it didn't remove the others.  A created file that was changed after it was copied is not removed.
+func (m *Manifest) Rollback() (int, []error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	removed := 0
+	for _, e := range m.Entries {
+		if !e.Created {
+			continue
+		}
+		fi, err := os.Stat(e.Dest)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // already gone, as when a failed copy removed its partial file.
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if e.Status == StatusCopied || e.Status == StatusVerified {
+			if fi.Size() != e.Size || fi.ModTime().Unix() != e.ModTime.Unix() {
+				errs = append(errs, fmt.Errorf("%s was changed after it was copied, so it was not removed", e.Dest))
+				continue
+			}
+		}
+		if err = os.Remove(e.Dest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+
+	dirs := append([]string(nil), m.Dirs...)
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs))) // a subdirectory sorts after its parent, so this removes the innermost first.
+	for _, d := range dirs {
+		if err := os.Remove(d); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("directory %s was not removed: %w", d, err))
+		}
+	}
+	return removed, errs
+} // Rollback
+
+// Counts returns how many entries have each status.
+func (m *Manifest) Counts() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int)
+	for _, e := range m.Entries {
+		counts[e.Status]++
+	}
+	return counts
+} // Counts
@@ -0,0 +1,121 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("some data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := HashFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManifest(filepath.Join(dir, "sub", "manifest.json"), "test", dir)
+	m.Add(ManifestEntry{Source: src, Dest: filepath.Join(dir, "dest.txt"), Size: 9, Status: StatusPending, Created: true})
+	m.Add(ManifestEntry{Source: "other", Dest: "other.out", Status: StatusPending})
+	if err = m.Update(src, func(e *ManifestEntry) { e.Status, e.Hash, e.Created = StatusCopied, hash, false }); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Update("not there", func(e *ManifestEntry) {}); err == nil {
+		t.Errorf("Update of a source that's not in the manifest should be an error")
+	}
+	if err = m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m2, err := LoadManifest(m.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m2.Entries) != 2 {
+		t.Fatalf("loaded manifest should have 2 entries, but it has %d", len(m2.Entries))
+	}
+	e := m2.Entries[0]
+	if e.Source != src || e.Status != StatusCopied || e.Hash != hash || !e.Created {
+		t.Errorf("loaded entry is %+v, which is not what was saved", e)
+	}
+	if counts := m2.Counts(); counts[StatusCopied] != 1 || counts[StatusPending] != 1 {
+		t.Errorf("counts are %v", counts)
+	}
+	m2.Add(ManifestEntry{Source: src, Dest: e.Dest, Status: StatusPending})
+	if len(m2.Entries) != 2 || !m2.Entries[0].Created {
+		t.Errorf("Add of the same source should replace it and keep it created, but the entries are %+v", m2.Entries)
+	}
+}
+
+func TestManifestVerifyRollback(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	m := NewManifest(filepath.Join(t.TempDir(), "manifest.json"), "test", dest)
+	var created, kept string
+	for _, name := range []string{"new.txt", "old.txt", "changed.txt"} {
+		from, to := filepath.Join(src, name), filepath.Join(dest, "a", "b", name)
+		if name == "old.txt" {
+			to = filepath.Join(dest, name)
+			kept = to
+		}
+		m.AddDirs(MissingDirs(filepath.Dir(to))...)
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(from, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(to, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := os.Stat(to)
+		hash, _ := HashFile(from)
+		e := ManifestEntry{Source: from, Dest: to, Size: fi.Size(), ModTime: fi.ModTime(), Hash: hash, Status: StatusVerified, Created: name != "old.txt"}
+		if err := e.Verify(); err != nil {
+			t.Errorf("Verify of %s is %s", name, err)
+		}
+		m.Add(e)
+		if name == "new.txt" {
+			created = to
+		}
+	}
+	if len(m.Dirs) != 2 {
+		t.Errorf("manifest dirs should be a and a/b, but they are %v", m.Dirs)
+	}
+
+	changed := filepath.Join(dest, "a", "b", "changed.txt")
+	if err := os.WriteFile(changed, []byte("it's different now"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range m.Entries {
+		if e.Dest == changed && e.Verify() == nil {
+			t.Errorf("Verify of %s should fail after it was changed", changed)
+		}
+	}
+
+	removed, errs := m.Rollback()
+	if removed != 1 {
+		t.Errorf("Rollback should have removed 1 file, but it removed %d", removed)
+	}
+	if len(errs) != 3 { // changed.txt is not removed, so neither is a/b, nor a.
+		t.Errorf("Rollback should have 3 errors, but it has %d: %v", len(errs), errs)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("%s should have been removed", created)
+	}
+	for _, f := range []string{kept, changed} {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("%s should not have been removed: %s", f, err)
+		}
+	}
+
+	os.Remove(changed)
+	if _, errs = m.Rollback(); len(errs) != 0 {
+		t.Errorf("second Rollback should have removed the empty directories w/o errors, but got %v", errs)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "a")); !os.IsNotExist(err) {
+		t.Errorf("directory a should have been removed")
+	}
+}
@@ -0,0 +1,292 @@
+package list
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"src/timlibg"
+)
+
+/*
+  REVISION HISTORY
+  -------- -------
+  18 Oct 26 -- Non-interactive file selection, so cf2, movelist, dellist and the rest can be run from a script.  When SelectStr is not blank, FileSelection and
+                 FileSelectionString apply it instead of prompting.  Every list/cmd tool sets SelectStr from its select flag.
+                 A selection expression is criteria separated by spaces.  Each criterion applies to what's left from the ones before it, so
+                 "rex=\.jpg$ newest=5" is the 5 newest jpg files, and "1-10 size=1m.." is whichever of the first 10 are at least 1 MB.  The criteria are
+                   newest=N, oldest=N, largest=N, smallest=N
+                   size=LO..HI     either end can be left off.  Sizes can end in k, m, g or t, which are powers of 1000 as in the size filter.
+                   mtime=LO..HI    either end can be left off.  Each end is a date that timlibg.ParseDate understands, or how long ago as in 36h, 7d or 2w.
+                                   mtime=DATE is that day, and mtime=7d is the last 7 days.
+                   rex=REGEXP      the file name has to match, ignoring case.
+                   exclude=REGEXP  the file name must not match, ignoring case.
+                   1-5,8           1 based positions in the list as it's displayed, or what's left of it.  idx=1-5,8 is the same thing.
+                   all             everything, which is what an expression of only the other criteria starts from anyway.
+                 The result is in the same order as the list.
+*/
+
+// SelectStr is the selection expression from the select flag.  When it's blank, the selection routines prompt as before.
+var SelectStr string
+
+type selectCriterion func(in []FileInfoExType) ([]FileInfoExType, error)
+
+var indexListRegex = regexp.MustCompile(`^[0-9]+(-[0-9]+)?(,[0-9]+(-[0-9]+)?)*$`)
+var relativeTimeRegex = regexp.MustCompile(`^([0-9]+)([smhdw])$`)
+
+// ------------------------------------------------ Select -------------------------------------------------------------
+
+// Select returns the files of inList that meet the criteria of the selection expression, w/o prompting.  See the comments at the top of select.go for the criteria.
+func Select(inList []FileInfoExType, expr string) ([]FileInfoExType, error) {
+	criteria, err := parseSelection(expr)
+	if err != nil {
+		return nil, err
+	}
+	outList := inList
+	for _, criterion := range criteria {
+		outList, err = criterion(outList)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if VerboseFlag {
+		fmt.Printf(" Selected %d of %d files by %q\n", len(outList), len(inList), expr)
+	}
+	return outList, nil
+} // Select
+
+// ------------------------------------------------ parseSelection -----------------------------------------------------
+
+// parseSelection -- turns the expression into a slice of criteria, so a bad one is reported before any are applied.
+func parseSelection(expr string) ([]selectCriterion, error) {
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("selection expression is empty")
+	}
+	criteria := make([]selectCriterion, 0, len(fields))
+	now := time.Now()
+
+	for _, field := range fields {
+		key, value, hasValue := strings.Cut(field, "=")
+		key = strings.ToLower(key)
+		if !hasValue {
+			switch {
+			case key == "all":
+				criteria = append(criteria, func(in []FileInfoExType) ([]FileInfoExType, error) { return in, nil })
+				continue
+			case indexListRegex.MatchString(key):
+				key, value = "idx", key
+			default:
+				return nil, fmt.Errorf("selection criterion %q is not understood", field)
+			}
+		}
+		if value == "" {
+			return nil, fmt.Errorf("selection criterion %q needs a value", field)
+		}
+
+		switch key {
+		case "newest", "oldest", "largest", "smallest":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s needs a count, not %q", key, value)
+			}
+			criteria = append(criteria, selectTop(key, n))
+
+		case "size":
+			lo, hi, err := parseRange(value, parseSize)
+			if err != nil {
+				return nil, fmt.Errorf("size range %q: %w", value, err)
+			}
+			criteria = append(criteria, selectWhere(func(f FileInfoExType) bool {
+				return (lo < 0 || f.FI.Size() >= lo) && (hi < 0 || f.FI.Size() <= hi)
+			}))
+
+		case "mtime":
+			parseTime := func(s string) (int64, error) { return parseModTime(s, now) }
+			lo, hi, err := parseRange(value, parseTime)
+			if err != nil {
+				return nil, fmt.Errorf("mtime range %q: %w", value, err)
+			}
+			if !strings.Contains(value, "..") { // a single date is that whole day, and a single how long ago is since then.
+				if relativeTimeRegex.MatchString(strings.ToLower(value)) {
+					hi = -1
+				} else {
+					hi = lo + 24*60*60 - 1
+				}
+			}
+			criteria = append(criteria, selectWhere(func(f FileInfoExType) bool {
+				t := f.FI.ModTime().Unix()
+				return (lo < 0 || t >= lo) && (hi < 0 || t <= hi)
+			}))
+
+		case "rex", "exclude":
+			rex, err := regexp.Compile("(?i)" + value)
+			if err != nil {
+				return nil, fmt.Errorf("%s %q: %w", key, value, err)
+			}
+			want := key == "rex"
+			criteria = append(criteria, selectWhere(func(f FileInfoExType) bool {
+				return rex.MatchString(f.FI.Name()) == want
+			}))
+
+		case "idx":
+			if !indexListRegex.MatchString(value) {
+				return nil, fmt.Errorf("index list %q is not like 1-5,8", value)
+			}
+			criteria = append(criteria, selectIndexes(value))
+
+		default:
+			return nil, fmt.Errorf("selection criterion %q is not understood", field)
+		}
+	}
+	return criteria, nil
+} // parseSelection
+
+// selectWhere -- keeps the files for which keep is true.
+func selectWhere(keep func(f FileInfoExType) bool) selectCriterion {
+	return func(in []FileInfoExType) ([]FileInfoExType, error) {
+		out := make([]FileInfoExType, 0, len(in))
+		for _, f := range in {
+			if keep(f) {
+				out = append(out, f)
+			}
+		}
+		return out, nil
+	}
+}
+
+// selectTop -- the n newest, oldest, largest or smallest, kept in list order.
+func selectTop(which string, n int) selectCriterion {
+	return func(in []FileInfoExType) ([]FileInfoExType, error) {
+		if n >= len(in) {
+			return in, nil
+		}
+		order := make([]int, len(in))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := in[order[i]].FI, in[order[j]].FI
+			switch which {
+			case "newest":
+				return a.ModTime().After(b.ModTime())
+			case "oldest":
+				return a.ModTime().Before(b.ModTime())
+			case "largest":
+				return a.Size() > b.Size()
+			}
+			return a.Size() < b.Size()
+		})
+		order = order[:n]
+		sort.Ints(order)
+		out := make([]FileInfoExType, 0, n)
+		for _, i := range order {
+			out = append(out, in[i])
+		}
+		return out, nil
+	}
+}
+
+// selectIndexes -- 1 based positions.  An index past the end is an error, as a script that deletes or moves files shouldn't guess.
+func selectIndexes(indexList string) selectCriterion {
+	return func(in []FileInfoExType) ([]FileInfoExType, error) {
+		chosen := make(map[int]bool)
+		for _, part := range strings.Split(indexList, ",") {
+			begStr, endStr, isRange := strings.Cut(part, "-")
+			beg, _ := strconv.Atoi(begStr) // the regexp already made sure these are digits.
+			end := beg
+			if isRange {
+				end, _ = strconv.Atoi(endStr)
+			}
+			if beg < 1 || end < beg {
+				return nil, fmt.Errorf("index range %q is backwards or starts at zero", part)
+			}
+			if end > len(in) {
+				return nil, fmt.Errorf("index %d is past the end of the list, which has %d files", end, len(in))
+			}
+			for i := beg; i <= end; i++ {
+				chosen[i-1] = true
+			}
+		}
+		out := make([]FileInfoExType, 0, len(chosen))
+		for i, f := range in {
+			if chosen[i] {
+				out = append(out, f)
+			}
+		}
+		return out, nil
+	}
+}
+
+// ------------------------------------------------ parsing helpers ----------------------------------------------------
+
+// parseRange -- LO..HI, LO.. or ..HI, or a single value meaning exactly that.  A missing end is returned as -1.
+func parseRange(s string, parse func(string) (int64, error)) (int64, int64, error) {
+	loStr, hiStr, isRange := strings.Cut(s, "..")
+	if !isRange {
+		v, err := parse(s)
+		return v, v, err
+	}
+	lo, hi := int64(-1), int64(-1)
+	var err error
+	if loStr != "" {
+		if lo, err = parse(loStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	if hiStr != "" {
+		if hi, err = parse(hiStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	if lo < 0 && hi < 0 {
+		return 0, 0, fmt.Errorf("needs at least one end")
+	}
+	if lo >= 0 && hi >= 0 && hi < lo {
+		lo, hi = hi, lo // so mtime=7d..1d works as well as 1d..7d
+	}
+	return lo, hi, nil
+}
+
+// parseSize -- a number that can end in k, m, g or t, which are powers of 1000 like the filter flag.  A trailing b is ignored, so 10mb is fine.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSuffix(strings.ToLower(s), "b")
+	mult := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k':
+			mult = 1000
+		case 'm':
+			mult = 1_000_000
+		case 'g':
+			mult = 1_000_000_000
+		case 't':
+			mult = 1_000_000_000_000
+		}
+		if mult > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("%q is not a size", s)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+// parseModTime -- a date, or how long before now, as Unix seconds.  A date w/o a time is the start of that day.
+func parseModTime(s string, now time.Time) (int64, error) {
+	if m := relativeTimeRegex.FindStringSubmatch(strings.ToLower(s)); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[2]]
+		return now.Add(-time.Duration(n) * unit).Unix(), nil
+	}
+	t, err := timlibg.ParseDate(s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
@@ -0,0 +1,87 @@
+package list
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+type fakeFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (f fakeFileInfo) Name() string       { return f.name }
+func (f fakeFileInfo) Size() int64        { return f.size }
+func (f fakeFileInfo) Mode() os.FileMode  { return 0644 }
+func (f fakeFileInfo) ModTime() time.Time { return f.modTime }
+func (f fakeFileInfo) IsDir() bool        { return false }
+func (f fakeFileInfo) Sys() any           { return nil }
+
+func makeSelectList() []FileInfoExType {
+	now := time.Now()
+	files := []fakeFileInfo{
+		{"a.jpg", 500, now.Add(-1 * time.Hour)},
+		{"b.txt", 2_000_000, now.Add(-48 * time.Hour)},
+		{"c.JPG", 3_000_000, now.Add(-10 * 24 * time.Hour)},
+		{"d.mp4", 40_000_000, now.Add(-2 * time.Hour)},
+		{"e.jpg", 1500, time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)},
+	}
+	lst := make([]FileInfoExType, 0, len(files))
+	for _, f := range files {
+		lst = append(lst, FileInfoExType{FI: f, RelPath: f.name, AbsPath: f.name, FullPath: f.name})
+	}
+	return lst
+}
+
+func TestSelect(t *testing.T) {
+	var tests = []struct {
+		expr string
+		out  string
+	}{
+		{"all", "abcde"},
+		{"1-3,5", "abce"},
+		{"idx=2", "b"},
+		{"newest=2", "ad"},
+		{"oldest=1", "e"},
+		{"largest=2", "cd"},
+		{"smallest=2", "ae"},
+		{"size=1m..", "bcd"},
+		{"size=..2kb", "ae"},
+		{"size=1k..5m", "bce"},
+		{"mtime=3h", "ad"},
+		{"mtime=..7d", "ce"},
+		{"mtime=2024-03-15", "e"},
+		{"rex=\\.jpg$", "ace"},
+		{"exclude=jpg", "bd"},
+		{"rex=jpg newest=2", "ac"},
+		{"1-3 size=1m..", "bc"},
+		{"size=1m.. 1-2", "bc"},
+	}
+
+	lst := makeSelectList()
+	for _, test := range tests {
+		result, err := Select(lst, test.expr)
+		if err != nil {
+			t.Errorf("Select(%q) error is %s", test.expr, err)
+			continue
+		}
+		got := ""
+		for _, f := range result {
+			got += f.FI.Name()[:1]
+		}
+		if got != test.out {
+			t.Errorf("Select(%q) should be %s, but it is %s instead", test.expr, test.out, got)
+		}
+	}
+}
+
+func TestSelectErrors(t *testing.T) {
+	lst := makeSelectList()
+	for _, expr := range []string{"", "1-9", "3-1", "0", "newest=x", "size=..", "size=big", "mtime=yesterday", "rex=(", "bogus", "bogus=1", "newest="} {
+		if _, err := Select(lst, expr); err == nil {
+			t.Errorf("Select(%q) should be an error, but it isn't", expr)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+/*
+  REVISION HISTORY
+  -------- -------
+  18 Oct 26 -- Recursive mode, so the list commands can work on nested project folders.  When RecurseFlag is set, the directory reading routines walk the subdirectories too.
+                 MaxDepth limits how far down it goes, where 1 is only the subdirectories of the top directory, and 0 is no limit.
+                 DirExcludeRex skips a directory and everything under it.  DirIncludeRex lists the files of only the directories whose subpath matches, but doesn't stop the walk,
+                 so "2024" finds photos/2024/jan.  Both are matched against the lower case subpath w/ forward slashes, and the top directory's subpath is "".
+                 Symlinked directories are followed, but each real directory is only read once, so a symlink loop can't send the walk around forever.
+                 It's the limited fanout pattern, w/ numWorkers directories read at a time, fetch entries per ReadDir call.
+                 SubDir in FileInfoExType is the subpath of the file's directory under the top directory, so cf2 and copyc can recreate the tree at the destination.
+*/
+
+// RecurseFlag means to walk the subdirectories of the directory being read.
+var RecurseFlag bool
+
+// MaxDepth is how many levels of subdirectories to walk when RecurseFlag is set.  Zero means no limit.
+var MaxDepth int
+
+// DirIncludeRex and DirExcludeRex are matched against the lower case subpath of a directory w/ forward slashes.  They're only used when RecurseFlag is set.
+var DirIncludeRex, DirExcludeRex *regexp.Regexp
+
+type walkState struct {
+	root    string
+	include func(fi os.FileInfo) bool
+	wg      sync.WaitGroup
+	sem     chan struct{} // limits the number of directories being read at once to numWorkers.
+	fixChan chan FileInfoExType
+	mu      sync.Mutex
+	visited map[string]bool // real paths of the directories already read, for the symlink loop protection.
+}
+
+// ------------------------------------------------ SetDirRegexps ------------------------------------------------------
+
+// SetDirRegexps compiles the directory include and exclude patterns from the command line.  Either can be blank.  Like the exclude regex, they're not case sensitive.
+func SetDirRegexps(includePattern, excludePattern string) error {
+	var err error
+	DirIncludeRex, DirExcludeRex = nil, nil
+	if includePattern != "" {
+		if DirIncludeRex, err = regexp.Compile(strings.ToLower(includePattern)); err != nil {
+			return fmt.Errorf("directory include pattern: %w", err)
+		}
+	}
+	if excludePattern != "" {
+		if DirExcludeRex, err = regexp.Compile(strings.ToLower(excludePattern)); err != nil {
+			return fmt.Errorf("directory exclude pattern: %w", err)
+		}
+	}
+	return nil
+} // SetDirRegexps
+
+// ------------------------------------------------ DestDirFor ---------------------------------------------------------
+
+// DestDirFor returns the directory under destDir that f goes in, creating it if needed, so a recursive copy has the same tree as the source.
+// When f isn't from a recursive walk, it's just destDir.
+func DestDirFor(f FileInfoExType, destDir string) (string, error) {
+	if f.SubDir == "" {
+		return destDir, nil
+	}
+	d := filepath.Join(destDir, f.SubDir) + sepString
+	if err := os.MkdirAll(d, 0755); err != nil {
+		return destDir, err
+	}
+	return d, nil
+} // DestDirFor
+
+// ------------------------------------------------ myReadDirRecursive -------------------------------------------------
+
+// myReadDirRecursive -- reads root and its subdirectories, w/ include deciding which files are in the returned slice.
+func myReadDirRecursive(root string, include func(fi os.FileInfo) bool) ([]FileInfoExType, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+	ws := &walkState{
+		root:    root,
+		include: include,
+		sem:     make(chan struct{}, numWorkers),
+		fixChan: make(chan FileInfoExType, numWorkers),
+		visited: make(map[string]bool),
+	}
+	if VerboseFlag {
+		fmt.Printf("Walking directory %s, numworkers = %d, max depth = %d\n", root, numWorkers, MaxDepth)
+	}
+
+	fixSlice := make([]FileInfoExType, 0, fetch)
+	doneChan := make(chan bool)
+	go func() {
+		for fix := range ws.fixChan {
+			fixSlice = append(fixSlice, fix)
+		}
+		close(doneChan)
+	}()
+
+	ws.walk(root, "", 0)
+	ws.wg.Wait()
+	close(ws.fixChan)
+	<-doneChan
+
+	if VerboseFlag {
+		fmt.Printf("Found %d files in %d directories under %s.\n", len(fixSlice), len(ws.visited), root)
+	}
+	return fixSlice, nil
+} // myReadDirRecursive
+
+// walk -- starts a goroutine to read dir, which starts another for each subdirectory.  Only numWorkers of them read at once.
+func (ws *walkState) walk(dir, subDir string, depth int) {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " ERROR from EvalSymlinks(%s) is %s.  Skipped.\n", dir, err)
+		return
+	}
+	if abs, err := filepath.Abs(realDir); err == nil {
+		realDir = abs
+	}
+	ws.mu.Lock()
+	if ws.visited[realDir] {
+		ws.mu.Unlock()
+		if VerboseFlag {
+			fmt.Printf(" %s is %s, which was already read.  Skipped.\n", dir, realDir)
+		}
+		return
+	}
+	ws.visited[realDir] = true
+	ws.mu.Unlock()
+
+	ws.wg.Add(1)
+	go func() {
+		defer ws.wg.Done()
+		ws.sem <- struct{}{}
+		subDirs := ws.readOneDir(dir, subDir, depth)
+		<-ws.sem // released before walking the subdirectories, so a deep tree can't use up all the slots waiting on itself.
+		for _, sd := range subDirs {
+			ws.walk(filepath.Join(dir, sd), filepath.Join(subDir, sd), depth+1)
+		}
+	}()
+} // walk
+
+// readOneDir -- sends the files of dir that are included to fixChan, and returns the names of the subdirectories to walk.
+func (ws *walkState) readOneDir(dir, subDir string, depth int) []string {
+	d, err := os.Open(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, " ERROR from os.Open(%s) is %s.  Skipped.\n", dir, err)
+		return nil
+	}
+	defer d.Close()
+
+	listFiles := DirIncludeRex == nil || DirIncludeRex.MatchString(slashPath(subDir))
+	descend := MaxDepth <= 0 || depth < MaxDepth
+	subDirs := make([]string, 0, 10)
+	for {
+		deSlice, err := d.ReadDir(fetch)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " ERROR from %s.ReadDir(%d) is %s.\n", dir, fetch, err)
+			break
+		}
+		for _, de := range deSlice {
+			fi, err := de.Info()
+			if err != nil {
+				fmt.Printf("Error getting file info for %s: %v, ignored\n", de.Name(), err)
+				continue
+			}
+			isDir := fi.IsDir()
+			if fi.Mode()&os.ModeSymlink != 0 {
+				if target, err := os.Stat(filepath.Join(dir, fi.Name())); err == nil && target.IsDir() {
+					isDir = true
+				}
+			}
+			if isDir {
+				if descend && (DirExcludeRex == nil || !DirExcludeRex.MatchString(slashPath(filepath.Join(subDir, fi.Name())))) {
+					subDirs = append(subDirs, fi.Name())
+				}
+				continue
+			}
+			if listFiles && ws.include(fi) {
+				joinedFilename := filepath.Join(dir, fi.Name())
+				ws.fixChan <- FileInfoExType{
+					FI:       fi,
+					Dir:      dir,
+					RelPath:  joinedFilename, // has the subpath, as dir is under the top directory.
+					AbsPath:  joinedFilename,
+					FullPath: joinedFilename,
+					SubDir:   subDir,
+				}
+			}
+		}
+	}
+	return subDirs
+} // readOneDir
+
+// slashPath -- lower case w/ forward slashes, so the directory regexps work the same on Windows.
+func slashPath(s string) string {
+	return strings.ToLower(filepath.ToSlash(s))
+}
@@ -0,0 +1,84 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// makeTree -- top/a.txt, top/sub/b.txt, top/sub/deeper/c.txt, top/skip/d.txt and a symlink top/sub/deeper/loop -> top.
+func makeTree(t *testing.T) string {
+	top := t.TempDir()
+	for _, f := range []string{"a.txt", "sub/b.txt", "sub/deeper/c.txt", "skip/d.txt"} {
+		path := filepath.Join(top, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(top, filepath.Join(top, "sub", "deeper", "loop")); err != nil {
+		t.Logf("no symlink, so the loop isn't tested: %s", err)
+	}
+	return top
+}
+
+func walkNames(t *testing.T, top string) string {
+	lst, err := myReadDirRecursive(top, includeThisForConcurrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(lst))
+	for _, f := range lst {
+		names = append(names, filepath.ToSlash(filepath.Join(f.SubDir, f.FI.Name())))
+		if f.RelPath != filepath.Join(top, f.SubDir, f.FI.Name()) {
+			t.Errorf("RelPath %s doesn't have the subpath %s", f.RelPath, f.SubDir)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestReadDirRecursive(t *testing.T) {
+	top := makeTree(t)
+	defer func() { MaxDepth, DirIncludeRex, DirExcludeRex, filterAmt = 0, nil, nil, 0 }()
+
+	var tests = []struct {
+		maxDepth         int
+		include, exclude string
+		out              string
+	}{
+		{0, "", "", "a.txt skip/d.txt sub/b.txt sub/deeper/c.txt"},
+		{1, "", "", "a.txt skip/d.txt sub/b.txt"},
+		{0, "", "^skip$", "a.txt sub/b.txt sub/deeper/c.txt"},
+		{0, "^sub", "", "sub/b.txt sub/deeper/c.txt"},
+		{0, "DEEPER", "", "sub/deeper/c.txt"},
+		{0, "", "^sub$", "a.txt skip/d.txt"},
+	}
+	for _, test := range tests {
+		MaxDepth = test.maxDepth
+		if err := SetDirRegexps(test.include, test.exclude); err != nil {
+			t.Fatal(err)
+		}
+		if got := walkNames(t, top); got != test.out {
+			t.Errorf("maxdepth=%d, include=%q, exclude=%q should be %q, but it is %q", test.maxDepth, test.include, test.exclude, test.out, got)
+		}
+	}
+}
+
+func TestDestDirFor(t *testing.T) {
+	dest := t.TempDir()
+	d, err := DestDirFor(FileInfoExType{SubDir: filepath.Join("sub", "deeper")}, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(d); err != nil || !fi.IsDir() {
+		t.Errorf("DestDirFor should have made %s, err = %v", d, err)
+	}
+	if d, _ = DestDirFor(FileInfoExType{}, dest); d != dest {
+		t.Errorf("DestDirFor w/o a SubDir should be %s, but it is %s", dest, d)
+	}
+}
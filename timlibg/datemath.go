@@ -0,0 +1,239 @@
+package timlibg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+  REVISION HISTORY
+  ----------------
+  18 Oct 26 -- Date arithmetic that calgo, lint, taxproc and citibankdateconvert each had their own version of.  AddMonths clamps to the end of the month, so Jan 31 + 1 month is Feb 28 or 29.
+                 ISO week and week-year, day of year, and business days that skip weekends and a HolidaySet.  holidaycalc fills a HolidaySet, since holidaycalc already imports timlibg.
+                 ParseDate takes the formats in our bank, Excel and SQLite data, and FormatDate writes them back out.
+*/
+
+// HolidaySet -- the days that are not business days besides Sat and Sun, keyed by Julian date number.  holidaycalc.HolidaySet returns one.
+type HolidaySet map[int]bool
+
+// DateFormat selects the output of FormatDate.
+type DateFormat int
+
+const (
+	FormatISO      DateFormat = iota // 2006-01-02, which is also SQLite's date()
+	FormatUS                         // 01/02/2006, as in the Citibank and most US bank downloads
+	FormatUSShort                    // 1/2/06
+	FormatSQLite                     // 2006-01-02 15:04:05, SQLite's datetime()
+	FormatOFX                        // 20060102, as in OFX and QFX bank files
+	FormatExcel                      // Excel serial day number, day 1 is 1/1/1900
+	FormatLong                       // January 2, 2006
+	FormatDayMonYr                   // 02-Jan-2006, as some banks write it
+)
+
+const excelOffset = 693594 // Julian date number of Excel day zero, 12/31/1899.  Same number as in FromExcelToJul.
+
+// The order matters, as the first one that parses wins.  2 digit years must come after the 4 digit years of the same layout.
+var parseLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006/1/2",
+	"1/2/2006",
+	"1/2/06",
+	"1-2-2006",
+	"1-2-06",
+	"02-Jan-2006",
+	"2-Jan-06",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"Mon Jan 2 2006",
+	"20060102",
+	"20060102150405",
+}
+
+// ----------------------------------------------- DaysInMonth, IsLeapYear
+
+// IsLeapYear -- Gregorian rules, so 1900 is not and 2000 is.
+func IsLeapYear(y int) bool {
+	return (y%4 == 0 && y%100 != 0) || y%400 == 0
+}
+
+// DaysInMonth -- m is 1..12.
+func DaysInMonth(m, y int) int {
+	switch m {
+	case 2:
+		if IsLeapYear(y) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// ----------------------------------------------- AddMonths
+
+// AddMonths -- adds n months, which can be negative.  If the day doesn't exist in the new month, it's the last day of that month, unlike time.AddDate which rolls over.
+func AddMonths(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	months := int(m) - 1 + n
+	y += months / 12
+	months %= 12
+	if months < 0 {
+		months += 12
+		y--
+	}
+	newMonth := months + 1
+	if maxDay := DaysInMonth(newMonth, y); d > maxDay {
+		d = maxDay
+	}
+	return time.Date(y, time.Month(newMonth), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+} // AddMonths
+
+// ----------------------------------------------- DayOfYear, ISOWeek
+
+// DayOfYear -- returns 1..365, or 1..366 in a leap year.
+func DayOfYear(t time.Time) int {
+	return t.YearDay()
+}
+
+// ISOWeek -- returns the ISO-8601 week-year and week number 1..53.  Weeks start on Monday, and week 1 has the year's first Thursday,
+// so Jan 1 can be in week 52 or 53 of the previous week-year, and Dec 31 can be in week 1 of the next.
+func ISOWeek(t time.Time) (weekYear, week int) {
+	return t.ISOWeek()
+}
+
+// ----------------------------------------------- JulianFromTime, TimeFromJulian
+
+// JulianFromTime -- returns my Julian date number for the date part of t.
+func JulianFromTime(t time.Time) int {
+	return JULIAN(int(t.Month()), t.Day(), t.Year())
+}
+
+// TimeFromJulian -- returns midnight local time of the Julian date number.
+func TimeFromJulian(juldate int) time.Time {
+	m, d, y := GREGORIAN(juldate)
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.Local)
+}
+
+// ----------------------------------------------- Business days
+
+// NewHolidaySet -- returns a HolidaySet of the given dates.
+func NewHolidaySet(dates ...time.Time) HolidaySet {
+	hols := make(HolidaySet, len(dates))
+	for _, t := range dates {
+		hols.Add(t)
+	}
+	return hols
+}
+
+// Add -- adds the date part of t.
+func (hols HolidaySet) Add(t time.Time) {
+	hols[JulianFromTime(t)] = true
+}
+
+// Contains -- a nil HolidaySet contains nothing, so only weekends are skipped.
+func (hols HolidaySet) Contains(t time.Time) bool {
+	return hols[JulianFromTime(t)]
+}
+
+// IsBusinessDay -- not Sat, not Sun and not in hols.
+func IsBusinessDay(t time.Time, hols HolidaySet) bool {
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday && !hols.Contains(t)
+}
+
+// AddBusinessDays -- moves n business days forward, or back if n < 0.  If n is zero and t is not a business day, t is returned unchanged.
+func AddBusinessDays(t time.Time, n int, hols HolidaySet) time.Time {
+	step := 1
+	if n < 0 {
+		step = -1
+		n = -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if IsBusinessDay(t, hols) {
+			n--
+		}
+	}
+	return t
+} // AddBusinessDays
+
+// BusinessDaysBetween -- returns the number of business days after start up to and including end, so AddBusinessDays(start, n) is end when end is a business day.
+// It's negative if end is before start.  Excel's NETWORKDAYS also counts start, so it's 1 more when start is a business day.
+func BusinessDaysBetween(start, end time.Time, hols HolidaySet) int {
+	sign := 1
+	startJul, endJul := JulianFromTime(start), JulianFromTime(end)
+	if endJul < startJul {
+		startJul, endJul = endJul, startJul
+		start, end = end, start
+		sign = -1
+	}
+	count := 0
+	t := time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, time.UTC) // noon UTC so DST changes can't skip or repeat a day.
+	for j := startJul + 1; j <= endJul; j++ {
+		t = t.AddDate(0, 0, 1)
+		if IsBusinessDay(t, hols) {
+			count++
+		}
+	}
+	return sign * count
+} // BusinessDaysBetween
+
+// ----------------------------------------------- ParseDate, FormatDate
+
+// ParseDate -- tries the date formats in our data, ISO and SQLite, US m/d/y w/ 2 or 4 digit years, OFX yyyymmdd, bank dd-Mon-yyyy, and the long forms.
+// A number of 5 digits or fewer is taken as an Excel serial day number.  Dates are in local time.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(strings.Trim(strings.TrimSpace(s), `"'`))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date string")
+	}
+	if len(s) <= 5 {
+		if xl, err := strconv.Atoi(s); err == nil {
+			if xl < 1 {
+				return time.Time{}, fmt.Errorf("excel date number %d is before 1/1/1900", xl)
+			}
+			m, d, y := FromExcelToGreg(xl)
+			return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.Local), nil
+		}
+	}
+	for _, layout := range parseLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("date %q is not in a format I know", s)
+} // ParseDate
+
+// FormatDate -- writes t in one of the formats ParseDate reads.
+func FormatDate(t time.Time, format DateFormat) string {
+	switch format {
+	case FormatUS:
+		return t.Format("01/02/2006")
+	case FormatUSShort:
+		return t.Format("1/2/06")
+	case FormatSQLite:
+		return t.Format(time.DateTime)
+	case FormatOFX:
+		return t.Format("20060102")
+	case FormatExcel:
+		return strconv.Itoa(ToExcel(t))
+	case FormatLong:
+		return t.Format("January 2, 2006")
+	case FormatDayMonYr:
+		return t.Format("02-Jan-2006")
+	}
+	return t.Format(time.DateOnly)
+} // FormatDate
+
+// ToExcel -- returns the Excel serial day number, the inverse of FromExcelToGreg.
+func ToExcel(t time.Time) int {
+	return JulianFromTime(t) - excelOffset
+}
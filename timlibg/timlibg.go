@@ -29,6 +29,7 @@ import (
 	28 Nov 25 -- Fixed bug in JULIAN because "y" was not assigned correctly.
     20 Feb 26 -- Added SecToHMS
 	19 Apr 26 -- Added WeekDayNum and YearDayNum based on functions in the time package.  And added Julian and Gregorian that wrap their respective functions.
+	18 Oct 26 -- Added datemath.go, w/ AddMonths, ISOWeek, DayOfYear, business days, and ParseDate and FormatDate for the formats in our bank, Excel and SQLite data.
 */
 
 // DateTimeType -- fields are Rawtime, Month, Day, Year, Hours, Minutes, Seconds, Nanosec, WeekDayNum, YearDayNum, MonthStr and DayOfWeekStr.
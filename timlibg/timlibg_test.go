@@ -70,3 +70,94 @@ func TestGetDateTime(t *testing.T) {
 		t.Errorf("july4DanNum = %d, JuldateJuly4 = %d", july4DayNum, JuldateJuly4)
 	}
 }
+
+func TestAddMonths(t *testing.T) {
+	var tests = []struct {
+		date string
+		n    int
+		want string
+	}{
+		{"2025-01-31", 1, "2025-02-28"},
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2024-03-31", -1, "2024-02-29"},
+		{"2025-05-31", 1, "2025-06-30"},
+		{"2025-11-15", 3, "2026-02-15"},
+		{"2025-02-15", -14, "2023-12-15"},
+		{"2025-12-31", 12, "2026-12-31"},
+	}
+	for _, test := range tests {
+		d, _ := time.Parse(time.DateOnly, test.date)
+		got := AddMonths(d, test.n).Format(time.DateOnly)
+		if got != test.want {
+			t.Errorf("AddMonths(%s, %d) = %s, want %s", test.date, test.n, got, test.want)
+		}
+	}
+}
+
+func TestISOWeek(t *testing.T) {
+	var tests = []struct {
+		date           string
+		weekYear, week int
+		dayOfYear      int
+	}{
+		{"2021-01-03", 2020, 53, 3},
+		{"2024-12-30", 2025, 1, 365},
+		{"2026-10-18", 2026, 42, 291},
+		{"2024-12-31", 2025, 1, 366},
+	}
+	for _, test := range tests {
+		d, _ := time.Parse(time.DateOnly, test.date)
+		wy, w := ISOWeek(d)
+		if wy != test.weekYear || w != test.week || DayOfYear(d) != test.dayOfYear {
+			t.Errorf("%s is week %d-%d day %d, want %d-%d day %d", test.date, wy, w, DayOfYear(d), test.weekYear, test.week, test.dayOfYear)
+		}
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	july4, _ := time.Parse(time.DateOnly, "2025-07-04") // a Friday
+	hols := NewHolidaySet(july4)
+	thu, _ := time.Parse(time.DateOnly, "2025-07-03")
+	got := AddBusinessDays(thu, 1, hols).Format(time.DateOnly)
+	if got != "2025-07-07" {
+		t.Errorf("AddBusinessDays(Thu 7/3, 1) = %s, want Mon 2025-07-07", got)
+	}
+	back := AddBusinessDays(AddBusinessDays(thu, 10, hols), -10, hols)
+	if !back.Equal(thu) {
+		t.Errorf("10 business days forward and back from 7/3 is %s", back.Format(time.DateOnly))
+	}
+	end := AddBusinessDays(thu, 10, hols)
+	if n := BusinessDaysBetween(thu, end, hols); n != 10 {
+		t.Errorf("BusinessDaysBetween(7/3, %s) = %d, want 10", end.Format(time.DateOnly), n)
+	}
+	if n := BusinessDaysBetween(end, thu, hols); n != -10 {
+		t.Errorf("BusinessDaysBetween(%s, 7/3) = %d, want -10", end.Format(time.DateOnly), n)
+	}
+	if n := BusinessDaysBetween(thu, end, nil); n != 11 {
+		t.Errorf("BusinessDaysBetween w/o holidays = %d, want 11", n)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2025, 3, 7, 0, 0, 0, 0, time.Local)
+	for _, s := range []string{"2025-03-07", "03/07/2025", "3/7/2025", "3/7/25", "20250307", "07-Mar-2025", "March 7, 2025", "45723", "2025-03-07 00:00:00"} {
+		d, err := ParseDate(s)
+		if err != nil || !d.Equal(want) {
+			t.Errorf("ParseDate(%q) = %s, %v, want 2025-03-07", s, d, err)
+		}
+	}
+	if _, err := ParseDate("13/45/2025"); err == nil {
+		t.Errorf("ParseDate(13/45/2025) should be an error")
+	}
+
+	for f, s := range map[DateFormat]string{FormatISO: "2025-03-07", FormatUS: "03/07/2025", FormatUSShort: "3/7/25", FormatOFX: "20250307",
+		FormatExcel: "45723", FormatLong: "March 7, 2025", FormatDayMonYr: "07-Mar-2025", FormatSQLite: "2025-03-07 00:00:00"} {
+		if got := FormatDate(want, f); got != s {
+			t.Errorf("FormatDate(%d) = %s, want %s", f, got, s)
+		}
+		back, err := ParseDate(FormatDate(want, f))
+		if err != nil || !back.Equal(want) {
+			t.Errorf("ParseDate(FormatDate(%d)) = %s, %v", f, back, err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 /*
@@ -94,9 +95,13 @@ REVISION HISTORY
 11 Jun 26 -- Using a byte to represent a character is only true for ASCII.  I need to use rune, but I'll do that in a different package.
 15 Jun 26 -- Removed STOTKNPOSN and RCLTKNPOSN.  These were from the Modula-2 days, were never used, but never removed.  Until now.
 16 Jun 26 -- Removed HOLDCURPOSN and HoldLineBS.  These were from the Modula-2 days, were never used, but never removed.  Until now.
+18 Oct 26 -- Added "0b" and "0o" prefixes for binary and octal, same as "0x" for hex.  The prefix isn't part of Str, and BinFlag or OctFlag is set.  For the programmer mode in hpcalc2.
+               And every token now has Offset, the byte offset of its first character, and Column, the rune column starting at 1.  So a front end can point at a token.
+               And added GetTokenErr and TokenRealErr, which also return a *SyntaxError for a bad hex, binary or octal digit, a malformed real, or a missing closing quote.
+               Before this, those just produced a garbage token.  GetToken and TokenReal don't change, so nothing that calls them has to change.
 */
 
-const LastAltered = "16 June 2026"
+const LastAltered = "18 Oct 2026"
 
 const (
 	DELIM = iota // so DELIM = 0, and so on.  And the zero val needs to be DELIM.
@@ -116,8 +121,30 @@ type TokenType struct {
 	Rsum       float64
 	RealFlag   bool // flag so integer processing stops when it sees a dot, E or e.
 	HexFlag    bool // only way I know of to signal that the input string is a hex format.
+	BinFlag    bool // "0b" prefix
+	OctFlag    bool // "0o" prefix
+	Offset     int  // byte offset in the line of the first character of the token, including a sign or quote.
+	Column     int  // rune column of the first character, starting at 1, for error messages.
 } // TokenType record
 
+// SyntaxError is returned by GetTokenErr and TokenRealErr.  Offset and Column are of the bad character, not of the start of the token.
+type SyntaxError struct {
+	Line     string
+	Offset   int
+	Column   int
+	Expected string
+	Found    string
+} // SyntaxError
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: expected %s, found %s", e.Column, e.Expected, e.Found)
+}
+
+// Caret -- returns the line w/ a second line that has a ^ under the bad character.
+func (e *SyntaxError) Caret() string {
+	return e.Line + "\n" + strings.Repeat(" ", e.Column-1) + "^"
+}
+
 // CharType fields are Ch byte and State int.
 type CharType struct {
 	Ch    byte
@@ -128,6 +155,7 @@ type BufferState struct {
 	CURPOSN, PREVPOSN int
 	lineByteSlice     []byte
 	StateMap          map[byte]int // as of 9/28/20, StateMap is part of this structure.
+	tknErr            error        // set by the last token fetched, for GetTokenErr and TokenRealErr.
 }
 
 var FSAnameType = [...]string{"DELIM", "OP", "DGT", "ALLELSE"}
@@ -170,6 +198,7 @@ const EXPSIGN = '^'
 const PERCNT = '%'
 
 var wantReal bool // used by TokenReal
+var wantErr bool  // used by TokenRealErr, so TokenReal doesn't print the error as well.
 
 // These variables are declared here to make the variable global so to maintain their values btwn calls.
 
@@ -391,10 +420,15 @@ func (bufState *BufferState) GetToken(UpperCase bool) (TOKEN TokenType, EOL bool
 	var NEGATV, QUOFLG bool
 	TOKEN = TokenType{}                    // This will zero out all the fields by using a nil struct literal.  It's the default; I put it here so I remember.
 	tokenByteSlice := make([]byte, 0, 200) // to build up the TOKEN.Str field
+	start := -1                            // byte offset of the first character of the token
+	bufState.tknErr = nil
 
 ExitForLoop:
 	for {
 		CHAR, EOL = bufState.GETCHR()
+		if start < 0 && !EOL && CHAR.State != DELIM {
+			start = bufState.CURPOSN - 1
+		}
 		if EOL {
 			//          If TKNSTATE is DELIM, then gettkn was called when there were
 			//          no more tokens on line.  Otherwise it means that we have fetched the last
@@ -549,6 +583,16 @@ ExitForLoop:
 					bufState.StateMap['F'] = DGT
 					continue
 				}
+				if string(tokenByteSlice) == "0" && !TOKEN.HexFlag { // "0b" or "0o" prefix.  The binary and octal digits are already DGT.
+					switch rune(CHAR.Ch) {
+					case 'b', 'B':
+						TOKEN.BinFlag = true
+						continue
+					case 'o', 'O':
+						TOKEN.OctFlag = true
+						continue
+					}
+				}
 
 				bufState.UNGETCHR()
 				break ExitForLoop //goto ExitLoop;
@@ -592,6 +636,10 @@ ExitForLoop:
 	} else {
 		TOKEN.Str = string(tokenByteSlice) // Trying to apply idiomatic Go guidelines to use byte slice intermediate.
 	}
+	bufState.setPosn(&TOKEN, start)
+	if QUOFLG {
+		bufState.tknErr = bufState.syntaxError(len(bufState.lineByteSlice), "closing "+string(QUOCHR), "end of line")
+	}
 	TOKEN.DelimCH = CHAR.Ch
 	TOKEN.DelimState = CHAR.State
 	TOKEN.FullString = TOKEN.Str
@@ -619,11 +667,16 @@ ExitForLoop:
 			bufState.StateMap['D'] = ALLELSE
 			bufState.StateMap['E'] = ALLELSE
 			bufState.StateMap['F'] = ALLELSE
+		} else if TOKEN.BinFlag {
+			TOKEN.Isum = FromBase(TOKEN.Str, 2)
+		} else if TOKEN.OctFlag {
+			TOKEN.Isum = FromBase(TOKEN.Str, 8)
 		}
 		if NEGATV {
 			TOKEN.Isum = -TOKEN.Isum
 			TOKEN.FullString = "-" + TOKEN.Str
 		}
+		bufState.tknErr = bufState.checkDigits(TOKEN)
 		return TOKEN, false
 	}
 
@@ -677,6 +730,17 @@ func FromHex(s string) int {
 	return result
 } // FromHex
 
+// FromBase -- input a string of binary or octal digits and the base, and returns int.  Like FromHex, other characters are ignored.
+func FromBase(s string, base int) int {
+	result := 0
+	for _, dgtchar := range s {
+		if isdigit(dgtchar) && int(dgtchar)-Dgt0 < base {
+			result = base*result + int(dgtchar) - Dgt0
+		}
+	}
+	return result
+} // FromBase
+
 // ---------------------------------------- SetMapDelim -----------------------------------------
 // SetMapDelim -- input a byte that will be included in the characters that are used as delimiters.
 func (bufState *BufferState) SetMapDelim(char byte) {
@@ -707,13 +771,18 @@ func (bufState *BufferState) TokenReal() (TokenType, bool) {
 
 	if token.State == DGT {
 		token.FullString = strings.ReplaceAll(token.FullString, "_", "-")
-		if token.HexFlag {
+		if token.HexFlag || token.BinFlag || token.OctFlag {
 			token.Rsum = float64(token.Isum)
 		} else {
 			token.Rsum, err = strconv.ParseFloat(token.FullString, 64) // FullString field now includes the sign character, if given.
 		}
 		if err != nil {
-			fmt.Printf(" in TokenReal after call to strconv.ParseFloat(%s, 64).  err = %s\n", token.Str, err)
+			if bufState.tknErr == nil {
+				bufState.tknErr = bufState.syntaxError(token.Offset, "a real number", fmt.Sprintf("%q", token.FullString))
+			}
+			if !wantErr {
+				fmt.Printf(" in TokenReal after call to strconv.ParseFloat(%s, 64).  err = %s\n", token.Str, err)
+			}
 		}
 	}
 	bufState.StateMap['_'] = ALLELSE // make sure the underscore is back to the type it's supposed to be.
@@ -725,6 +794,94 @@ func (bufState *BufferState) TokenReal() (TokenType, bool) {
 
 } // TokenReal
 
+// ------------------------------------------- setPosn, syntaxError ------------------------------------
+
+// setPosn -- sets the Offset and Column fields.  A start < 0 means no characters were found, so the position is the end of the line.
+func (bufState *BufferState) setPosn(token *TokenType, start int) {
+	if start < 0 || start > len(bufState.lineByteSlice) {
+		start = len(bufState.lineByteSlice)
+	}
+	token.Offset = start
+	token.Column = bufState.column(start)
+}
+
+// column -- converts a byte offset to a rune column, starting at 1.
+func (bufState *BufferState) column(offset int) int {
+	if offset > len(bufState.lineByteSlice) {
+		offset = len(bufState.lineByteSlice)
+	}
+	return utf8.RuneCount(bufState.lineByteSlice[:offset]) + 1
+}
+
+func (bufState *BufferState) syntaxError(offset int, expected, found string) *SyntaxError {
+	return &SyntaxError{
+		Line:     string(bufState.lineByteSlice),
+		Offset:   offset,
+		Column:   bufState.column(offset),
+		Expected: expected,
+		Found:    found,
+	}
+}
+
+// charAt -- describes the character at offset for an error message.
+func (bufState *BufferState) charAt(offset int) string {
+	if offset >= len(bufState.lineByteSlice) {
+		return "end of line"
+	}
+	r, _ := utf8.DecodeRune(bufState.lineByteSlice[offset:])
+	return fmt.Sprintf("%q", r)
+}
+
+// ------------------------------------------- checkDigits -------------------------------------------
+
+// checkDigits -- returns a *SyntaxError if a hex, binary or octal token has no digits, a digit too big for its base, or too many digits for an int.
+// The prefix letter isn't in Str, so it's added back in to get the offsets right.
+func (bufState *BufferState) checkDigits(token TokenType) error {
+	var base, maxDigits int
+	var name, digit string
+	switch {
+	case token.HexFlag:
+		base, maxDigits, name, digit = 16, 16, "hex", "a hex digit"
+	case token.BinFlag:
+		base, maxDigits, name, digit = 2, 64, "binary", "a binary digit"
+	case token.OctFlag:
+		base, maxDigits, name, digit = 8, 22, "octal", "an octal digit"
+	default:
+		return nil
+	}
+	digitsStart := token.Offset + len(token.FullString) - len(token.Str) + 2 // after the sign, the 0 and the prefix letter.
+	if len(token.Str) < 2 {
+		return bufState.syntaxError(digitsStart, digit, bufState.charAt(digitsStart))
+	}
+	if base != 16 {
+		for i, dgtchar := range token.Str[1:] {
+			if int(dgtchar)-Dgt0 >= base {
+				return bufState.syntaxError(digitsStart+i, digit, fmt.Sprintf("%q", dgtchar))
+			}
+		}
+	}
+	if len(strings.TrimLeft(token.Str, "0")) > maxDigits {
+		return bufState.syntaxError(digitsStart, fmt.Sprintf("at most %d %s digits", maxDigits, name), fmt.Sprintf("%d", len(token.Str)-1))
+	}
+	return nil
+} // checkDigits
+
+// ------------------------------------------- GetTokenErr, TokenRealErr -------------------------------
+
+// GetTokenErr -- same as GetToken, but also returns a *SyntaxError when the token is malformed.  The token is still returned, so the caller can decide what to do.
+func (bufState *BufferState) GetTokenErr(UpperCase bool) (TokenType, bool, error) {
+	token, EOL := bufState.GetToken(UpperCase)
+	return token, EOL, bufState.tknErr
+} // GetTokenErr
+
+// TokenRealErr -- same as TokenReal, but returns a *SyntaxError instead of printing when the number is malformed.
+func (bufState *BufferState) TokenRealErr() (TokenType, bool, error) {
+	wantErr = true
+	token, EOL := bufState.TokenReal()
+	wantErr = false
+	return token, EOL, bufState.tknErr
+} // TokenRealErr
+
 //-------------------------------------------- GETTKNREAL ---------------------------------------
 // I am copying the working code from TKNRTNS here.  See the comments in tknrtnsa.adb for reason why.
 // Allows "0x" as hex prefix; no longer allows "H" as hex suffix.
@@ -753,11 +910,15 @@ func (bufState *BufferState) GETTKNREAL() (TOKEN TokenType, EOL bool) {
 	TOKEN.State = DGT
 	bufState.PREVPOSN = bufState.CURPOSN
 	HexFlag := false
+	start := -1
 
 ExitLoop:
 	for {
 		CHAR, EOL = bufState.GETCHR()
 		CHAR.Ch = CAP(CHAR.Ch)
+		if start < 0 && !EOL && CHAR.State != DELIM {
+			start = bufState.CURPOSN - 1
+		}
 		if EOL {
 			// If TKNSTATE is DELIM, then GETTKN was called when there were
 			// no more tokens on line.  Otherwise it means that we have fetched the last token on this line.
@@ -797,6 +958,7 @@ ExitLoop:
 
 	TOKEN.DelimCH = CHAR.Ch
 	TOKEN.DelimState = CHAR.State
+	bufState.setPosn(&TOKEN, start)
 	Len = len(tokenByteSlice)
 	TOKEN.Str = string(tokenByteSlice) // An initial assignment that can be changed below.
 	if tokenByteSlice[Len-1] == 'H' {
@@ -927,6 +1089,7 @@ func (bufState *BufferState) GetTokenEOL(UpperCase bool) (TOKEN TokenType, EOL b
 	bufState.PREVPOSN = bufState.CURPOSN // So this tkn can be ungotten as well
 	tokenByteSlice := make([]byte, 0, 200)
 	TOKEN = TokenType{}
+	bufState.setPosn(&TOKEN, bufState.CURPOSN)
 	for {
 		Char, EOL = bufState.GETCHR() // the Cap function is not here anymore.
 		if EOL {
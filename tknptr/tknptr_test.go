@@ -11,6 +11,7 @@ import (
    7 July 23 -- I'm going to try and code another set of table based testing functions
   20 July 23 -- Adjusted the test cases in the table because I changed the logic.  I want to keep '-' as an operator.  To enter neg exponent need '_'.
   24 July 23 -- Added test for hex input.
+  18 Oct  26 -- Added tests for binary and octal input, token positions and the syntax errors from TokenRealErr.
 */
 
 /*
@@ -33,7 +34,13 @@ var testRealStrings = []struct {
 	inputString string
 	outputToken TokenType
 }{
-	{"fix", TokenType{"FIX", "FIX", ALLELSE, '0', DELIM, 0, 0, false, false}},
+	{"fix", TokenType{
+		Str:        "FIX",
+		FullString: "FIX",
+		State:      ALLELSE,
+		DelimCH:    '0',
+		DelimState: DELIM,
+	}},
 	{"+", TokenType{
 		Str:        "+",
 		FullString: "+",
@@ -94,7 +101,15 @@ var testRealStrings = []struct {
 		Rsum:       0,
 		RealFlag:   false,
 	}},
-	{"7.6", TokenType{"7.6", "7.6", DGT, 0, DELIM, 7, 7.6, true, false}},
+	{"7.6", TokenType{
+		Str:        "7.6",
+		FullString: "7.6",
+		State:      DGT,
+		DelimState: DELIM,
+		Isum:       7,
+		Rsum:       7.6,
+		RealFlag:   true,
+	}},
 	{"-3.14159", TokenType{
 		Str:        "3.14159",
 		FullString: "-3.14159",
@@ -279,6 +294,28 @@ var testRealStrings = []struct {
 		RealFlag:   true, // this flag affects how Isum is calculated, which is fine.
 		HexFlag:    true,
 	}},
+	{"0b101", TokenType{
+		Str:        "0101",
+		FullString: "0101",
+		State:      DGT,
+		DelimCH:    0,
+		DelimState: DELIM,
+		Isum:       5,
+		Rsum:       5,
+		RealFlag:   false,
+		BinFlag:    true,
+	}},
+	{"0o17", TokenType{
+		Str:        "017",
+		FullString: "017",
+		State:      DGT,
+		DelimCH:    0,
+		DelimState: DELIM,
+		Isum:       15,
+		Rsum:       15,
+		RealFlag:   false,
+		OctFlag:    true,
+	}},
 }
 
 func FSAname(i int) string { // no param checking is done.  This code will panic if the int param is out of bounds, ie, not in the correct range of 0..3
@@ -314,3 +351,73 @@ func TestTokenReal(t *testing.T) { // test ALLELSE FIX keyword, OP of +, and the
 		}
 	}
 }
+
+func TestTokenPosition(t *testing.T) { // é is 2 bytes, so the column of x is 1 less than its offset + 1.
+	line := "fix  3.5 + 'ab c' é x -7"
+	want := []struct {
+		str            string
+		offset, column int
+	}{
+		{"FIX", 0, 1},
+		{"3.5", 5, 6},
+		{"+", 9, 10},
+		{"AB C", 11, 12},
+		{"X", 21, 21},
+		{"-7", 23, 23},
+	}
+	bs := New(line)
+	for _, w := range want {
+		token, EOL, err := bs.TokenRealErr()
+		if EOL || err != nil {
+			t.Fatalf(" %q: EOL = %t, err = %v before token %q", line, EOL, err, w.str)
+		}
+		if token.FullString != w.str || token.Offset != w.offset || token.Column != w.column {
+			t.Errorf(" token %q at offset %d column %d, wanted %q at offset %d column %d", token.FullString, token.Offset, token.Column, w.str, w.offset, w.column)
+		}
+	}
+	if _, EOL, _ := bs.TokenRealErr(); !EOL {
+		t.Errorf(" EOL should be true after the last token of %q", line)
+	}
+}
+
+var testSyntaxErrors = []struct {
+	inputString string
+	column      int
+	expected    string
+}{
+	{"0x", 3, "a hex digit"},
+	{"0x1ffffffffffffffff", 3, "at most 16 hex digits"},
+	{"0b102", 5, "a binary digit"},
+	{"-0o78", 5, "an octal digit"},
+	{"1.2.3", 1, "a real number"},
+	{"12 'abc", 8, "closing '"},
+}
+
+func TestTokenRealErr(t *testing.T) {
+	for _, tst := range testSyntaxErrors {
+		bs := New(tst.inputString)
+		var err error
+		for err == nil {
+			var EOL bool
+			_, EOL, err = bs.TokenRealErr()
+			if EOL {
+				break
+			}
+		}
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf(" %q: wanted a *SyntaxError, got %v", tst.inputString, err)
+			continue
+		}
+		if syntaxErr.Column != tst.column || syntaxErr.Expected != tst.expected {
+			t.Errorf(" %q: got %s, wanted column %d: expected %s", tst.inputString, syntaxErr, tst.column, tst.expected)
+		}
+	}
+
+	for _, good := range []string{"0x1F", "0b101", "0o17", "1e_3", "-.5", "'a b'"} {
+		bs := New(good)
+		if _, _, err := bs.TokenRealErr(); err != nil {
+			t.Errorf(" %q should not be an error, got %s", good, err)
+		}
+	}
+}
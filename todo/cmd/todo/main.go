@@ -9,7 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
-	"src/todo"
+	"src/todocore"
 	"strings"
 	"time"
 )
@@ -29,15 +29,27 @@ REVISION HISTORY
                which only returns the string for one task.  And added an about option.
  9 Jul 22 -- Help message now includes a call to runtime.Version().
  1 Apr 23 -- StaticCheck found a few issues.
+18 Oct 26 -- Now uses todocore, which has priorities, due dates, tags, projects, notes and recurrence.  Added the subcommands in subcommands.go to set them,
+               and to list w/ a filter and a sort, and to search.  A subcommand is the first word on the command line, so a task that starts w/ one of those
+               words has to be added w/ the -add flag.  The old flags work as before.
+18 Oct 26 -- todo.json and todo.gob now hold named lists, selected by the -l flag or the TODO_LIST environment variable.  The default is the todo list,
+               which is what an old file of one list is read into.  Added the lists, move, import and export subcommands.  Import and export do todo.txt,
+               CSV and json, by the file extension or the -format flag.  The saves are atomic, so two terminals can't corrupt the files.
+18 Oct 26 -- A task that starts w/ a subcommand word, as in todo move couch, is added again, as it was before the subcommands.  It's only a subcommand if
+               the words after it fit, like an item number.
+18 Oct 26 -- list and search fit only their own arguments too, so todo list groceries and todo search for the keys are tasks.  list only takes flags, and
+               has -search for what the words after it used to do.  search takes one word, and a phrase has to be in quotes.
 */
 
-const lastModified = "1 Apr 2023"
+const lastModified = "18 Oct 2026"
 
 var todoFilename = "todo.json" // now a var instead of a const so can use environment variable if set.
 var todoFileBin = "todo.gob"   // now a var instead of a const so can use environment variable if set.
 var prefix string
 var fileExists bool
 var fullFilenameJson, fullFilenameBin string
+var store = todocore.NewStore() // the todo.json and todo.gob files hold all the named lists.
+var listName = todocore.DefaultListName
 
 var verboseFlag = flag.Bool("v", false, "Set verbose mode.")
 
@@ -47,6 +59,13 @@ var complete = flag.Int("complete", 0, "Item to be completed.") // here, 0 means
 var listFlag = flag.Bool("list", false, "List all tasks to the display.")
 var aboutFlag = flag.Bool("about", false, "Show the about string from the todo package.")
 
+func init() {
+	if envList, ok := os.LookupEnv("TODO_LIST"); ok && envList != "" {
+		listName = envList
+	}
+	flag.StringVar(&listName, "l", listName, "Name of the list to use, like work or home.  TODO_LIST environment variable sets the default.")
+}
+
 type structForListing struct {
 	i int
 	s string
@@ -59,6 +78,7 @@ func main() {
 			todoFilename, os.Getenv("TODO_PREFIX"), os.Getenv("TODO_FILENAME"))
 		fmt.Fprintf(flag.CommandLine.Output(), " Usage information:\n")
 		flag.PrintDefaults()
+		fmt.Fprint(flag.CommandLine.Output(), subcommandUsage)
 	}
 	flag.Parse()
 	if *verboseFlag {
@@ -105,11 +125,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, " %s got error from os.Stat of %v.\n", fullFilenameBin, err)
 	}
 
-	l := todo.ListType{}
-	err = l.LoadJSON(fullFilenameJson) // if file doesn't exist, this doesn't return an error.
+	err = store.LoadJSON(fullFilenameJson) // if file doesn't exist, this doesn't return an error.  An old file of one list becomes the todo list.
 	if err != nil {
 		fmt.Fprintf(os.Stderr, " Error returned while reading %s is %v\n", fullFilenameJson, err)
-		er := l.LoadBinary(fullFilenameBin)
+		er := store.LoadBinary(fullFilenameBin)
 		if er != nil {
 			fmt.Fprintf(os.Stderr, " Error returned while reading %s is %v\n", fullFilenameBin, er)
 			fmt.Print(" Should I exit? ")
@@ -122,13 +141,31 @@ func main() {
 			fileExists = false
 		}
 	}
+	l := store.Get(listName)
 
 	if *verboseFlag {
-		for i, t := range l {
+		for i, t := range *l {
 			fmt.Printf(" %d: %s, %t, %s, %s\n", i+1, t.Task, t.Done, t.CreatedAt.Format(time.RFC822), t.CompletedAt.Format(time.RFC822))
 		}
 		fmt.Println()
 	}
+	if flag.NArg() > 0 && !*add {
+		if subcmd, ok := subcommands[strings.ToLower(flag.Arg(0))]; ok {
+			err = subcmd(l, flag.Args()[1:])
+			if err == nil {
+				return
+			}
+			if !isArgsError(err) {
+				fmt.Fprintf(os.Stderr, " %s: %v\n", flag.Arg(0), err)
+				os.Exit(1)
+			}
+			if *verboseFlag {
+				fmt.Printf(" %s: %v, so it's added as a task.\n", flag.Arg(0), err)
+			}
+			// falls thru to the default case, which adds the words as a task.
+		}
+	}
+
 	switch {
 	case *listFlag:
 		/* Replaced by the stringer interface
@@ -150,7 +187,7 @@ func main() {
 		// followed the book that defined it as a pointer receiver.  So I defined it in todo.go as a value receiver, and it started to work.
 
 		if fileExists {
-			itemStrings := getSortedSliceOfTasks(l)
+			itemStrings := getSortedSliceOfTasks(*l)
 			for _, itemString := range itemStrings {
 				fmt.Print(itemString)
 			}
@@ -174,13 +211,8 @@ func main() {
 			fmt.Fprintf(os.Stderr, " Item number %d cannot be completed because %v\n", *complete, err)
 		}
 
-		err = l.SaveJSON(fullFilenameJson)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " List could not be saved in json because %v\n", err)
-		}
-		err = l.SaveBinary(fullFilenameBin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " List could not be saved in binary format because %v\n", err)
+		if err = saveStore(); err != nil {
+			fmt.Fprintf(os.Stderr, " %v\n", err)
 		}
 	case *add:
 		task, err := getTask(os.Stdin, flag.Args()...)
@@ -189,13 +221,8 @@ func main() {
 			os.Exit(1)
 		}
 		l.Add(task)
-		err = l.SaveJSON(fullFilenameJson)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " List could not be saved in JSON because %v \n", err)
-		}
-		err = l.SaveBinary(fullFilenameBin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, " List could not be saved in binary format because %v \n", err)
+		if err = saveStore(); err != nil {
+			fmt.Fprintf(os.Stderr, " %v\n", err)
 		}
 	case *aboutFlag:
 		fmt.Printf("todo library last modified %s, main last modified %s\n", l.About(), lastModified)
@@ -204,17 +231,12 @@ func main() {
 		if flag.NArg() > 0 {
 			tsk := strings.Join(flag.Args(), " ")
 			l.Add(tsk)
-			err = l.SaveJSON(fullFilenameJson)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, " List could not be saved in JSON because %v \n", err)
-			}
-			err = l.SaveBinary(fullFilenameBin)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, " List could not be saved in binary format because %v \n", err)
+			if err = saveStore(); err != nil {
+				fmt.Fprintf(os.Stderr, " %v\n", err)
 			}
 		} else {
 			if fileExists {
-				itemStrings := getSortedSliceOfTasks(l)
+				itemStrings := getSortedSliceOfTasks(*l)
 				for _, itemString := range itemStrings {
 					fmt.Print(itemString)
 				}
@@ -247,7 +269,7 @@ func getTask(r io.Reader, args ...string) (string, error) { // decides where to
 	return scnr.Text(), nil
 }
 
-func getSortedSliceOfTasks(l todo.ListType) []string {
+func getSortedSliceOfTasks(l todocore.ListType) []string {
 
 	completedToBeSorted := make([]structForListing, 0, len(l))
 	notCompletedToBeSorted := make([]structForListing, 0, len(l))
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -108,3 +109,46 @@ func TestTodoCLI(t *testing.T) {
 	}
 	t.Run("ListTasks", listTasksTestFunc)
 }
+
+func TestSubcommandWordTask(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmdPath := filepath.Join(dir, execName)
+
+	// move needs an item number, so move couch is a task, as it was before there were subcommands.
+	if out, err := exec.Command(cmdPath, "move", "couch").CombinedOutput(); err != nil {
+		t.Fatalf(" todo move couch should add a task, got %v, %s instead.\n", err, out)
+	}
+	out, err := exec.Command(cmdPath, "export", "-").CombinedOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "move couch") {
+		t.Errorf(" The list should have the task move couch, got %q instead.\n", out)
+	}
+
+	// list only takes flags, and search only one word, so these are tasks too.
+	for _, words := range [][]string{{"list", "groceries"}, {"ls", "the", "files"}, {"search", "for", "the", "keys"}} {
+		if out, err := exec.Command(cmdPath, words...).CombinedOutput(); err != nil {
+			t.Fatalf(" todo %s should add a task, got %v, %s instead.\n", strings.Join(words, " "), err, out)
+		}
+	}
+	out, err = exec.Command(cmdPath, "export", "-").CombinedOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range []string{"list groceries", "ls the files", "search for the keys"} {
+		if !strings.Contains(string(out), task) {
+			t.Errorf(" The list should have the task %s, got %q instead.\n", task, out)
+		}
+	}
+	if out, err = exec.Command(cmdPath, "search", "the keys").CombinedOutput(); err != nil || !strings.Contains(string(out), "search for the keys") {
+		t.Errorf(" todo search \"the keys\" should find the task, got %v, %s instead.\n", err, out)
+	}
+
+	if out, err := exec.Command(cmdPath, "del", "999").CombinedOutput(); err == nil {
+		t.Errorf(" todo del 999 should be an error, not a task, got %s instead.\n", out)
+	}
+}
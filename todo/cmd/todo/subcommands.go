@@ -0,0 +1,429 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"src/todocore"
+	"strconv"
+	"strings"
+)
+
+// The subcommands are the first word on the command line.  If the words after it don't fit the subcommand, as in todo move couch, the whole line is
+// added as a task, the same as before there were subcommands.  The -add flag or the add subcommand always adds.
+var subcommands = map[string]func(l *todocore.ListType, args []string) error{
+	"add":     addCmd,
+	"list":    listCmd,
+	"ls":      listCmd,
+	"search":  searchCmd,
+	"done":    doneCmd,
+	"del":     delCmd,
+	"pri":     priCmd,
+	"due":     dueCmd,
+	"tag":     tagCmd,
+	"project": projectCmd,
+	"note":    noteCmd,
+	"recur":   recurCmd,
+	"lists":   listsCmd,
+	"move":    moveCmd,
+	"import":  importCmd,
+	"export":  exportCmd,
+}
+
+const subcommandUsage = `
+ Subcommands, as the first word on the command line.  If the words after it don't fit, as in todo move couch, the line is added as a task:
+   add [-pri A] [-due date] [-tag t,u] [-project p] [-recur weekly] task words
+   list [-all] [-tag t] [-project p] [-overdue] [-pri B] [-search s] [-sort due|pri|num]
+   search word|"words"    -- case insensitive, in the tasks and the notes, done or not.  More than one word needs quotes.
+   done N ...             -- completes the items.  A recurring item adds the next one.
+   del N                  -- deletes the item.
+   pri N A|B|..|high|none -- sets or removes the priority.
+   due N date|+3d|none    -- sets or removes the due date.  Dates are in any of the formats of timlibg.ParseDate.
+   tag N [+]t [-u] ...    -- adds tag t and removes tag u.
+   project N name|none
+   note N words           -- replaces the notes.  No words removes them.
+   recur N daily|weekly|monthly|yearly|2w|none
+   lists                  -- shows the names of the lists, and how many items are in each.
+   move N list            -- moves the item to the end of the other list, which is made if it's not there.
+   import [-format txt|csv|json] file    -- adds the items of a todo.txt, CSV or json file to the list.
+   export [-format txt|csv|json] file|-  -- writes the list to a file, or to stdout for -, which is todo.txt by default.
+ The format is from the file extension if there's no -format flag.  The list is set by -l before the subcommand, as in todo -l work list.
+`
+
+// argsError is what a subcommand returns when its arguments don't fit it.  It's returned before anything is changed, so main can add the line as a task instead.
+type argsError struct {
+	error
+}
+
+func argsErrorf(format string, a ...any) error {
+	return argsError{fmt.Errorf(format, a...)}
+}
+
+// isArgsError is true if the subcommand didn't take its arguments, so the command line is a task.
+func isArgsError(err error) bool {
+	var ae argsError
+	return errors.As(err, &ae)
+}
+
+// saveStore saves all the lists, not only the one that was changed.
+func saveStore() error {
+	if err := store.SaveJSON(fullFilenameJson); err != nil {
+		return fmt.Errorf("list could not be saved in JSON because %w", err)
+	}
+	if err := store.SaveBinary(fullFilenameBin); err != nil {
+		return fmt.Errorf("list could not be saved in binary format because %w", err)
+	}
+	return nil
+}
+
+func itemNum(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, argsErrorf("%q is not an item number", s)
+	}
+	return n, nil
+}
+
+// numAndRest is for the subcommands that take an item number and then a value.
+func numAndRest(name string, args []string) (int, string, error) {
+	if len(args) < 1 {
+		return 0, "", argsErrorf("%s needs an item number", name)
+	}
+	n, err := itemNum(args[0])
+	return n, strings.Join(args[1:], " "), err
+}
+
+func printItems(l todocore.ListType, nums []int) {
+	for _, n := range nums {
+		fmt.Print(l.GetString(n - 1))
+	}
+	fmt.Printf("\n %d of %d items.\n", len(nums), len(l))
+}
+
+func addCmd(l *todocore.ListType, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	pri := fs.String("pri", "", "priority, A thru Z or high, medium or low.")
+	due := fs.String("due", "", "due date.")
+	tags := fs.String("tag", "", "tags, separated by commas.")
+	project := fs.String("project", "", "project.")
+	recur := fs.String("recur", "", "recurrence, as in weekly or 2w.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	task, err := getTask(os.Stdin, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	p, err := todocore.ParsePriority(*pri)
+	if err != nil {
+		return err
+	}
+	d, err := todocore.ParseDue(*due)
+	if err != nil {
+		return err
+	}
+	l.Add(task)
+	n := len(*l)
+	if err = l.SetPriority(n, p); err == nil {
+		err = l.SetDue(n, d)
+	}
+	if err == nil && *tags != "" {
+		err = l.AddTags(n, strings.Split(*tags, ",")...)
+	}
+	if err == nil {
+		err = l.SetProject(n, *project)
+	}
+	if err == nil {
+		err = l.SetRecur(n, *recur)
+	}
+	if err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func listCmd(l *todocore.ListType, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	var f todocore.FilterType
+	fs.BoolVar(&f.All, "all", false, "include the completed items.")
+	fs.StringVar(&f.Tag, "tag", "", "only the items w/ this tag.")
+	fs.StringVar(&f.Project, "project", "", "only the items of this project.")
+	fs.BoolVar(&f.Overdue, "overdue", false, "only the overdue items.")
+	pri := fs.String("pri", "", "only the items at least this important, so B is A and B.")
+	fs.StringVar(&f.Search, "search", "", "only the items w/ this in the task or the notes.")
+	sortBy := fs.String("sort", "num", "sort by due, pri or num.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 { // so todo list groceries is a task.
+		return argsErrorf("list only takes flags, not %q", strings.Join(fs.Args(), " "))
+	}
+	var err error
+	if f.Priority, err = todocore.ParsePriority(*pri); err != nil {
+		return err
+	}
+	if !fileExists {
+		return fmt.Errorf("cannot list todo files (%s or %s) as they cannot be found", fullFilenameJson, fullFilenameBin)
+	}
+	nums := l.Filter(f)
+	switch strings.ToLower(*sortBy) {
+	case "due":
+		l.SortByDue(nums)
+	case "pri", "priority":
+		l.SortByPriority(nums)
+	case "num", "":
+	default:
+		return fmt.Errorf("sort must be due, pri or num, not %q", *sortBy)
+	}
+	printItems(*l, nums)
+	return nil
+}
+
+func searchCmd(l *todocore.ListType, args []string) error {
+	if len(args) != 1 { // so todo search for the keys is a task.  A phrase is quoted, as in todo search "the keys".
+		return argsErrorf("search takes one word, or words in quotes")
+	}
+	printItems(*l, l.Search(args[0]))
+	return nil
+}
+
+func doneCmd(l *todocore.ListType, args []string) error {
+	if len(args) == 0 {
+		return argsErrorf("done needs an item number")
+	}
+	nums := make([]int, 0, len(args))
+	for _, arg := range args { // all of them are checked before any are completed, so done 3 loads isn't half done.
+		n, err := itemNum(arg)
+		if err != nil {
+			return err
+		}
+		nums = append(nums, n)
+	}
+	for _, n := range nums {
+		if err := l.Complete(n); err != nil {
+			return err
+		}
+	}
+	return saveStore()
+}
+
+func delCmd(l *todocore.ListType, args []string) error {
+	if len(args) != 1 {
+		return argsErrorf("del takes one item number, as the numbers after it change")
+	}
+	n, err := itemNum(args[0])
+	if err != nil {
+		return err
+	}
+	if err = l.Delete(n); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func priCmd(l *todocore.ListType, args []string) error {
+	n, s, err := numAndRest("pri", args)
+	if err != nil {
+		return err
+	}
+	p, err := todocore.ParsePriority(s)
+	if err != nil {
+		return err
+	}
+	if err = l.SetPriority(n, p); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func dueCmd(l *todocore.ListType, args []string) error {
+	n, s, err := numAndRest("due", args)
+	if err != nil {
+		return err
+	}
+	d, err := todocore.ParseDue(s)
+	if err != nil {
+		return err
+	}
+	if err = l.SetDue(n, d); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func tagCmd(l *todocore.ListType, args []string) error {
+	n, _, err := numAndRest("tag", args)
+	if err != nil {
+		return err
+	}
+	for _, tag := range args[1:] {
+		if strings.HasPrefix(tag, "-") {
+			err = l.RemoveTags(n, tag[1:])
+		} else {
+			err = l.AddTags(n, strings.TrimPrefix(tag, "+"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return saveStore()
+}
+
+func projectCmd(l *todocore.ListType, args []string) error {
+	n, s, err := numAndRest("project", args)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(s, "none") {
+		s = ""
+	}
+	if err = l.SetProject(n, s); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func noteCmd(l *todocore.ListType, args []string) error {
+	n, s, err := numAndRest("note", args)
+	if err != nil {
+		return err
+	}
+	if err = l.SetNotes(n, s); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func recurCmd(l *todocore.ListType, args []string) error {
+	n, s, err := numAndRest("recur", args)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(s, "none") {
+		s = ""
+	}
+	if err = l.SetRecur(n, s); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+func listsCmd(l *todocore.ListType, args []string) error {
+	if len(args) > 0 {
+		return argsErrorf("lists doesn't take any arguments")
+	}
+	for _, name := range store.Names() {
+		lst := *store.Lists[name]
+		current := "  "
+		if name == listName {
+			current = "* "
+		}
+		fmt.Printf(" %s%-20s %3d items, %3d not done\n", current, name, len(lst), len(lst.Filter(todocore.FilterType{})))
+	}
+	return nil
+}
+
+func moveCmd(l *todocore.ListType, args []string) error {
+	if len(args) != 2 {
+		return argsErrorf("move takes an item number and the list to move it to")
+	}
+	n, err := itemNum(args[0])
+	if err != nil {
+		return err
+	}
+	if err = store.Move(listName, n, args[1]); err != nil {
+		return err
+	}
+	return saveStore()
+}
+
+// fileFormat is the -format flag of import and export, or else the file extension.  Stdout is todo.txt.
+func fileFormat(format, filename string) (string, error) {
+	if format == "" && filename == "-" {
+		format = "txt"
+	}
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+	switch format {
+	case "txt", "todo.txt", "todotxt":
+		return "txt", nil
+	case "csv", "json":
+		return format, nil
+	}
+	return "", fmt.Errorf("format of %q must be txt, csv or json, not %q", filename, format)
+}
+
+func importCmd(l *todocore.ListType, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "txt, csv or json.  Default is from the file extension.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return argsErrorf("import takes one file name")
+	}
+	filename := fs.Arg(0)
+	f, err := fileFormat(*format, filename)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(filename); err != nil {
+		return err // the Load methods don't return an error for a file that's not there.
+	}
+	var imported todocore.ListType
+	switch f {
+	case "txt":
+		err = imported.LoadTodoTxt(filename)
+	case "csv":
+		err = imported.LoadCSV(filename)
+	default:
+		err = imported.LoadJSON(filename)
+	}
+	if err != nil {
+		return err
+	}
+	*l = append(*l, imported...)
+	fmt.Printf(" Imported %d items from %s into the %s list.\n", len(imported), filename, listName)
+	return saveStore()
+}
+
+func exportCmd(l *todocore.ListType, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "txt, csv or json.  Default is from the file extension.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return argsErrorf("export takes one file name, or - for stdout")
+	}
+	filename := fs.Arg(0)
+	f, err := fileFormat(*format, filename)
+	if err != nil {
+		return err
+	}
+	if filename != "-" {
+		switch f {
+		case "txt":
+			return l.SaveTodoTxt(filename)
+		case "csv":
+			return l.SaveCSV(filename)
+		}
+		return l.SaveJSON(filename)
+	}
+
+	var w io.Writer = os.Stdout
+	switch f {
+	case "txt":
+		return l.WriteTodoTxt(w)
+	case "csv":
+		return l.WriteCSV(w)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(*l)
+}
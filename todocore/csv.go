@@ -0,0 +1,178 @@
+package todocore
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"src/timlibg"
+	"strings"
+	"time"
+)
+
+/*
+  The CSV format has a header line, and WriteCSV writes the columns in csvColumns order.  ReadCSV goes by the header, so a spreadsheet can have the
+  columns in any order, leave out any but Task, and have other columns, which are skipped.  The header is not case sensitive, and csvAliases has the
+  other names a column goes by.  Tags are separated by spaces.  The dates are read by ParseDue and timlibg.ParseDate, so Excel's date numbers work too.
+*/
+
+var csvColumns = []string{"Task", "Done", "Priority", "Due", "Project", "Tags", "Notes", "Recur", "Created", "Completed"}
+
+var csvAliases = map[string]string{
+	"description": "task", "title": "task", "name": "task",
+	"status": "done", "pri": "priority", "due date": "due", "contexts": "tags", "context": "tags", "note": "notes",
+	"recurrence": "recur", "created at": "created", "createdat": "created", "completed at": "completed", "completedat": "completed",
+}
+
+// WriteCSV writes the list as CSV w/ a header line.
+func (l ListType) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	for _, t := range l {
+		done := ""
+		if t.Done {
+			done = "x"
+		}
+		due := ""
+		if !t.Due.IsZero() {
+			due = t.Due.Format(todoTxtDate)
+		}
+		record := []string{t.Task, done, PriorityString(t.Priority), due, t.Project, strings.Join(t.Tags, " "), t.Notes, t.Recur,
+			formatTime(t.CreatedAt), formatTime(t.CompletedAt)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads CSV w/ a header line that has at least a Task column.  Rows w/ a blank task are skipped.
+func ReadCSV(r io.Reader) (ListType, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // spreadsheets don't always write the trailing empty columns.
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the header: %w", err)
+	}
+	col := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) // Excel starts a UTF-8 CSV w/ a BOM.
+		if alias, ok := csvAliases[name]; ok {
+			name = alias
+		}
+		if _, dup := col[name]; !dup {
+			col[name] = i
+		}
+	}
+	if _, ok := col["task"]; !ok {
+		return nil, fmt.Errorf("the header %v has no Task column", header)
+	}
+
+	var l ListType
+	lineNum := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		lineNum++
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		it, err := csvItem(field)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if it.Task != "" {
+			l = append(l, it)
+		}
+	}
+	return l, nil
+}
+
+func csvItem(field func(name string) string) (item, error) {
+	var it item
+	var err error
+	it.Task = field("task")
+	switch strings.ToLower(field("done")) {
+	case "x", "true", "yes", "y", "1", "done", "completed":
+		it.Done = true
+	}
+	if it.Priority, err = ParsePriority(field("priority")); err != nil {
+		return it, err
+	}
+	if it.Due, err = ParseDue(field("due")); err != nil {
+		return it, err
+	}
+	it.Project = strings.TrimPrefix(field("project"), "+")
+	for _, tag := range strings.Fields(strings.ReplaceAll(field("tags"), ",", " ")) {
+		it.Tags = append(it.Tags, normalizeTag(strings.TrimPrefix(tag, "@")))
+	}
+	it.Notes = field("notes")
+	if recur := strings.ToLower(field("recur")); recur != "" {
+		if _, _, err = ParseRecur(recur); err != nil {
+			return it, err
+		}
+		it.Recur = recur
+	}
+	if it.CreatedAt, err = csvTime(field("created")); err != nil {
+		return it, err
+	}
+	if it.CompletedAt, err = csvTime(field("completed")); err != nil {
+		return it, err
+	}
+	if !it.CompletedAt.IsZero() {
+		it.Done = true
+	}
+	return it, nil
+}
+
+func csvTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return timlibg.ParseDate(s)
+}
+
+// LoadCSV replaces the list w/ the items of a CSV file.  Like LoadJSON, a file that's not there is not an error.
+func (l *ListType) LoadCSV(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	lst, err := ReadCSV(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	*l = lst
+	return nil
+}
+
+// SaveCSV writes the list to a CSV file atomically.
+func (l *ListType) SaveCSV(filename string) error {
+	return writeFileAtomic(filename, l.WriteCSV)
+}
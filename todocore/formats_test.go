@@ -0,0 +1,145 @@
+package todocore_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"src/todocore"
+	"strings"
+	"testing"
+	"time"
+)
+
+const todoTxt = `(A) 2026-10-01 call the landlord +apartment +rent @phone due:2026-10-20 rec:+1m
+x 2026-10-18 2026-10-01 pay the rent +apartment pri:B note:late+fee%3F
+
+2026-09-30 read a book about:go
+`
+
+func TestTodoTxt(t *testing.T) {
+	l, err := todocore.ReadTodoTxt(strings.NewReader(todoTxt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 3 {
+		t.Fatalf(" There should be 3 items, got %d instead.\n", len(l))
+	}
+	first := l[0]
+	if first.Task != "call the landlord +rent" || first.Priority != 1 || first.Project != "apartment" || !slices.Equal(first.Tags, []string{"phone"}) ||
+		first.Recur != "1m" || !first.Due.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)) || first.CreatedAt.Day() != 1 {
+		t.Errorf(" The first line was not read right, got %+v instead.\n", first)
+	}
+	second := l[1]
+	if !second.Done || second.CompletedAt.Day() != 18 || second.CreatedAt.Day() != 1 || second.Priority != 2 || second.Notes != "late fee?" {
+		t.Errorf(" The second line was not read right, got %+v instead.\n", second)
+	}
+	if l[2].Task != "read a book about:go" || l[2].Priority != 0 {
+		t.Errorf(" The third line was not read right, got %+v instead.\n", l[2])
+	}
+
+	var buf bytes.Buffer
+	if err = l.WriteTodoTxt(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `(A) 2026-10-01 +apartment call the landlord +rent @phone due:2026-10-20 rec:1m
+x 2026-10-18 2026-10-01 +apartment pay the rent pri:B note:late+fee%3F
+2026-09-30 read a book about:go
+`
+	if buf.String() != want {
+		t.Errorf(" WriteTodoTxt: expected\n%s got\n%s instead.\n", want, buf.String())
+	}
+
+	if _, err = todocore.ReadTodoTxt(strings.NewReader("(A) 2026-10-01\n")); err == nil {
+		t.Errorf(" A line w/o a task should be an error.\n")
+	}
+	if _, err = todocore.ReadTodoTxt(strings.NewReader("mow the lawn due:someday\n")); err == nil {
+		t.Errorf(" A due date that can't be read should be an error.\n")
+	}
+
+	name := filepath.Join(t.TempDir(), "todo.txt")
+	if err = l.SaveTodoTxt(name); err != nil {
+		t.Fatal(err)
+	}
+	var l2 todocore.ListType
+	if err = l2.LoadTodoTxt(name); err != nil || len(l2) != 3 || l2[1].Notes != "late fee?" {
+		t.Errorf(" LoadTodoTxt should read back what SaveTodoTxt wrote, got %v, %v instead.\n", l2, err)
+	}
+	if len(l2) > 0 && (l2[0].Project != "apartment" || l2[0].Task != "call the landlord +rent") {
+		t.Errorf(" A +word in the task should stay in the task, and the Project should be apartment, got %+v instead.\n", l2[0])
+	}
+}
+
+func TestCSV(t *testing.T) {
+	spreadsheet := "\ufeffDescription,Due Date,Pri,Status,Contexts,Extra\n" +
+		"file the taxes,4/15/2027,high,done,\"@home, @money\",ignored\n" +
+		"buy milk,46000,,,\n" +
+		",,,,\n"
+	l, err := todocore.ReadCSV(strings.NewReader(spreadsheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 2 {
+		t.Fatalf(" There should be 2 items, as the blank task is skipped, got %d instead.\n", len(l))
+	}
+	if l[0].Task != "file the taxes" || !l[0].Done || l[0].Priority != 1 || !slices.Equal(l[0].Tags, []string{"home", "money"}) ||
+		!l[0].Due.Equal(time.Date(2027, 4, 15, 0, 0, 0, 0, time.Local)) {
+		t.Errorf(" The first row was not read right, got %+v instead.\n", l[0])
+	}
+	if l[1].Done || !l[1].Due.Equal(time.Date(2025, 12, 9, 0, 0, 0, 0, time.Local)) {
+		t.Errorf(" The second row should be due on Excel day 46000, which is Dec 9, 2025, got %+v instead.\n", l[1])
+	}
+
+	l.Add("write the report")
+	_ = l.SetNotes(3, "has a \"quote\", and a comma")
+	_ = l.SetRecur(3, "2w")
+	var buf bytes.Buffer
+	if err = l.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	l2, err := todocore.ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l2) != 3 || l2[2].Notes != l[2].Notes || l2[2].Recur != "2w" || !l2[2].CreatedAt.Equal(l[2].CreatedAt.Truncate(time.Second)) {
+		t.Errorf(" ReadCSV should read back what WriteCSV wrote, got %+v instead.\n", l2)
+	}
+
+	if _, err = todocore.ReadCSV(strings.NewReader("Due,Priority\n1/1/2027,A\n")); err == nil {
+		t.Errorf(" CSV w/o a Task column should be an error.\n")
+	}
+}
+
+func TestPriorityRoundTrip(t *testing.T) {
+	var l todocore.ListType
+	for i, p := range []int{8, 12, 13} { // H, L and M, which ParsePriority used to take as high, low and medium.
+		l.Add("task " + todocore.PriorityString(p))
+		_ = l.SetPriority(i+1, p)
+	}
+	_ = l.Complete(2) // a done task has its priority in pri:L
+	want := []int{8, 12, 13}
+
+	var buf bytes.Buffer
+	if err := l.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	fromCSV, err := todocore.ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = l.WriteTodoTxt(&buf); err != nil {
+		t.Fatal(err)
+	}
+	fromTodoTxt, err := todocore.ReadTodoTxt(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range want {
+		if len(fromCSV) != len(want) || fromCSV[i].Priority != p {
+			t.Errorf(" CSV item %d should read back as priority %s, got %+v instead.\n", i+1, todocore.PriorityString(p), fromCSV)
+		}
+		if len(fromTodoTxt) != len(want) || fromTodoTxt[i].Priority != p {
+			t.Errorf(" todo.txt item %d should read back as priority %s, got %+v instead.\n", i+1, todocore.PriorityString(p), fromTodoTxt)
+		}
+	}
+}
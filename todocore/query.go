@@ -0,0 +1,216 @@
+package todocore
+
+import (
+	"fmt"
+	"slices"
+	"src/timlibg"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterType selects items for Filter.  The zero value selects every item that's not done.
+type FilterType struct {
+	Tag      string
+	Project  string
+	Overdue  bool
+	Priority int    // only the items at least this important, so 2 is A and B.  0 doesn't filter on priority.
+	Search   string // case insensitive, in the task and the notes.
+	All      bool   // include the completed items.
+}
+
+// Filter returns the 1 origin numbers of the items that f selects, in list order.
+func (l ListType) Filter(f FilterType) []int {
+	tag := normalizeTag(f.Tag)
+	project := strings.ToLower(strings.TrimPrefix(f.Project, "+"))
+	search := strings.ToLower(f.Search)
+	now := time.Now()
+	var nums []int
+	for i, t := range l {
+		switch {
+		case t.Done && !f.All:
+			continue
+		case tag != "" && !slices.Contains(t.Tags, tag):
+			continue
+		case project != "" && strings.ToLower(t.Project) != project:
+			continue
+		case f.Overdue && !t.IsOverdue(now):
+			continue
+		case f.Priority > 0 && (t.Priority == 0 || t.Priority > f.Priority):
+			continue
+		case search != "" && !strings.Contains(strings.ToLower(t.Task), search) && !strings.Contains(strings.ToLower(t.Notes), search):
+			continue
+		}
+		nums = append(nums, i+1)
+	}
+	return nums
+}
+
+// Search returns the 1 origin numbers of all the items, done or not, w/ text in the task or the notes.
+func (l ListType) Search(text string) []int {
+	return l.Filter(FilterType{Search: text, All: true})
+}
+
+// SortByDue sorts the item numbers by due date, w/ the items that have no due date last.  Ties go by priority, then by number.
+func (l ListType) SortByDue(nums []int) {
+	slices.SortStableFunc(nums, func(a, b int) int {
+		if c := compareDue(l[a-1], l[b-1]); c != 0 {
+			return c
+		}
+		if c := comparePriority(l[a-1], l[b-1]); c != 0 {
+			return c
+		}
+		return a - b
+	})
+}
+
+// SortByPriority sorts the item numbers by priority, w/ the items that have no priority last.  Ties go by due date, then by number.
+func (l ListType) SortByPriority(nums []int) {
+	slices.SortStableFunc(nums, func(a, b int) int {
+		if c := comparePriority(l[a-1], l[b-1]); c != 0 {
+			return c
+		}
+		if c := compareDue(l[a-1], l[b-1]); c != 0 {
+			return c
+		}
+		return a - b
+	})
+}
+
+func compareDue(a, b item) int {
+	switch {
+	case a.Due.IsZero() && b.Due.IsZero():
+		return 0
+	case a.Due.IsZero():
+		return 1
+	case b.Due.IsZero():
+		return -1
+	}
+	return a.Due.Compare(b.Due)
+}
+
+func comparePriority(a, b item) int {
+	pa, pb := a.Priority, b.Priority
+	if pa == 0 {
+		pa = 27
+	}
+	if pb == 0 {
+		pb = 27
+	}
+	return pa - pb
+}
+
+// IsOverdue -- not done, and due before the day of now.
+func (t item) IsOverdue(now time.Time) bool {
+	return !t.Done && !t.Due.IsZero() && t.Due.Before(dateOf(now))
+}
+
+// ---------------------------------------------------- Parsing -----------------------------------------------------
+
+// ParsePriority takes a letter A thru Z, a number 1 thru 26, or high, medium or low, which are A, B and C.  A blank or none is 0, which is no priority.
+// There are no 1 letter short forms of high, medium and low, as H, M and L are priorities too, and PriorityString writes them that way.
+func ParsePriority(s string) (int, error) {
+	s = strings.ToLower(strings.Trim(strings.TrimSpace(s), "()"))
+	switch s {
+	case "", "none", "0":
+		return 0, nil
+	case "high", "hi":
+		return 1, nil
+	case "medium", "med":
+		return 2, nil
+	case "low", "lo":
+		return 3, nil
+	}
+	if len(s) == 1 && s[0] >= 'a' && s[0] <= 'z' {
+		return int(s[0]-'a') + 1, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= 26 {
+		return n, nil
+	}
+	return 0, fmt.Errorf("priority %q is not A thru Z, 1 thru 26, or high, medium or low", s)
+}
+
+// PriorityString is the letter of a priority, or blank for none.
+func PriorityString(p int) string {
+	if p < 1 || p > 26 {
+		return ""
+	}
+	return string(rune('A' + p - 1))
+}
+
+// ParseDue takes today, tomorrow, yesterday, a + and a recurrence like +3d or +2w from today, or a date in any of the formats of timlibg.ParseDate.
+// A blank or none is the zero time, which is no due date.  The time of day is dropped.
+func ParseDue(s string) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "none":
+		return time.Time{}, nil
+	case "today":
+		return today(), nil
+	case "tomorrow":
+		return today().AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today().AddDate(0, 0, -1), nil
+	}
+	if strings.HasPrefix(s, "+") {
+		return nextDue(today(), s[1:])
+	}
+	t, err := timlibg.ParseDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return dateOf(t), nil
+}
+
+// ParseRecur takes daily, weekly, monthly, yearly, or a number followed by d, w, m or y, as in 2w for every other week.
+// It returns the number and the unit letter.
+func ParseRecur(s string) (int, byte, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "daily":
+		return 1, 'd', nil
+	case "weekly":
+		return 1, 'w', nil
+	case "monthly":
+		return 1, 'm', nil
+	case "yearly", "annually":
+		return 1, 'y', nil
+	}
+	if len(s) >= 2 {
+		unit := s[len(s)-1]
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n > 0 && strings.IndexByte("dwmy", unit) >= 0 {
+			return n, unit, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("recurrence %q is not daily, weekly, monthly, yearly, or a number and d, w, m or y", s)
+}
+
+// nextDue is from plus one recurrence.  Months are added by timlibg.AddMonths, so the 31st becomes the last day of a shorter month.
+func nextDue(from time.Time, recur string) (time.Time, error) {
+	n, unit, err := ParseRecur(recur)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch unit {
+	case 'd':
+		return from.AddDate(0, 0, n), nil
+	case 'w':
+		return from.AddDate(0, 0, 7*n), nil
+	case 'm':
+		return timlibg.AddMonths(from, n), nil
+	}
+	return timlibg.AddMonths(from, 12*n), nil
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func today() time.Time {
+	return dateOf(time.Now())
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
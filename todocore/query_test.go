@@ -0,0 +1,220 @@
+package todocore_test
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"slices"
+	"src/todocore"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func makeQueryList(t *testing.T) todocore.ListType {
+	l := todocore.ListType{}
+	today := dateOf(time.Now())
+	l.Add("pay the rent")    // 1, due yesterday, A, #bills
+	l.Add("call the doctor") // 2, due in a week, C
+	l.Add("read a book")     // 3, nothing
+	l.Add("file the taxes")  // 4, due tomorrow, A, #bills +taxes, done
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(l.SetDue(1, today.AddDate(0, 0, -1)))
+	must(l.SetPriority(1, 1))
+	must(l.AddTags(1, "#Bills", "bills"))
+	must(l.SetDue(2, today.AddDate(0, 0, 7)))
+	must(l.SetPriority(2, 3))
+	must(l.SetNotes(3, "the one about the Doctor"))
+	must(l.SetDue(4, today.AddDate(0, 0, 1)))
+	must(l.SetPriority(4, 1))
+	must(l.AddTags(4, "bills"))
+	must(l.SetProject(4, "+taxes"))
+	must(l.Complete(4))
+	return l
+}
+
+func TestFilter(t *testing.T) {
+	l := makeQueryList(t)
+	if len(l[0].Tags) != 1 || l[0].Tags[0] != "bills" {
+		t.Errorf(" Tags should be [bills], got %v instead.\n", l[0].Tags)
+	}
+
+	tests := []struct {
+		name string
+		f    todocore.FilterType
+		want []int
+	}{
+		{"not done", todocore.FilterType{}, []int{1, 2, 3}},
+		{"all", todocore.FilterType{All: true}, []int{1, 2, 3, 4}},
+		{"tag", todocore.FilterType{Tag: "#bills"}, []int{1}},
+		{"tag all", todocore.FilterType{Tag: "bills", All: true}, []int{1, 4}},
+		{"project", todocore.FilterType{Project: "Taxes", All: true}, []int{4}},
+		{"overdue", todocore.FilterType{Overdue: true, All: true}, []int{1}},
+		{"priority", todocore.FilterType{Priority: 2}, []int{1}},
+		{"search", todocore.FilterType{Search: "DOCTOR"}, []int{2, 3}},
+	}
+	for _, tc := range tests {
+		if got := l.Filter(tc.f); !slices.Equal(got, tc.want) {
+			t.Errorf(" Filter %s: expected %v, got %v instead.\n", tc.name, tc.want, got)
+		}
+	}
+	if got := l.Search("taxes"); !slices.Equal(got, []int{4}) {
+		t.Errorf(" Search should find the completed item 4, got %v instead.\n", got)
+	}
+
+	nums := l.Filter(todocore.FilterType{All: true})
+	l.SortByDue(nums)
+	if !slices.Equal(nums, []int{1, 4, 2, 3}) {
+		t.Errorf(" SortByDue: expected [1 4 2 3], got %v instead.\n", nums)
+	}
+	l.SortByPriority(nums)
+	if !slices.Equal(nums, []int{1, 4, 2, 3}) {
+		t.Errorf(" SortByPriority: expected [1 4 2 3], got %v instead.\n", nums)
+	}
+
+	if !strings.Contains(l.GetString(0), "(A) due ") || !strings.Contains(l.GetString(0), "OVERDUE") || !strings.Contains(l.GetString(0), "#bills") {
+		t.Errorf(" GetString(0) should show the priority, due date, overdue and tag, got %q instead.\n", l.GetString(0))
+	}
+	if err := l.SetPriority(9, 1); err == nil {
+		t.Errorf(" SetPriority of item 9 should be an error.\n")
+	}
+}
+
+func TestRecur(t *testing.T) {
+	l := todocore.ListType{}
+	l.Add("pay the mortgage")
+	due := time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local)
+	_ = l.SetDue(1, due)
+	if err := l.SetRecur(1, "fortnightly"); err == nil {
+		t.Errorf(" fortnightly should not be a recurrence.\n")
+	}
+	if err := l.SetRecur(1, "Monthly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Complete(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 2 || l[1].Done || l[1].Recur != "monthly" {
+		t.Fatalf(" Completing a monthly task should add the next one, got %v instead.\n", l)
+	}
+	if want := time.Date(2026, 2, 28, 0, 0, 0, 0, time.Local); !l[1].Due.Equal(want) {
+		t.Errorf(" The next one should be due %s, got %s instead.\n", want, l[1].Due)
+	}
+
+	l.Add("water the plants")
+	_ = l.SetRecur(3, "3d")
+	_ = l.Complete(3)
+	if want := dateOf(time.Now()).AddDate(0, 0, 3); len(l) != 4 || !l[3].Due.Equal(want) {
+		t.Errorf(" W/o a due date, the next one should be due 3 days from today, got %v instead.\n", l)
+	}
+}
+
+func TestParse(t *testing.T) {
+	priorities := map[string]int{"A": 1, "(b)": 2, "z": 26, "high": 1, "Low": 3, "26": 26, "": 0, "none": 0,
+		"H": 8, "h": 8, "M": 13, "m": 13, "L": 12, "(l)": 12, "hi": 1, "med": 2, "lo": 3} // a single letter is that letter, not high, medium or low.
+	for s, want := range priorities {
+		if got, err := todocore.ParsePriority(s); err != nil || got != want {
+			t.Errorf(" ParsePriority(%q): expected %d, got %d, %v instead.\n", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"27", "AA", "urgent"} {
+		if _, err := todocore.ParsePriority(s); err == nil {
+			t.Errorf(" ParsePriority(%q) should be an error.\n", s)
+		}
+	}
+	if todocore.PriorityString(3) != "C" || todocore.PriorityString(0) != "" {
+		t.Errorf(" PriorityString is wrong.\n")
+	}
+
+	today := dateOf(time.Now())
+	dues := map[string]time.Time{
+		"today":      today,
+		"tomorrow":   today.AddDate(0, 0, 1),
+		"+2w":        today.AddDate(0, 0, 14),
+		"3/15/2027":  time.Date(2027, 3, 15, 0, 0, 0, 0, time.Local),
+		"2027-03-15": time.Date(2027, 3, 15, 0, 0, 0, 0, time.Local),
+		"none":       {},
+	}
+	for s, want := range dues {
+		if got, err := todocore.ParseDue(s); err != nil || !got.Equal(want) {
+			t.Errorf(" ParseDue(%q): expected %s, got %s, %v instead.\n", s, want, got, err)
+		}
+	}
+	if _, err := todocore.ParseDue("someday"); err == nil {
+		t.Errorf(" ParseDue(someday) should be an error.\n")
+	}
+}
+
+// oldItem is the item before the priority, due date and the rest were added.
+type oldItem struct {
+	Task        string
+	Done        bool
+	CreatedAt   time.Time
+	CompletedAt time.Time
+}
+
+func TestOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	jsonName := filepath.Join(dir, "old.json")
+	oldJSON := `[{"Task":"old task","Done":true,"CreatedAt":"2022-01-10T10:00:00Z","CompletedAt":"2022-01-11T10:00:00Z"}]`
+	if err := os.WriteFile(jsonName, []byte(oldJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := todocore.ListType{}
+	if err := l.LoadJSON(jsonName); err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 1 || l[0].Task != "old task" || !l[0].Done || l[0].Priority != 0 || !l[0].Due.IsZero() {
+		t.Errorf(" The old json file did not load right, got %v instead.\n", l)
+	}
+	if err := l.SaveJSON(jsonName); err != nil {
+		t.Fatal(err)
+	}
+	js, _ := os.ReadFile(jsonName)
+	if strings.Contains(string(js), "Due") || strings.Contains(string(js), "Priority") {
+		t.Errorf(" The fields that aren't set should not be written, got %s instead.\n", js)
+	}
+
+	gobName := filepath.Join(dir, "old.gob")
+	f, err := os.Create(gobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = gob.NewEncoder(f).Encode([]oldItem{{Task: "old gob task"}}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	l = todocore.ListType{}
+	if err = l.LoadBinary(gobName); err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 1 || l[0].Task != "old gob task" {
+		t.Errorf(" The old gob file did not load right, got %v instead.\n", l)
+	}
+
+	// and the other way, an old program reading a new file.
+	l.Add("new task")
+	_ = l.SetPriority(2, 1)
+	_ = l.AddTags(2, "new")
+	if err = l.SaveBinary(gobName); err != nil {
+		t.Fatal(err)
+	}
+	f, err = os.Open(gobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var old []oldItem
+	if err = gob.NewDecoder(f).Decode(&old); err != nil || len(old) != 2 || old[1].Task != "new task" {
+		t.Errorf(" The old item type should read the new gob file, got %v, %v instead.\n", old, err)
+	}
+}
@@ -0,0 +1,220 @@
+package todocore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultListName is the list that an old todo.json or todo.gob, which has only one list, is loaded into.
+const DefaultListName = "todo"
+
+// StoreType is named lists, like work and home, that are saved in one file.  The json is an object of the list names to their lists,
+// except when the DefaultListName list is the only one.  Then it's saved as that list alone, so the file is the same as before there were stores.
+type StoreType struct {
+	Lists map[string]*ListType
+}
+
+func NewStore() *StoreType {
+	return &StoreType{Lists: make(map[string]*ListType)}
+}
+
+// Get returns the list of that name, which is created if it's not there.
+func (s *StoreType) Get(name string) *ListType {
+	if name == "" {
+		name = DefaultListName
+	}
+	if s.Lists == nil {
+		s.Lists = make(map[string]*ListType)
+	}
+	l, ok := s.Lists[name]
+	if !ok || l == nil {
+		l = &ListType{}
+		s.Lists[name] = l
+	}
+	return l
+}
+
+// fixNils makes the map and the lists that were null in the file, so a file of null, or of {"work": null}, is the same as an empty store or an empty list.
+func (s *StoreType) fixNils() {
+	if s.Lists == nil {
+		s.Lists = make(map[string]*ListType)
+	}
+	for name, l := range s.Lists {
+		if l == nil {
+			s.Lists[name] = &ListType{}
+		}
+	}
+}
+
+// Names returns the list names, sorted.
+func (s *StoreType) Names() []string {
+	names := make([]string, 0, len(s.Lists))
+	for name := range s.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Remove deletes a list and all its items.
+func (s *StoreType) Remove(name string) error {
+	if _, ok := s.Lists[name]; !ok {
+		return fmt.Errorf("list %q does not exist", name)
+	}
+	delete(s.Lists, name)
+	return nil
+}
+
+// Move takes item i, which is 1 origin, out of list from and adds it to the end of list to, which is created if it's not there.
+func (s *StoreType) Move(from string, i int, to string) error {
+	src, ok := s.Lists[from]
+	if !ok {
+		return fmt.Errorf("list %q does not exist", from)
+	}
+	if from == to {
+		return fmt.Errorf("item %d is already in list %q", i, to)
+	}
+	if err := src.checkItem(i); err != nil {
+		return err
+	}
+	it := (*src)[i-1]
+	if err := src.Delete(i); err != nil {
+		return err
+	}
+	dst := s.Get(to)
+	*dst = append(*dst, it)
+	return nil
+}
+
+// onlyDefault returns the DefaultListName list if it's the only list, or an empty list if there are none.
+func (s *StoreType) onlyDefault() (ListType, bool) {
+	if len(s.Lists) > 1 {
+		return nil, false
+	}
+	l, ok := s.Lists[DefaultListName]
+	if len(s.Lists) == 1 && !ok {
+		return nil, false
+	}
+	if l == nil || *l == nil {
+		return ListType{}, true // so the json is [], not null.
+	}
+	return *l, true
+}
+
+// SaveJSON writes the store atomically, so a reader never sees half a file.  A store of only the default list is written as a plain array, which older versions can read.
+func (s *StoreType) SaveJSON(filename string) error {
+	var js []byte
+	var err error
+	if l, ok := s.onlyDefault(); ok {
+		js, err = json.Marshal(l)
+	} else {
+		js, err = json.Marshal(s.Lists)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(js)
+		return err
+	})
+}
+
+// LoadJSON reads a store, or a file of a single list from before there were stores, which becomes the DefaultListName list.
+// Like ListType.LoadJSON, a file that's not there is not an error, and neither is null, which ListType.SaveJSON writes for a nil list.
+func (s *StoreType) LoadJSON(filename string) error {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	file = bytes.TrimSpace(file)
+	if len(file) == 0 {
+		return nil
+	}
+	if file[0] == '[' {
+		var l ListType
+		if err = json.Unmarshal(file, &l); err != nil {
+			return err
+		}
+		s.Lists = map[string]*ListType{DefaultListName: &l}
+		return nil
+	}
+	lists := make(map[string]*ListType)
+	if err = json.Unmarshal(file, &lists); err != nil { // null makes lists nil, which fixNils takes care of.
+		return err
+	}
+	s.Lists = lists
+	s.fixNils()
+	return nil
+}
+
+// SaveBinary writes the store as gob, atomically.  Like SaveJSON, a store of only the default list is written as that list.
+func (s *StoreType) SaveBinary(filename string) error {
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		if l, ok := s.onlyDefault(); ok {
+			return gob.NewEncoder(w).Encode(l)
+		}
+		return gob.NewEncoder(w).Encode(s.Lists)
+	})
+}
+
+// LoadBinary reads a store, or a gob file of a single list from before there were stores, which becomes the DefaultListName list.
+func (s *StoreType) LoadBinary(filename string) error {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	lists := make(map[string]*ListType)
+	if err = gob.NewDecoder(bytes.NewReader(file)).Decode(&lists); err == nil {
+		s.Lists = lists
+		s.fixNils()
+		return nil
+	}
+	var l ListType
+	if er := gob.NewDecoder(bytes.NewReader(file)).Decode(&l); er != nil {
+		return err // the error of it not being a store, as that's what it should be.
+	}
+	s.Lists = map[string]*ListType{DefaultListName: &l}
+	return nil
+}
+
+// writeFileAtomic writes to a temp file in the same directory, and then renames it over filename.  The rename is atomic, so another terminal that
+// reads or writes the file at the same time gets either the old file or the new one, never a mix.  The mode of the file it replaces is kept.
+func writeFileAtomic(filename string, write func(w io.Writer) error) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(filename); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // it's gone after the rename, so this only matters if something failed.
+	if err = write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,170 @@
+package todocore_test
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"src/todocore"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	s := todocore.NewStore()
+	work := s.Get("work")
+	work.Add("write the report")
+	work.Add("call home")
+	s.Get("").Add("default task")
+
+	if err := s.Move("work", 2, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*s.Get("work")) != 1 || (*s.Get("home"))[0].Task != "call home" {
+		t.Errorf(" Move should take call home from work to home, got work %v, home %v instead.\n", *s.Get("work"), *s.Get("home"))
+	}
+	if err := s.Move("work", 5, "home"); err == nil {
+		t.Errorf(" Moving item 5 should be an error.\n")
+	}
+	if err := s.Move("play", 1, "home"); err == nil {
+		t.Errorf(" Moving from a list that's not there should be an error.\n")
+	}
+	if names := strings.Join(s.Names(), " "); names != "home todo work" {
+		t.Errorf(" Names should be home todo work, got %s instead.\n", names)
+	}
+
+	dir := t.TempDir()
+	jsonName := filepath.Join(dir, "store.json")
+	gobName := filepath.Join(dir, "store.gob")
+	if err := s.SaveJSON(jsonName); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveBinary(gobName); err != nil {
+		t.Fatal(err)
+	}
+	for _, load := range []func(*todocore.StoreType) error{
+		func(s2 *todocore.StoreType) error { return s2.LoadJSON(jsonName) },
+		func(s2 *todocore.StoreType) error { return s2.LoadBinary(gobName) },
+	} {
+		s2 := todocore.NewStore()
+		if err := load(s2); err != nil {
+			t.Fatal(err)
+		}
+		if len(s2.Lists) != 3 || (*s2.Get("home"))[0].Task != "call home" {
+			t.Errorf(" The loaded store should match, got %v instead.\n", s2.Lists)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf(" The temp files of the atomic save should be gone, but the directory has %d files.\n", len(entries))
+	}
+
+	if err := s.Remove("home"); err != nil || len(s.Lists) != 2 {
+		t.Errorf(" Remove home should leave 2 lists, got %v, %v instead.\n", s.Lists, err)
+	}
+}
+
+func TestStoreOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	l := todocore.ListType{}
+	l.Add("old task")
+	jsonName := filepath.Join(dir, "todo.json")
+	if err := l.SaveJSON(jsonName); err != nil {
+		t.Fatal(err)
+	}
+	gobName := filepath.Join(dir, "todo.gob")
+	f, err := os.Create(gobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = gob.NewEncoder(f).Encode(l); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, load := range []func(*todocore.StoreType) error{
+		func(s *todocore.StoreType) error { return s.LoadJSON(jsonName) },
+		func(s *todocore.StoreType) error { return s.LoadBinary(gobName) },
+	} {
+		s := todocore.NewStore()
+		if err = load(s); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Lists) != 1 || len(*s.Get(todocore.DefaultListName)) != 1 || (*s.Get(todocore.DefaultListName))[0].Task != "old task" {
+			t.Errorf(" An old file should load as the %s list, got %v instead.\n", todocore.DefaultListName, s.Lists)
+		}
+	}
+
+	s := todocore.NewStore()
+	if err = s.LoadJSON(filepath.Join(dir, "not there.json")); err != nil || len(s.Lists) != 0 {
+		t.Errorf(" A file that's not there should be an empty store, got %v, %v instead.\n", s.Lists, err)
+	}
+}
+
+func TestStoreDefaultOnly(t *testing.T) {
+	dir := t.TempDir()
+	s := todocore.NewStore()
+	s.Get("").Add("only task")
+	jsonName := filepath.Join(dir, "todo.json")
+	gobName := filepath.Join(dir, "todo.gob")
+	if err := s.SaveJSON(jsonName); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveBinary(gobName); err != nil {
+		t.Fatal(err)
+	}
+
+	// a version from before there were stores reads the file as one list.
+	l := todocore.ListType{}
+	if err := l.LoadJSON(jsonName); err != nil || len(l) != 1 || l[0].Task != "only task" {
+		t.Errorf(" A store of only the default list should be a plain array, got %v, %v instead.\n", l, err)
+	}
+	f, err := os.Open(gobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var gl todocore.ListType
+	if err = gob.NewDecoder(f).Decode(&gl); err != nil || len(gl) != 1 || gl[0].Task != "only task" {
+		t.Errorf(" A gob store of only the default list should be a list, got %v, %v instead.\n", gl, err)
+	}
+
+	s.Get("work").Add("work task")
+	if err = s.SaveJSON(jsonName); err != nil {
+		t.Fatal(err)
+	}
+	s2 := todocore.NewStore()
+	if err = s2.LoadJSON(jsonName); err != nil || len(s2.Lists) != 2 {
+		t.Errorf(" A store of 2 lists should load as 2 lists, got %v, %v instead.\n", s2.Lists, err)
+	}
+}
+
+func TestStoreNull(t *testing.T) {
+	dir := t.TempDir()
+	var nilList todocore.ListType
+	nullName := filepath.Join(dir, "null.json")
+	if err := nilList.SaveJSON(nullName); err != nil {
+		t.Fatal(err)
+	}
+	workName := filepath.Join(dir, "work.json")
+	if err := os.WriteFile(workName, []byte(`{"work": null, "home": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{nullName, workName} {
+		s := todocore.NewStore()
+		if err := s.LoadJSON(name); err != nil {
+			t.Fatalf(" Loading %s: %v\n", filepath.Base(name), err)
+		}
+		s.Get("work").Add("after null") // this panic'd w/ a nil map, or a nil *ListType for work.
+		s.Get("").Add("default")
+		if len(*s.Get("work")) != 1 || len(*s.Get("")) != 1 {
+			t.Errorf(" After loading %s, work is %v and the default is %v, want 1 item each.\n", filepath.Base(name), *s.Get("work"), *s.Get(""))
+		}
+	}
+
+	var zero todocore.StoreType
+	zero.Get("work").Add("zero value store")
+	if len(zero.Names()) != 1 {
+		t.Errorf(" Get on the zero value store should make the list, got %v instead.\n", zero.Names())
+	}
+}
@@ -5,15 +5,42 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
+const lastModified = "Oct 18, 2026"
+
+/*
+REVISION HISTORY
+-------- -------
+18 Oct 26 -- Added priority, due date, tags, project, notes and recurrence to an item.  The new fields are omitted from the json when they're not set,
+               and gob skips fields it doesn't know, so the old todo.json and todo.gob files still load, and the old code can still read the new ones.
+               Completing a recurring task adds the next one, due one recurrence after the due date, or after today if it had none.
+               The query methods in query.go return 1 origin item numbers, so what they find can be completed, deleted or changed by number.
+               Added About, GetString and String from the todo package, so the todo cmd can use this package.
+18 Oct 26 -- The saves are now atomic, by writing a temp file and renaming it, so two terminals can't leave a file that's half of each.
+               Added StoreType in store.go, for named lists like work and home in one file, and moving an item between them.
+               Added the todo.txt format in todotxt.go and CSV in csv.go, for importing and exporting.
+18 Oct 26 -- ParsePriority took h, m and l as high, medium and low, so priority H was read back from a CSV or todo.txt file as A, and L as C.
+               Now a single letter is always that letter.
+18 Oct 26 -- A store file of null, or a list in it that's null, loads as empty, instead of leaving a nil map or list that Get panic'd on.
+*/
+
 type item struct {
 	Task        string
 	Done        bool
 	CreatedAt   time.Time
 	CompletedAt time.Time
+	Priority    int       `json:",omitempty"` // 1 is A, the highest, thru 26 for Z, like todo.txt.  0 is no priority.
+	Due         time.Time `json:",omitzero"`
+	Tags        []string  `json:",omitempty"`
+	Project     string    `json:",omitempty"`
+	Notes       string    `json:",omitempty"`
+	Recur       string    `json:",omitempty"` // daily, weekly, monthly, yearly, or a number w/ d, w, m or y, as in 2w.  See ParseRecur.
 }
 
 type ListType []item // the book calls this type List.  I think that's too vague so I changed it.
@@ -37,6 +64,115 @@ func (l *ListType) Complete(i int) error { // Will use 1 origin reference so i =
 	ls[i-1].Done = true // remember i is a 1 origin list that has to be converted to a zero origin slice index
 	ls[i-1].CompletedAt = time.Now()
 
+	if ls[i-1].Recur != "" { // the next one of a recurring task.
+		next := ls[i-1]
+		next.Done = false
+		next.CreatedAt = time.Now()
+		next.CompletedAt = time.Time{}
+		next.Tags = slices.Clone(next.Tags)
+		from := next.Due
+		if from.IsZero() {
+			from = today()
+		}
+		due, err := nextDue(from, next.Recur)
+		if err != nil {
+			return err
+		}
+		next.Due = due
+		*l = append(*l, next)
+	}
+	return nil
+}
+
+// checkItem returns the error for an item number that's not in the list, or nil.
+func (l *ListType) checkItem(i int) error {
+	if i <= 0 || i > len(*l) {
+		return fmt.Errorf("item %d does not exist", i)
+	}
+	return nil
+}
+
+// SetPriority -- p is 1 for A thru 26 for Z, and 0 removes the priority.  ParsePriority returns p from what's entered.
+func (l *ListType) SetPriority(i int, p int) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	if p < 0 || p > 26 {
+		return fmt.Errorf("priority %d is not 0 thru 26", p)
+	}
+	(*l)[i-1].Priority = p
+	return nil
+}
+
+// SetDue -- a zero due date removes it.  ParseDue returns the date from what's entered.
+func (l *ListType) SetDue(i int, due time.Time) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	(*l)[i-1].Due = due
+	return nil
+}
+
+// AddTags adds the tags that the item doesn't already have.  Tags are lower case, and a leading # is dropped.
+func (l *ListType) AddTags(i int, tags ...string) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	it := &(*l)[i-1]
+	for _, tag := range tags {
+		if tag = normalizeTag(tag); tag != "" && !slices.Contains(it.Tags, tag) {
+			it.Tags = append(it.Tags, tag)
+		}
+	}
+	return nil
+}
+
+// RemoveTags -- it's not an error if the item doesn't have one of them.
+func (l *ListType) RemoveTags(i int, tags ...string) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	it := &(*l)[i-1]
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		it.Tags = slices.DeleteFunc(it.Tags, func(t string) bool { return t == tag })
+	}
+	if len(it.Tags) == 0 {
+		it.Tags = nil
+	}
+	return nil
+}
+
+// SetProject -- a blank project removes it.  A leading + is dropped, as in todo.txt.
+func (l *ListType) SetProject(i int, project string) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	(*l)[i-1].Project = strings.TrimPrefix(strings.TrimSpace(project), "+")
+	return nil
+}
+
+// SetNotes replaces the notes.
+func (l *ListType) SetNotes(i int, notes string) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	(*l)[i-1].Notes = notes
+	return nil
+}
+
+// SetRecur -- a blank recurrence removes it.  Otherwise it's checked by ParseRecur.
+func (l *ListType) SetRecur(i int, recur string) error {
+	if err := l.checkItem(i); err != nil {
+		return err
+	}
+	recur = strings.ToLower(strings.TrimSpace(recur))
+	if recur != "" {
+		if _, _, err := ParseRecur(recur); err != nil {
+			return err
+		}
+	}
+	(*l)[i-1].Recur = recur
 	return nil
 }
 
@@ -57,20 +193,17 @@ func (l *ListType) SaveJSON(filename string) error {
 		return err
 	}
 
-	return os.WriteFile(filename, js, 0644)
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(js)
+		return err
+	})
 }
 
 func (l *ListType) SaveBinary(filename string) error {
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-
-	defer f.Close()
-
-	encoder := gob.NewEncoder(f)
-	err = encoder.Encode(*l)
-	return err // I want to make sure that the write operation occurs before the close operation.
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		encoder := gob.NewEncoder(w)
+		return encoder.Encode(*l)
+	})
 }
 
 func (l *ListType) LoadJSON(filename string) error {
@@ -111,3 +244,61 @@ func (l *ListType) List() []string {
 	}
 	return s
 }
+
+func (l *ListType) About() string {
+	return lastModified
+}
+
+// GetString -- i is the zero origin index, and the number shown is 1 origin.  The string includes the newline.
+func (l *ListType) GetString(i int) string {
+	if i < 0 || i >= len(*l) {
+		return ""
+	}
+
+	suffix := ".  "
+	prefix := "  "
+
+	t := (*l)[i]
+
+	var extra strings.Builder
+	if t.Priority > 0 {
+		fmt.Fprintf(&extra, " (%s)", PriorityString(t.Priority))
+	}
+	if !t.Due.IsZero() {
+		extra.WriteString(" due " + t.Due.Format("Jan-02-2006"))
+		if t.IsOverdue(time.Now()) {
+			extra.WriteString(" OVERDUE")
+		}
+	}
+	if t.Recur != "" {
+		extra.WriteString(" recur " + t.Recur)
+	}
+	if t.Project != "" {
+		extra.WriteString(" +" + t.Project)
+	}
+	for _, tag := range t.Tags {
+		extra.WriteString(" #" + tag)
+	}
+
+	createdAt := ", created " + t.CreatedAt.Format("Jan-02-2006 15:04") + ", "
+	if t.CreatedAt.IsZero() { // as from a todo.txt line w/o a creation date.
+		createdAt = ", "
+	}
+	if t.Done {
+		prefix = "X "
+		suffix = " completed at " + t.CompletedAt.Format("Jan-02-2006 15:04") + ".  "
+	}
+	formatted := fmt.Sprintf("%s%d: %s%s%s%s\n", prefix, i+1, t.Task, extra.String(), createdAt, suffix)
+	if t.Notes != "" {
+		formatted += "      " + strings.ReplaceAll(t.Notes, "\n", "\n      ") + "\n"
+	}
+	return formatted
+}
+
+func (l ListType) String() string { // implements the fmt.Stringer interface, which must be a value receiver.
+	var formatted string
+	for i := range l {
+		formatted += l.GetString(i)
+	}
+	return formatted
+}
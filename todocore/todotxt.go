@@ -0,0 +1,182 @@
+package todocore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+  The todo.txt format is one task per line, as in
+    (A) 2026-10-01 call the landlord +apartment @phone due:2026-10-20 rec:1m
+    x 2026-10-18 2026-10-01 pay the rent +apartment pri:A
+  A line that starts w/ x is done, and is followed by the completion date and then the creation date.  A line that's not done starts w/ the priority,
+  and then the creation date.  The dates are optional.  The first +project is the Project, and the others stay in the task.  Each @context is a tag.
+  The key:value words are due: for the due date, rec: for the recurrence, pri: for the priority of a done task, and note: for the notes, which are
+  url escaped as they can have spaces.  Other key:value words stay in the task.
+  The Project is written before the task, so a +word in the task stays in the task when it's read back.
+*/
+
+const todoTxtDate = "2006-01-02"
+
+var todoTxtPriorityRegex = regexp.MustCompile(`^\([A-Z]\)$`)
+
+// ReadTodoTxt reads the todo.txt format.  Blank lines are skipped.
+func ReadTodoTxt(r io.Reader) (ListType, error) {
+	var l ListType
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+		it, err := parseTodoTxtLine(words)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		l = append(l, it)
+	}
+	return l, scanner.Err()
+}
+
+func parseTodoTxtLine(words []string) (item, error) {
+	var it item
+	nextDate := func() (time.Time, bool) {
+		if len(words) > 0 {
+			if t, err := time.ParseInLocation(todoTxtDate, words[0], time.Local); err == nil {
+				words = words[1:]
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	if words[0] == "x" {
+		it.Done = true
+		words = words[1:]
+		if t, ok := nextDate(); ok {
+			it.CompletedAt = t
+		}
+	} else if todoTxtPriorityRegex.MatchString(words[0]) {
+		it.Priority = int(words[0][1]-'A') + 1
+		words = words[1:]
+	}
+	if t, ok := nextDate(); ok {
+		it.CreatedAt = t
+	}
+
+	task := make([]string, 0, len(words))
+	for _, word := range words {
+		key, value, isKeyValue := strings.Cut(word, ":")
+		switch {
+		case len(word) > 1 && word[0] == '+' && it.Project == "":
+			it.Project = word[1:]
+		case len(word) > 1 && word[0] == '@':
+			it.Tags = append(it.Tags, normalizeTag(word[1:]))
+		case isKeyValue && key == "due" && value != "":
+			due, err := ParseDue(value)
+			if err != nil {
+				return it, err
+			}
+			it.Due = due
+		case isKeyValue && key == "rec" && value != "":
+			recur := strings.TrimPrefix(value, "+")
+			if _, _, err := ParseRecur(recur); err != nil {
+				return it, err
+			}
+			it.Recur = strings.ToLower(recur)
+		case isKeyValue && key == "pri" && value != "":
+			p, err := ParsePriority(value)
+			if err != nil {
+				return it, err
+			}
+			it.Priority = p
+		case isKeyValue && key == "note":
+			notes, err := url.QueryUnescape(value)
+			if err != nil {
+				return it, fmt.Errorf("note: %w", err)
+			}
+			it.Notes = notes
+		default:
+			task = append(task, word)
+		}
+	}
+	it.Task = strings.Join(task, " ")
+	if it.Task == "" {
+		return it, fmt.Errorf("there's no task")
+	}
+	return it, nil
+}
+
+// WriteTodoTxt writes the list in the todo.txt format, one line per item.
+func (l ListType) WriteTodoTxt(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range l {
+		words := make([]string, 0, 8)
+		if t.Done {
+			words = append(words, "x")
+			if !t.CompletedAt.IsZero() {
+				words = append(words, t.CompletedAt.Format(todoTxtDate))
+			}
+		} else if t.Priority > 0 {
+			words = append(words, "("+PriorityString(t.Priority)+")")
+		}
+		if !t.CreatedAt.IsZero() {
+			words = append(words, t.CreatedAt.Format(todoTxtDate))
+		}
+		if t.Project != "" { // before the task, since the first +word is the Project.
+			words = append(words, "+"+strings.ReplaceAll(t.Project, " ", "_"))
+		}
+		words = append(words, strings.Fields(t.Task)...)
+		for _, tag := range t.Tags {
+			words = append(words, "@"+strings.ReplaceAll(tag, " ", "_"))
+		}
+		if !t.Due.IsZero() {
+			words = append(words, "due:"+t.Due.Format(todoTxtDate))
+		}
+		if t.Recur != "" {
+			words = append(words, "rec:"+t.Recur)
+		}
+		if t.Done && t.Priority > 0 {
+			words = append(words, "pri:"+PriorityString(t.Priority))
+		}
+		if t.Notes != "" {
+			words = append(words, "note:"+url.QueryEscape(t.Notes))
+		}
+		if _, err := fmt.Fprintln(bw, strings.Join(words, " ")); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadTodoTxt replaces the list w/ the items of a todo.txt file.  Like LoadJSON, a file that's not there is not an error.
+func (l *ListType) LoadTodoTxt(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	lst, err := ReadTodoTxt(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	*l = lst
+	return nil
+}
+
+// SaveTodoTxt writes the list to a todo.txt file atomically.
+func (l *ListType) SaveTodoTxt(filename string) error {
+	return writeFileAtomic(filename, l.WriteTodoTxt)
+}